@@ -1,14 +1,21 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	chess2 "github.com/jerhon/chess/pkg/chess"
+	"github.com/jerhon/chess/pkg/chess/eval"
 	"github.com/jerhon/chess/pkg/chess/game"
+	"github.com/jerhon/chess/pkg/chess/san"
+	"github.com/jerhon/chess/pkg/chess_uci"
 )
 
 // ── Styles ────────────────────────────────────────────────────────────────────
@@ -76,6 +83,13 @@ type model struct {
 	status      string
 	isError     bool
 	windowWidth int
+
+	// human is the side the user plays against opponent, NoColor when both sides are played at the keyboard
+	human    game.ColorType
+	opponent opponent
+	// ctx cancels the opponent's search when the program exits
+	ctx      context.Context
+	thinking bool
 }
 
 func initialModel() model {
@@ -85,9 +99,51 @@ func initialModel() model {
 	}
 }
 
+// opponentModel starts a game in which the user plays human and opponent plays the other side.
+func opponentModel(ctx context.Context, human game.ColorType, opponent opponent) model {
+	m := initialModel()
+	m.human, m.opponent, m.ctx = human, opponent, ctx
+	m.status = fmt.Sprintf("You play %s against %s. Enter a SAN move or type 'quit' to exit.",
+		colorName(human), opponent.name())
+	return m
+}
+
+// engineMoveMsg carries the move the opponent chose for the position after ply half moves.
+type engineMoveMsg struct {
+	ply  int
+	move string
+	err  error
+}
+
+// opponentToMove returns true when the opponent should play the next move.
+func (m model) opponentToMove() bool {
+	return m.opponent != nil && !m.chessGame.GetResult().IsDecided() &&
+		m.chessGame.GetPosition().PlayerToMove != m.human
+}
+
+// think starts the opponent's search of a copy of the game, reporting its move as an engineMoveMsg so the
+// interface keeps running while it thinks.
+func (m model) think() (model, tea.Cmd) {
+	copied, err := copyGame(m.chessGame)
+	if err != nil {
+		m.status, m.isError = err.Error(), true
+		return m, nil
+	}
+	m.thinking = true
+	opponent, ctx, ply := m.opponent, m.ctx, m.chessGame.Ply()
+	return m, func() tea.Msg {
+		move, err := opponent.bestMove(ctx, copied)
+		return engineMoveMsg{ply: ply, move: move, err: err}
+	}
+}
+
 // ── Init ──────────────────────────────────────────────────────────────────────
 
 func (m model) Init() tea.Cmd {
+	if m.opponentToMove() {
+		_, cmd := m.think()
+		return cmd
+	}
 	return nil
 }
 
@@ -99,6 +155,23 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.windowWidth = msg.Width
 		return m, nil
 
+	case engineMoveMsg:
+		m.thinking = false
+		if msg.ply != m.chessGame.Ply() {
+			return m, nil
+		}
+		if msg.err != nil {
+			m.status, m.isError = fmt.Sprintf("%s failed: %v", m.opponent.name(), msg.err), true
+			return m, nil
+		}
+		sanText, err := m.chessGame.TryUciMove(msg.move)
+		if err != nil {
+			m.status, m.isError = fmt.Sprintf("%s played %s: %v", m.opponent.name(), msg.move, err), true
+			return m, nil
+		}
+		m.status, m.isError = fmt.Sprintf("%s played: %s", m.opponent.name(), sanText), false
+		return m, nil
+
 	case tea.KeyMsg:
 		switch msg.Type {
 		case tea.KeyCtrlC, tea.KeyEsc:
@@ -113,6 +186,10 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if input == "" {
 				return m, nil
 			}
+			if m.thinking {
+				m.status, m.isError = fmt.Sprintf("%s is thinking, wait for its move.", m.opponent.name()), true
+				return m, nil
+			}
 			_, err := m.chessGame.TrySanMove(input)
 			if err != nil {
 				m.status = err.Error()
@@ -121,6 +198,9 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.status = fmt.Sprintf("Played: %s", input)
 				m.isError = false
 			}
+			if m.opponentToMove() {
+				return m.think()
+			}
 
 		case tea.KeyBackspace:
 			if len(m.input) > 0 {
@@ -139,17 +219,27 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 func (m model) View() string {
 	pos := m.chessGame.GetPosition()
 
+	// the board faces the user when playing the opponent, and the player to move otherwise
+	perspective := pos.PlayerToMove
+	if m.opponent != nil {
+		perspective = m.human
+	}
+	gameStatus := renderGameStatus(m.chessGame)
+	if m.thinking {
+		gameStatus += "\n" + labelStyle.Render(m.opponent.name()+" is thinking…")
+	}
+
 	// ── Left panel: board ──────────────────────────────────────────────────
 	boardContent := titleStyle.Render("Chess") + "\n\n" +
-		renderBoard(pos, pos.PlayerToMove) + "\n\n" +
-		renderGameStatus(m.chessGame)
+		renderBoard(pos, perspective) + "\n\n" +
+		gameStatus
 	boardPanel := boardStyle.Render(boardContent)
 
 	// ── Right panel: moves + evaluation ───────────────────────────────────
 	sideContent := titleStyle.Render("Valid Moves") + "\n" +
 		renderMoves(m.chessGame) + "\n\n" +
 		titleStyle.Render("Evaluation") + "\n" +
-		labelStyle.Render("(not yet implemented)")
+		renderEvaluation(pos)
 	sidePanel := sidebarStyle.Render(sideContent)
 
 	// ── Top row: board + sidebar ──────────────────────────────────────────
@@ -184,8 +274,8 @@ func (m model) View() string {
 // renderBoard produces a board where each square is 3 columns wide × 3 rows
 // tall with rank/file labels and alternating light/dark square background
 // colors. The piece letter is centered in the middle row and column of each
-// square. The board is oriented so that the player whose turn it is appears
-// at the bottom.
+// square. The board is oriented so that the perspective player appears at the
+// bottom.
 func renderBoard(pos *game.ChessPosition, perspective game.ColorType) string {
 	// Determine iteration order based on perspective.
 	// White at bottom: ranks 8→1, files a→h.
@@ -295,6 +385,14 @@ func renderGameStatus(g *chess2.ChessGame) string {
 	}
 }
 
+// colorName returns the name of a color.
+func colorName(color game.ColorType) string {
+	if color == game.BlackPiece {
+		return "Black"
+	}
+	return "White"
+}
+
 // oppositePlayer returns the name of the opposite color.
 func oppositePlayer(color game.ColorType) string {
 	if color == game.WhitePiece {
@@ -303,15 +401,16 @@ func oppositePlayer(color game.ColorType) string {
 	return "White"
 }
 
-// renderMoves lists all valid moves for the current player in columns.
+// renderMoves lists all valid moves for the current player in columns, in standard algebraic notation.
 func renderMoves(g *chess2.ChessGame) string {
-	moves := g.GetMoves()
-
-	// Only show moves that can actually be played.
-	var valid []game.ChessMove
-	for _, mv := range moves {
-		if mv.CanMove {
-			valid = append(valid, mv)
+	var valid []string
+	for _, mv := range g.GetLegalMoves() {
+		promotions := []game.PieceType{game.NoPiece}
+		if mv.IsPromotion {
+			promotions = game.PromotionPieces
+		}
+		for _, promotion := range promotions {
+			valid = append(valid, san.FromMove(g.GetPosition(), mv, promotion))
 		}
 	}
 
@@ -320,24 +419,8 @@ func renderMoves(g *chess2.ChessGame) string {
 	}
 
 	var sb strings.Builder
-	for i, mv := range valid {
-
-		hasSameTo := false
-		for j, mv2 := range valid {
-			if i != j && mv.To == mv2.To {
-				hasSameTo = true
-				break
-			}
-		}
-
-		var entry string
-		if hasSameTo {
-			entry = mv.From.Location.File.String() + mv.From.Location.Rank.String() + mv.To.File.String() + mv.To.Rank.String()
-		} else {
-			entry = mv.To.File.String() + mv.To.Rank.String()
-		}
-
-		sb.WriteString(moveStyle.Render(entry + " "))
+	for i, entry := range valid {
+		sb.WriteString(moveStyle.Render(fmt.Sprintf("%-8s", entry)))
 		if (i+1)%3 == 0 {
 			sb.WriteRune('\n')
 		}
@@ -348,9 +431,116 @@ func renderMoves(g *chess2.ChessGame) string {
 // ── Main ──────────────────────────────────────────────────────────────────────
 
 func main() {
-	p := tea.NewProgram(initialModel(), tea.WithAltScreen())
-	if _, err := p.Run(); err != nil {
+	if err := run(os.Args[1:]); err != nil {
 		fmt.Fprintf(os.Stderr, "error running program: %v\n", err)
 		os.Exit(1)
 	}
 }
+
+// optionsFlag collects the engine options given as name=value.
+type optionsFlag []string
+
+func (o *optionsFlag) String() string { return strings.Join(*o, ",") }
+
+func (o *optionsFlag) Set(value string) error {
+	*o = append(*o, value)
+	return nil
+}
+
+func run(args []string) error {
+	fs := flag.NewFlagSet("chess-cli", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+
+	var (
+		play       = fs.String("play", "", "Color to play against the engine, white or black")
+		enginePath = fs.String("engine", "", "UCI engine executable, the built in engine when empty")
+		moveTime   = fs.Duration("movetime", time.Second, "Time the engine thinks about each move")
+		depth      = fs.Int("depth", 0, "Depth the engine searches to, unlimited when 0")
+		options    optionsFlag
+	)
+	fs.Var(&options, "option", "UCI engine option as name=value, may be repeated")
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage: chess-cli [options]
+
+Plays chess in the terminal, both sides at the keyboard or one side against an engine.
+
+Options:
+  -play <color>       Play white or black against the engine (default: both sides at the keyboard)
+  -engine <path>      UCI engine executable (default: the built in engine)
+  -movetime <time>    Time the engine thinks about each move (default 1s)
+  -depth <n>          Depth the engine searches to, lower is weaker (default: unlimited)
+  -option <name=val>  UCI engine option, e.g. "Skill Level=5", may be repeated
+
+Examples:
+  chess-cli
+  chess-cli -play white -movetime 500ms -depth 3
+  chess-cli -play black -engine /usr/bin/stockfish -option "UCI_LimitStrength=true" -option "UCI_Elo=1500"
+`)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	m := initialModel()
+	if *play != "" {
+		human := game.WhitePiece
+		switch strings.ToLower(*play) {
+		case "white", "w":
+		case "black", "b":
+			human = game.BlackPiece
+		default:
+			return fmt.Errorf("invalid color %q, expected white or black", *play)
+		}
+		if *moveTime <= 0 || *depth < 0 {
+			return fmt.Errorf("invalid engine limits: movetime %s, depth %d", *moveTime, *depth)
+		}
+
+		milliseconds := int(moveTime.Milliseconds())
+		limits := chess_uci.CmdGo{MoveTime: &milliseconds}
+		if *depth > 0 {
+			limits.Depth = depth
+		}
+
+		var opponent opponent = newBuiltinOpponent(limits)
+		if *enginePath != "" {
+			uci, err := newUciOpponent(*enginePath, options, limits)
+			if err != nil {
+				return err
+			}
+			opponent = uci
+		} else if len(options) > 0 {
+			return fmt.Errorf("-option needs -engine")
+		}
+		defer opponent.close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		// the search running when the user quits is stopped before the engine is closed
+		defer cancel()
+		m = opponentModel(ctx, human, opponent)
+	} else if *enginePath != "" || len(options) > 0 {
+		return fmt.Errorf("-engine and -option need -play")
+	}
+
+	p := tea.NewProgram(m, tea.WithAltScreen())
+	_, err := p.Run()
+	return err
+}
+
+// renderEvaluation lists each evaluation term as white's score less black's, so positive numbers favour
+// white, followed by the total in pawns.
+func renderEvaluation(pos *game.ChessPosition) string {
+	evaluation := eval.Evaluate(pos)
+
+	var sb strings.Builder
+	for _, term := range eval.Terms {
+		sb.WriteString(labelStyle.Render(fmt.Sprintf("%-15s", term)))
+		sb.WriteString(fmt.Sprintf(" %+5d\n", evaluation.White[term]-evaluation.Black[term]))
+	}
+	sb.WriteString(fmt.Sprintf("%-15s %+5.2f", "Total (pawns)", float64(evaluation.Total)/100))
+	return sb.String()
+}
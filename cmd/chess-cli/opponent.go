@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	chess2 "github.com/jerhon/chess/pkg/chess"
+	"github.com/jerhon/chess/pkg/chess/engine"
+	"github.com/jerhon/chess/pkg/chess/fen"
+	"github.com/jerhon/chess/pkg/chess/game"
+	"github.com/jerhon/chess/pkg/chess_uci"
+)
+
+// opponent plays the side the user does not.
+type opponent interface {
+	// name is shown in the status line, e.g. "Stockfish 16"
+	name() string
+	// bestMove returns the move to play in the current position of the game in UCI notation. The game is a copy
+	// owned by the caller.
+	bestMove(ctx context.Context, chessGame *chess2.ChessGame) (string, error)
+	close() error
+}
+
+// builtinOpponent plays with the engine package in this process.
+type builtinOpponent struct {
+	engine *engine.Engine
+	limits chess_uci.CmdGo
+}
+
+func newBuiltinOpponent(limits chess_uci.CmdGo) *builtinOpponent {
+	return &builtinOpponent{engine: engine.NewEngine(), limits: limits}
+}
+
+func (o *builtinOpponent) name() string { return o.engine.Name() }
+
+func (o *builtinOpponent) bestMove(ctx context.Context, chessGame *chess2.ChessGame) (string, error) {
+	best := o.engine.Search(ctx, chessGame, o.limits, nil)
+	if best.Move == "" {
+		return "", fmt.Errorf("%s found no move", o.name())
+	}
+	return best.Move, nil
+}
+
+func (o *builtinOpponent) close() error { return nil }
+
+// uciOpponent plays with an external engine driven over UCI.
+type uciOpponent struct {
+	client *chess_uci.Client
+	limits chess_uci.CmdGo
+}
+
+// newUciOpponent starts an engine executable and sets its options, given as name=value.
+func newUciOpponent(path string, options []string, limits chess_uci.CmdGo) (*uciOpponent, error) {
+	client, err := chess_uci.StartClient(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, option := range options {
+		name, value, found := strings.Cut(option, "=")
+		var valuePointer *string
+		if found {
+			valuePointer = &value
+		}
+		if err := client.SetOption(strings.TrimSpace(name), valuePointer); err != nil {
+			client.Close()
+			return nil, err
+		}
+	}
+	if err := client.NewGame(); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return &uciOpponent{client: client, limits: limits}, nil
+}
+
+func (o *uciOpponent) name() string {
+	if o.client.EngineName == "" {
+		return "engine"
+	}
+	return o.client.EngineName
+}
+
+// bestMove sends the game as its starting position and moves, so the engine sees repetitions.
+func (o *uciOpponent) bestMove(ctx context.Context, chessGame *chess2.ChessGame) (string, error) {
+	moves := []string{}
+	for _, record := range chessGame.History() {
+		moves = append(moves, uciMove(record))
+	}
+	position := chess_uci.CmdPosition{FEN: fen.ToFenString(chessGame.StartingPosition()), Moves: moves}
+	if err := o.client.SetPosition(position); err != nil {
+		return "", err
+	}
+
+	search, err := o.client.Go(o.limits)
+	if err != nil {
+		return "", err
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			o.client.Stop()
+		case <-done:
+		}
+	}()
+
+	best, err := search.Wait()
+	if err != nil {
+		return "", err
+	}
+	if best.Move == "" || best.Move == "(none)" || best.Move == "0000" {
+		return "", fmt.Errorf("%s found no move", o.name())
+	}
+	return best.Move, nil
+}
+
+func (o *uciOpponent) close() error { return o.client.Close() }
+
+// uciMove writes a played move in UCI notation, castling as the king's move.
+func uciMove(record chess2.MoveRecord) string {
+	text := record.From.String() + record.To.String()
+	if record.Promotion != game.NoPiece {
+		text += strings.ToLower(string(rune(record.Promotion)))
+	}
+	return text
+}
+
+// copyGame replays a game into a new one, so an opponent can search it while the user interface reads the
+// original.
+func copyGame(chessGame *chess2.ChessGame) (*chess2.ChessGame, error) {
+	copied := chess2.NewGameFromPosition(chessGame.StartingPosition())
+	for _, record := range chessGame.History() {
+		if _, err := copied.TryUciMove(uciMove(record)); err != nil {
+			return nil, err
+		}
+	}
+	return copied, nil
+}
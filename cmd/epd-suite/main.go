@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/jerhon/chess/pkg/chess/engine"
+	"github.com/jerhon/chess/pkg/chess/fen"
+	"github.com/jerhon/chess/pkg/chess/suite"
+	"github.com/jerhon/chess/pkg/chess_uci"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	fs := flag.NewFlagSet("epd-suite", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+
+	var (
+		moveTime   = fs.Duration("movetime", suite.DefaultMoveTime, "Time to search each position")
+		enginePath = fs.String("engine", "", "UCI engine executable, the built in engine when empty")
+		quiet      = fs.Bool("quiet", false, "Only print the summary")
+	)
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage: epd-suite [options] <suite.epd>
+
+Searches each position of an EPD test suite such as WAC or STS and reports how many
+positions the engine solved and its score.
+
+Options:
+  -movetime <duration>  Time to search each position (default 1s)
+  -engine <path>        UCI engine executable (default: the built in engine)
+  -quiet                Only print the summary
+
+Examples:
+  epd-suite -movetime 500ms wac.epd
+  epd-suite -engine /usr/bin/stockfish -movetime 100ms sts1.epd
+`)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("expected one EPD file")
+	}
+	if *moveTime <= 0 {
+		return fmt.Errorf("invalid movetime: %s", *moveTime)
+	}
+
+	file, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	records, err := fen.ReadEpd(file)
+	file.Close()
+	if err != nil {
+		return fmt.Errorf("invalid EPD: %w", err)
+	}
+
+	searcher := suite.NewEngineSearcher(engine.NewEngine())
+	if *enginePath != "" {
+		client, err := chess_uci.StartClient(*enginePath)
+		if err != nil {
+			return err
+		}
+		defer client.Close()
+		searcher = suite.NewClientSearcher(client)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	start := time.Now()
+	report, err := suite.Run(ctx, searcher, records, suite.Options{
+		MoveTime: *moveTime,
+		Progress: func(result suite.Result) {
+			if !*quiet {
+				printResult(result)
+			}
+		},
+	})
+	if err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+
+	fmt.Println()
+	fmt.Printf("Positions: %d of %d\n", len(report.Results), len(records))
+	fmt.Printf("Solved: %d\n", report.Solved)
+	fmt.Printf("Failed: %d\n", report.Failed)
+	fmt.Printf("Score: %d/%d\n", report.Score, report.MaxScore)
+	fmt.Printf("Time: %s\n", time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+func printResult(result suite.Result) {
+	status := "fail"
+	if result.Solved {
+		status = "ok"
+	}
+	expected := strings.Join(result.Record.BestMoves, " ")
+	if len(result.Record.AvoidMoves) > 0 {
+		expected = strings.TrimSpace(expected + " am " + strings.Join(result.Record.AvoidMoves, " "))
+	}
+
+	move := result.San
+	if result.Err != nil {
+		move = "error: " + result.Err.Error()
+	}
+	fmt.Printf("%-4s %-30s %-8s expected %s (%d/%d)\n", status, result.Record.ID, move, expected, result.Points, result.MaxPoints)
+}
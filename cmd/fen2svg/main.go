@@ -0,0 +1,198 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jerhon/chess/pkg/chess/fen"
+	"github.com/jerhon/chess/pkg/chess/game"
+	"github.com/jerhon/chess/pkg/chess/pgn"
+	"github.com/jerhon/chess/pkg/chess/render"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+// listFlag collects a flag given more than once.
+type listFlag []string
+
+func (l *listFlag) String() string { return strings.Join(*l, ",") }
+
+func (l *listFlag) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+func run(args []string) error {
+	fs := flag.NewFlagSet("fen2svg", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+
+	var (
+		fenString   = fs.String("fen", "", "FEN of the position to draw")
+		pgnPath     = fs.String("pgn", "", "PGN file holding the game to draw")
+		gameNumber  = fs.Int("game", 1, "Number of the game in the PGN file, from 1")
+		ply         = fs.Int("ply", -1, "Number of plies played in the PGN game, the last position when negative")
+		output      = fs.String("o", "", "File to write, standard output when empty")
+		size        = fs.Int("size", render.DefaultSize, "Width and height in pixels")
+		flip        = fs.Bool("flip", false, "Draw the board from black's side")
+		coordinates = fs.Bool("coords", true, "Label the files and ranks")
+		light       = fs.String("light", render.DefaultLightSquare, "Color of the light squares")
+		dark        = fs.String("dark", render.DefaultDarkSquare, "Color of the dark squares")
+		arrows      listFlag
+		highlights  listFlag
+	)
+	fs.Var(&arrows, "arrow", "Arrow between two squares, e.g. e2e4, may be repeated")
+	fs.Var(&highlights, "highlight", "Square to highlight, e.g. e4, may be repeated")
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage: fen2svg [options]
+
+Draws a chess position as an SVG diagram, from a FEN or from a game in a PGN file.
+
+Options:
+  -fen <fen>          FEN of the position to draw
+  -pgn <file>         PGN file holding the game to draw
+  -game <n>           Number of the game in the PGN file (default 1)
+  -ply <n>            Plies played in the game before the diagram (default: all)
+  -o <file>           File to write (default: standard output)
+  -size <pixels>      Width and height of the diagram (default 400)
+  -flip               Draw the board from black's side
+  -coords             Label the files and ranks (default true)
+  -light <color>      Color of the light squares
+  -dark <color>       Color of the dark squares
+  -arrow <squares>    Arrow between two squares, e.g. e2e4, may be repeated
+  -highlight <square> Square to highlight, e.g. e4, may be repeated
+
+Examples:
+  fen2svg -fen "r1bqkb1r/pppp1ppp/2n2n2/4p2Q/2B1P3/8/PPPP1PPP/RNB1K1NR w KQkq - 4 4" -arrow h5f7 -o puzzle.svg
+  fen2svg -pgn games.pgn -game 3 -ply 20 -flip -o diagram.svg
+`)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	if (*fenString == "") == (*pgnPath == "") {
+		fs.Usage()
+		return fmt.Errorf("expected one of -fen or -pgn")
+	}
+
+	options := render.DefaultOptions()
+	options.Size = *size
+	options.Coordinates = *coordinates
+	options.LightSquare, options.DarkSquare = *light, *dark
+	if *flip {
+		options.Orientation = game.BlackPiece
+	}
+	for _, arrow := range arrows {
+		from, to, err := parseSquares(arrow)
+		if err != nil {
+			return err
+		}
+		options.Arrows = append(options.Arrows, render.Arrow{From: from, To: to})
+	}
+	for _, highlight := range highlights {
+		square, err := parseSquare(highlight)
+		if err != nil {
+			return err
+		}
+		options.Highlights = append(options.Highlights, render.Highlight{Square: square})
+	}
+
+	var position *game.ChessPosition
+	if *fenString != "" {
+		parsed, err := fen.ParseFen(*fenString)
+		if err != nil {
+			return fmt.Errorf("invalid FEN: %w", err)
+		}
+		position = &parsed
+	} else {
+		var lastMove []game.ChessLocation
+		var err error
+		position, lastMove, err = pgnPosition(*pgnPath, *gameNumber, *ply)
+		if err != nil {
+			return err
+		}
+		options.LastMove = lastMove
+	}
+
+	svg := render.SVG(position, options)
+	if *output == "" {
+		_, err := fmt.Print(svg)
+		return err
+	}
+	return os.WriteFile(*output, []byte(svg), 0o644)
+}
+
+// pgnPosition replays a game of a PGN file and returns its position after ply moves with the squares of the
+// move that led to it.
+func pgnPosition(path string, gameNumber int, ply int) (*game.ChessPosition, []game.ChessLocation, error) {
+	if gameNumber < 1 {
+		return nil, nil, fmt.Errorf("invalid game number: %d", gameNumber)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	number := 0
+	for pgnGame, err := range pgn.ReadGames(file) {
+		if err != nil {
+			return nil, nil, err
+		}
+		if number++; number < gameNumber {
+			continue
+		}
+
+		replayed, err := pgn.Replay(pgnGame)
+		if err != nil {
+			return nil, nil, err
+		}
+		played := len(replayed.Positions) - 1
+		if ply < 0 {
+			ply = played
+		}
+		if ply > played {
+			return nil, nil, fmt.Errorf("game %d has %d plies, can't draw ply %d", gameNumber, played, ply)
+		}
+
+		var lastMove []game.ChessLocation
+		if ply > 0 {
+			record := replayed.Game.History()[ply-1]
+			lastMove = []game.ChessLocation{record.From, record.To}
+		}
+		return replayed.Positions[ply], lastMove, nil
+	}
+	return nil, nil, fmt.Errorf("the PGN file has %d games, can't draw game %d", number, gameNumber)
+}
+
+func parseSquares(text string) (game.ChessLocation, game.ChessLocation, error) {
+	if len(text) != 4 {
+		return game.ChessLocation{}, game.ChessLocation{}, fmt.Errorf("invalid arrow %q, expected two squares such as e2e4", text)
+	}
+	from, err := parseSquare(text[:2])
+	if err != nil {
+		return game.ChessLocation{}, game.ChessLocation{}, err
+	}
+	to, err := parseSquare(text[2:])
+	return from, to, err
+}
+
+func parseSquare(text string) (game.ChessLocation, error) {
+	if len(text) != 2 || text[0] < 'a' || text[0] > 'h' || text[1] < '1' || text[1] > '8' {
+		return game.ChessLocation{}, fmt.Errorf("invalid square %q, expected a square such as e4", text)
+	}
+	return game.ChessLocation{File: game.FileType(text[0]), Rank: game.RankType(text[1])}, nil
+}
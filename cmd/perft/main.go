@@ -0,0 +1,84 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jerhon/chess/pkg/chess/fen"
+	"github.com/jerhon/chess/pkg/chess/perft"
+)
+
+const startPositionFen = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	fs := flag.NewFlagSet("perft", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+
+	var (
+		fenString = fs.String("fen", startPositionFen, "FEN of the position to count from")
+		depth     = fs.Int("depth", 1, "Number of plies to search")
+		divide    = fs.Bool("divide", false, "Print the node count below each root move")
+	)
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage: perft [options]
+
+Counts the leaf nodes of the legal move tree to verify the move generator.
+
+Options:
+  -fen <fen>       FEN of the position (default: standard start position)
+  -depth <n>       Number of plies to search (default 1)
+  -divide          Print the node count below each root move
+
+Examples:
+  perft -depth 5
+  perft -fen "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1" -depth 3 -divide
+`)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	if *depth < 1 {
+		return fmt.Errorf("invalid depth: %d", *depth)
+	}
+
+	position, err := fen.ParseFen(*fenString)
+	if err != nil {
+		return fmt.Errorf("invalid FEN: %w", err)
+	}
+
+	start := time.Now()
+	var nodes uint64
+	if *divide {
+		for _, entry := range perft.Divide(&position, *depth) {
+			fmt.Printf("%s: %d\n", entry.Move, entry.Nodes)
+			nodes += entry.Nodes
+		}
+		fmt.Println()
+	} else {
+		nodes = perft.Perft(&position, *depth)
+	}
+	elapsed := time.Since(start)
+
+	fmt.Printf("Nodes searched: %d\n", nodes)
+	fmt.Printf("Time: %s\n", elapsed.Round(time.Millisecond))
+	if elapsed > 0 {
+		fmt.Printf("Nodes/second: %.0f\n", float64(nodes)/elapsed.Seconds())
+	}
+	return nil
+}
@@ -1,32 +1,36 @@
 package chess
 
 import (
+	"errors"
 	"fmt"
-	"strings"
 
-	"github.com/jerhon/chess/pkg/chess/fen"
+	"github.com/jerhon/chess/pkg/chess/clock"
 	game "github.com/jerhon/chess/pkg/chess/game"
 	"github.com/jerhon/chess/pkg/chess/san"
 )
 
 type ChessGame struct {
-	position        *game.ChessPosition
-	moves           *game.ChessMovement
-	positionHistory map[string]int
-}
-
-// positionKey returns a string key for the given position that captures all factors
-// relevant to threefold repetition: board state, player to move, castling rights,
-// and en passant square. It uses the first four fields of the FEN string and omits
-// the halfmove clock and fullmove number, which do not affect position identity
-// for repetition purposes under standard chess rules.
-func positionKey(position *game.ChessPosition) string {
-	fenStr := fen.ToFenString(position)
-	parts := strings.Fields(fenStr)
-	if len(parts) >= 4 {
-		return strings.Join(parts[:4], " ")
-	}
-	return fenStr
+	position *game.ChessPosition
+	moves    *game.ChessMovement
+	// positionHistory counts how often each position has occurred by its Zobrist hash
+	positionHistory map[uint64]int
+
+	// positions holds the position before every recorded move followed by the latest position,
+	// so positions[ply] is always the current position.
+	positions []*game.ChessPosition
+	// records holds every recorded move, including moves that were undone and can be redone.
+	records []MoveRecord
+	ply     int
+
+	// ending is the result of a game ended by resignation, agreement or a draw claim, nil while the position
+	// decides the result. It is cleared when a move is taken back.
+	ending *gameEnding
+	// drawOffer is the color whose draw offer is open, NoColor when there is none
+	drawOffer game.ColorType
+	// drawRules decide when repetition and the fifty-move rule draw the game
+	drawRules DrawRules
+	// clock times the game, nil when it isn't timed
+	clock *clock.Clock
 }
 
 func NewGame() *ChessGame {
@@ -39,7 +43,9 @@ func NewGame() *ChessGame {
 	return &ChessGame{
 		position:        position,
 		moves:           moves,
-		positionHistory: map[string]int{positionKey(position): 1},
+		positionHistory: map[uint64]int{position.Hash(): 1},
+		positions:       []*game.ChessPosition{position},
+		drawRules:       OnlineRules,
 	}
 }
 
@@ -51,7 +57,9 @@ func NewGameFromPosition(position *game.ChessPosition) *ChessGame {
 	return &ChessGame{
 		position:        position,
 		moves:           moves,
-		positionHistory: map[string]int{positionKey(position): 1},
+		positionHistory: map[uint64]int{position.Hash(): 1},
+		positions:       []*game.ChessPosition{position},
+		drawRules:       OnlineRules,
 	}
 }
 
@@ -62,59 +70,89 @@ func (g *ChessGame) calculate() {
 	}
 }
 
-// recordCurrentPosition increments the visit count for the current position and
-// declares DrawRepetition when the same position has occurred three or more times.
+// recordCurrentPosition increments the visit count for the current position, which decides draws by
+// repetition.
 func (g *ChessGame) recordCurrentPosition() {
-	key := positionKey(g.position)
-	g.positionHistory[key]++
-	if g.moves.Result == game.InProgress && g.positionHistory[key] >= 3 {
-		g.moves.Result = game.DrawRepetition
-	}
+	g.positionHistory[g.position.Hash()]++
 }
 
-func (g *ChessGame) castleKingSide() {
-	g.position = g.position.CastleKingside()
+// playMove applies a legal move from the current position, records it in the history and discards
+// any moves that were undone and not redone.
+func (g *ChessGame) playMove(move game.ChessMove, promotionPiece game.PieceType, sanText string) {
+	record := newMoveRecord(g.position, move, promotionPiece, sanText)
+
+	// a draw offer lapses once the opponent of the player who made it moves
+	if g.drawOffer != g.position.PlayerToMove {
+		g.drawOffer = game.NoColor
+	}
+
+	g.records = append(g.records[:g.ply], record)
+	g.positions = append(g.positions[:g.ply+1], g.position.ApplyMove(move, promotionPiece))
+	g.ply++
+
+	g.position = g.positions[g.ply]
 	g.calculate()
+	g.recordCurrentPosition()
+	g.pressClock()
 }
 
-func (g *ChessGame) castleQueenSide() {
-	g.position = g.position.CastleQueenside()
-	g.calculate()
+// findCastleMove returns the legal castling move toward the given side, if there is one.
+func (g *ChessGame) findCastleMove(kingSide bool) (game.ChessMove, bool) {
+	for _, move := range g.moves.Moves {
+		if move.IsCastle && move.CastlesKingSide() == kingSide {
+			return move, true
+		}
+	}
+	return game.ChessMove{}, false
 }
 
+// TrySanMove plays a move given in SAN. It returns an *ErrGameOver once the game has ended, an
+// *ErrMalformedSAN when the text isn't SAN, an *ErrIllegalCastle, *ErrNoMatchingMove or *ErrAmbiguousMove
+// when the move can't be played.
 func (g *ChessGame) TrySanMove(sanText string) (bool, error) {
 
-	if g.moves.Result != game.InProgress {
-		return false, fmt.Errorf("game is over: %s", g.moves.Result)
+	if err := g.checkInProgress(); err != nil {
+		return false, err
 	}
 
 	sanMove, sanCastle, err := san.ParseSan(sanText)
 	if err != nil {
-		return false, fmt.Errorf("invalid SAN %s: %v", sanText, err)
+		malformed := &ErrMalformedSAN{San: sanText, Err: err}
+		var sanError *san.SanError
+		if errors.As(err, &sanError) {
+			malformed.Position = sanError.Position
+		}
+		return false, malformed
 	}
 
 	if sanCastle != nil {
-		if sanCastle.CastleKingSide && !g.moves.CanCastle.KingSide {
-			return false, fmt.Errorf("cannot castle king side")
+		if failure := g.moves.CheckCastle(sanCastle.CastleKingSide); failure != game.CastleAllowed {
+			return false, &ErrIllegalCastle{KingSide: sanCastle.CastleKingSide, Reason: failure}
 		}
 
-		if sanCastle.CastleQueenSide && !g.moves.CanCastle.QueenSide {
-			return false, fmt.Errorf("cannot castle queen side")
+		move, found := g.findCastleMove(sanCastle.CastleKingSide)
+		if !found {
+			return false, &ErrIllegalCastle{KingSide: sanCastle.CastleKingSide, Reason: game.CastleNoRights}
 		}
+		g.playMove(move, game.NoPiece, san.FromMove(g.position, move, game.NoPiece))
 
-		if sanCastle.CastleKingSide {
-			g.castleKingSide()
-		}
+	} else if sanMove != nil {
 
-		if sanCastle.CastleQueenSide {
-			g.castleQueenSide()
+		switch sanMove.PromotionPiece {
+		case game.NoPiece, game.Queen, game.Rook, game.Bishop, game.Knight:
+		default:
+			// no move promotes to a king or a pawn
+			return false, &ErrNoMatchingMove{San: sanText}
 		}
 
-	} else if sanMove != nil {
-
 		// find the appropriate move from the list of moves based on the san notation
 		actualMoves := []game.ChessMove{}
 		for _, move := range g.moves.Moves {
+			// castling is only expressed through O-O / O-O-O
+			if move.IsCastle {
+				continue
+			}
+
 			if sanMove.ToFile != move.To.File || sanMove.ToRank != move.To.Rank {
 				continue
 			}
@@ -144,29 +182,24 @@ func (g *ChessGame) TrySanMove(sanText string) (bool, error) {
 			actualMoves = append(actualMoves, move)
 		}
 
-		if len(actualMoves) != 1 {
-			return false, fmt.Errorf("invalid move, multiple pieces can move to the same square: %v", actualMoves)
+		if len(actualMoves) == 0 {
+			return false, &ErrNoMatchingMove{San: sanText}
 		}
 
-		move := actualMoves[0]
-		promotionPiece := game.NoPiece
-		if move.IsPromotion {
-			switch sanMove.PromotionPiece {
-			case game.Queen, game.Rook, game.Bishop, game.Knight:
-				promotionPiece = sanMove.PromotionPiece
-			default:
-				return false, fmt.Errorf("invalid promotion piece %v in SAN %s", sanMove.PromotionPiece, sanText)
+		if len(actualMoves) > 1 {
+			candidates := make([]string, 0, len(actualMoves))
+			for _, move := range actualMoves {
+				candidates = append(candidates, san.FromMove(g.position, move, sanMove.PromotionPiece))
 			}
+			return false, &ErrAmbiguousMove{San: sanText, Candidates: candidates}
 		}
-		newPosition := g.position.Move(move.From.Location, move.To, promotionPiece)
 
-		g.position = newPosition
-		g.calculate()
+		move := actualMoves[0]
+		g.playMove(move, sanMove.PromotionPiece, san.FromMove(g.position, move, sanMove.PromotionPiece))
 	} else {
-		return false, fmt.Errorf("invalid SAN %s", sanText)
+		return false, &ErrMalformedSAN{San: sanText, Err: fmt.Errorf("neither a move nor a castle")}
 	}
 
-	g.recordCurrentPosition()
 	return true, nil
 }
 
@@ -214,6 +247,6 @@ func (g *ChessGame) IsCheck() bool {
 // GetResult returns the current result of the game.
 // It returns InProgress if the game is still ongoing.
 func (g *ChessGame) GetResult() game.GameResult {
-	g.calculate()
-	return g.moves.Result
+	result, _ := g.result()
+	return result
 }
@@ -335,6 +335,20 @@ func TestGetResult_DrawByThreefoldRepetition(t *testing.T) {
 	assert.True(t, g.GetResult().IsDecided())
 }
 
+func TestGetResult_RepetitionIgnoresEnPassantTargetThatCannotBeCaptured(t *testing.T) {
+	g := NewGame()
+
+	// after 1. e4 the en passant target e3 cannot be used, so the position with black to move
+	// repeats once the knights have gone out and back twice
+	moves := []string{"e4", "Nf6", "Nf3", "Ng8", "Ng1", "Nf6", "Nf3", "Ng8", "Ng1"}
+	for _, m := range moves {
+		ok, err := g.TrySanMove(m)
+		assert.True(t, ok, "move %s should succeed", m)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, game.DrawRepetition, g.GetResult())
+}
+
 func TestGetResult_NoMoreMovesAfterDrawRepetition(t *testing.T) {
 	g := NewGame()
 
@@ -0,0 +1,72 @@
+package chess
+
+import (
+	"github.com/jerhon/chess/pkg/chess/clock"
+	"github.com/jerhon/chess/pkg/chess/game"
+)
+
+// SetClock times the game with a clock, starting the time of the player to move when the clock is stopped.
+// Each move presses the clock, the clock stops when the game ends and a flag fall loses the game unless the
+// opponent can't checkmate, which draws it. Taking moves back or replaying them doesn't give time back, the
+// time of the player to move runs on from the position reached.
+func (g *ChessGame) SetClock(c *clock.Clock) error {
+	if c != nil && c.Running() == game.NoColor {
+		if err := c.Start(g.position.PlayerToMove); err != nil {
+			return err
+		}
+	}
+	g.clock = c
+	return nil
+}
+
+// Clock returns the clock of the game, nil when it isn't timed.
+func (g *ChessGame) Clock() *clock.Clock {
+	return g.clock
+}
+
+// pressClock completes the move just played on the clock, and stops the clock once the position ends the game.
+// A stopped clock is left alone, the move is played without it.
+func (g *ChessGame) pressClock() {
+	if g.clock == nil || g.clock.Running() == game.NoColor {
+		return
+	}
+	// the flag of the player was checked before the move was accepted
+	_ = g.clock.Press()
+	if g.positionResult() != game.InProgress {
+		g.clock.Stop()
+	}
+}
+
+// restartClock runs the time of the player to move after the position changed by taking moves back or
+// replaying them, or stops the clock when that position ends the game. A fallen flag is left as it is.
+func (g *ChessGame) restartClock() {
+	if g.clock == nil {
+		return
+	}
+	if g.GetResult() != game.InProgress {
+		g.clock.Stop()
+		return
+	}
+	_ = g.clock.Start(g.position.PlayerToMove)
+}
+
+// flagResult returns the result of the game once the flag of a player has fallen: the opponent wins if they
+// could still checkmate, otherwise the game is drawn. It returns false while no flag has fallen.
+func (g *ChessGame) flagResult() (game.GameResult, bool) {
+	if g.clock == nil {
+		return game.InProgress, false
+	}
+	color, flagged := g.clock.Flagged()
+	if !flagged {
+		return game.InProgress, false
+	}
+
+	opponent := color.OppositeColor()
+	if !game.AnalyzeDeadPosition(g.position).CanMate(opponent) {
+		return game.DrawInsufficientMaterial, true
+	}
+	if opponent == game.BlackPiece {
+		return game.BlackWins, true
+	}
+	return game.WhiteWins, true
+}
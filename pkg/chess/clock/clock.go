@@ -0,0 +1,222 @@
+// Package clock is a chess clock for sudden death, Fischer, Bronstein, simple delay, multi-stage and hourglass
+// time controls, reading the time from a source that tests can replace.
+package clock
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jerhon/chess/pkg/chess/game"
+)
+
+// ErrFlagFall is returned when a player presses the clock after their time has run out.
+var ErrFlagFall = errors.New("flag fell")
+
+// Clock keeps the time of both players, running the time of one of them at once.
+type Clock struct {
+	control TimeControl
+	now     func() time.Time
+
+	// remaining is the time each player had when their time last stopped
+	remaining [2]time.Duration
+	// moves is the number of moves each player has completed
+	moves [2]int
+	// stage is the index of the stage each player is in and stageEnd the move that ends it, 0 for none
+	stage    [2]int
+	stageEnd [2]int
+
+	// running is the color whose time runs since started, NoColor when the clock is stopped
+	running game.ColorType
+	started time.Time
+	// flagged is the color whose time ran out, NoColor while there is none
+	flagged game.ColorType
+}
+
+// NewClock returns a stopped clock for a time control. The clock reads the time from now, time.Now when nil.
+func NewClock(control TimeControl, now func() time.Time) (*Clock, error) {
+	if err := control.Validate(); err != nil {
+		return nil, err
+	}
+	if now == nil {
+		now = time.Now
+	}
+
+	c := &Clock{control: control, now: now, running: game.NoColor, flagged: game.NoColor}
+	first := control.Stages[0]
+	for i := range c.remaining {
+		c.remaining[i] = first.Time
+		c.stageEnd[i] = first.Moves
+	}
+	return c, nil
+}
+
+// TimeControl returns the time control of the clock.
+func (c *Clock) TimeControl() TimeControl {
+	return c.control
+}
+
+// Start runs the time of color, stopping the time of the other player without counting a move.
+func (c *Clock) Start(color game.ColorType) error {
+	if color != game.WhitePiece && color != game.BlackPiece {
+		return fmt.Errorf("invalid color %q, expected white or black", color)
+	}
+	if c.update() {
+		return ErrFlagFall
+	}
+	c.Stop()
+	c.running = color
+	c.started = c.now()
+	return nil
+}
+
+// Stop stops the clock without counting a move, e.g. at the end of the game or to pause it.
+func (c *Clock) Stop() {
+	if c.update() || c.running == game.NoColor {
+		return
+	}
+	c.settle(c.now().Sub(c.started))
+	c.running = game.NoColor
+}
+
+// Press completes the move of the player whose time runs: their time stops, the time of the move is given
+// back as the time control says and the opponent's time starts. It returns ErrFlagFall when the time of the
+// player ran out before the move.
+func (c *Clock) Press() error {
+	if c.update() {
+		return ErrFlagFall
+	}
+	if c.running == game.NoColor {
+		return fmt.Errorf("clock is not running")
+	}
+
+	now := c.now()
+	elapsed := now.Sub(c.started)
+	c.settle(elapsed)
+
+	i := index(c.running)
+	switch c.control.Mode {
+	case Fischer:
+		c.remaining[i] += c.control.Increment
+	case Bronstein:
+		c.remaining[i] += min(elapsed, c.control.Increment)
+	}
+
+	c.moves[i]++
+	if c.stageEnd[i] > 0 && c.moves[i] >= c.stageEnd[i] {
+		c.nextStage(i)
+	}
+
+	c.running = c.running.OppositeColor()
+	c.started = now
+	return nil
+}
+
+// Remaining returns the time left to color, not counting a delay that has yet to run.
+func (c *Clock) Remaining(color game.ColorType) time.Duration {
+	if color != game.WhitePiece && color != game.BlackPiece {
+		return 0
+	}
+	c.update()
+	remaining := c.remaining[index(color)]
+	if c.running == game.NoColor {
+		return remaining
+	}
+
+	elapsed := c.now().Sub(c.started)
+	switch {
+	case color == c.running:
+		remaining -= c.charge(elapsed)
+	case c.control.Mode == Hourglass:
+		remaining += elapsed
+	}
+	return remaining
+}
+
+// Running returns the color whose time runs, NoColor when the clock is stopped.
+func (c *Clock) Running() game.ColorType {
+	c.update()
+	return c.running
+}
+
+// Flagged returns the color whose time ran out, false while both players have time left.
+func (c *Clock) Flagged() (game.ColorType, bool) {
+	c.update()
+	return c.flagged, c.flagged != game.NoColor
+}
+
+// Moves returns the number of moves color has completed on the clock.
+func (c *Clock) Moves(color game.ColorType) int {
+	if color != game.WhitePiece && color != game.BlackPiece {
+		return 0
+	}
+	return c.moves[index(color)]
+}
+
+// update stops the clock once the time of the running player has run out, and returns true when a flag has
+// fallen.
+func (c *Clock) update() bool {
+	if c.flagged != game.NoColor {
+		return true
+	}
+	if c.running == game.NoColor {
+		return false
+	}
+	// the player has until the delay and their remaining time are used up
+	limit := c.remaining[index(c.running)]
+	if c.control.Mode == SimpleDelay {
+		limit += c.control.Increment
+	}
+	if c.now().Sub(c.started) < limit {
+		return false
+	}
+
+	c.settle(limit)
+	c.flagged = c.running
+	c.running = game.NoColor
+	return true
+}
+
+// settle takes the time the running player used from their time.
+func (c *Clock) settle(elapsed time.Duration) {
+	i := index(c.running)
+	c.remaining[i] -= c.charge(elapsed)
+	if c.control.Mode == Hourglass {
+		c.remaining[1-i] += elapsed
+	}
+}
+
+// charge returns the part of the time used for a move that counts against the player.
+func (c *Clock) charge(elapsed time.Duration) time.Duration {
+	if c.control.Mode == SimpleDelay {
+		return max(elapsed-c.control.Increment, 0)
+	}
+	return elapsed
+}
+
+// nextStage moves a player to the stage after their current one, the last stage repeating, and gives them its
+// time.
+func (c *Clock) nextStage(i int) {
+	next := min(c.stage[i]+1, len(c.control.Stages)-1)
+	stage := c.control.Stages[next]
+	c.stage[i] = next
+	if stage.Moves > 0 {
+		c.stageEnd[i] += stage.Moves
+	} else {
+		c.stageEnd[i] = 0
+	}
+
+	if c.control.Replace {
+		c.remaining[i] = stage.Time
+	} else {
+		c.remaining[i] += stage.Time
+	}
+}
+
+// index maps white to 0 and black to 1.
+func index(color game.ColorType) int {
+	if color == game.BlackPiece {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,214 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jerhon/chess/pkg/chess/game"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTime is a time source that only moves when told to.
+type fakeTime struct {
+	now time.Time
+}
+
+func (f *fakeTime) Now() time.Time { return f.now }
+
+func (f *fakeTime) advance(d time.Duration) { f.now = f.now.Add(d) }
+
+// newTestClock returns a clock for control with white's time running.
+func newTestClock(t *testing.T, control TimeControl) (*Clock, *fakeTime) {
+	t.Helper()
+	source := &fakeTime{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	c, err := NewClock(control, source.Now)
+	require.NoError(t, err)
+	require.NoError(t, c.Start(game.WhitePiece))
+	return c, source
+}
+
+// move lets the running player think for d, then press the clock.
+func move(t *testing.T, c *Clock, source *fakeTime, d time.Duration) {
+	t.Helper()
+	source.advance(d)
+	require.NoError(t, c.Press())
+}
+
+func TestClock_SuddenDeath(t *testing.T) {
+	c, source := newTestClock(t, NewSuddenDeath(5*time.Minute))
+
+	move(t, c, source, 10*time.Second)
+	assert.Equal(t, 4*time.Minute+50*time.Second, c.Remaining(game.WhitePiece))
+	assert.Equal(t, game.BlackPiece, c.Running())
+
+	source.advance(30 * time.Second)
+	assert.Equal(t, 4*time.Minute+30*time.Second, c.Remaining(game.BlackPiece))
+	assert.Equal(t, 4*time.Minute+50*time.Second, c.Remaining(game.WhitePiece))
+	assert.Equal(t, 1, c.Moves(game.WhitePiece))
+	assert.Equal(t, 0, c.Moves(game.BlackPiece))
+}
+
+func TestClock_FlagFall(t *testing.T) {
+	c, source := newTestClock(t, NewSuddenDeath(time.Minute))
+	move(t, c, source, 50*time.Second)
+
+	source.advance(59 * time.Second)
+	_, flagged := c.Flagged()
+	assert.False(t, flagged)
+
+	source.advance(2 * time.Second)
+	color, flagged := c.Flagged()
+	assert.True(t, flagged)
+	assert.Equal(t, game.BlackPiece, color)
+	assert.Equal(t, time.Duration(0), c.Remaining(game.BlackPiece))
+	assert.Equal(t, game.NoColor, c.Running())
+
+	// the clock stays stopped once a flag has fallen
+	assert.ErrorIs(t, c.Press(), ErrFlagFall)
+	assert.ErrorIs(t, c.Start(game.WhitePiece), ErrFlagFall)
+	source.advance(time.Minute)
+	assert.Equal(t, 10*time.Second, c.Remaining(game.WhitePiece))
+}
+
+func TestClock_Fischer(t *testing.T) {
+	c, source := newTestClock(t, NewFischer(3*time.Minute, 2*time.Second))
+
+	move(t, c, source, 5*time.Second)
+	assert.Equal(t, 2*time.Minute+57*time.Second, c.Remaining(game.WhitePiece))
+	move(t, c, source, time.Second)
+	assert.Equal(t, 3*time.Minute+time.Second, c.Remaining(game.BlackPiece))
+}
+
+func TestClock_Bronstein(t *testing.T) {
+	c, source := newTestClock(t, NewBronstein(time.Minute, 5*time.Second))
+
+	// a quick move gets all its time back, a slow one only the delay
+	move(t, c, source, 3*time.Second)
+	assert.Equal(t, time.Minute, c.Remaining(game.WhitePiece))
+	move(t, c, source, 10*time.Second)
+	assert.Equal(t, 55*time.Second, c.Remaining(game.BlackPiece))
+}
+
+func TestClock_SimpleDelay(t *testing.T) {
+	c, source := newTestClock(t, NewSimpleDelay(time.Minute, 5*time.Second))
+
+	source.advance(3 * time.Second)
+	assert.Equal(t, time.Minute, c.Remaining(game.WhitePiece))
+	source.advance(4 * time.Second)
+	assert.Equal(t, 58*time.Second, c.Remaining(game.WhitePiece))
+	require.NoError(t, c.Press())
+	assert.Equal(t, 58*time.Second, c.Remaining(game.WhitePiece))
+
+	// the flag falls once the delay and the time left are used up
+	source.advance(time.Minute + 4*time.Second)
+	_, flagged := c.Flagged()
+	assert.False(t, flagged)
+	source.advance(time.Second)
+	color, flagged := c.Flagged()
+	assert.True(t, flagged)
+	assert.Equal(t, game.BlackPiece, color)
+}
+
+func TestClock_Hourglass(t *testing.T) {
+	c, source := newTestClock(t, NewHourglass(time.Minute))
+
+	source.advance(20 * time.Second)
+	assert.Equal(t, 40*time.Second, c.Remaining(game.WhitePiece))
+	assert.Equal(t, 80*time.Second, c.Remaining(game.BlackPiece))
+	require.NoError(t, c.Press())
+
+	move(t, c, source, 5*time.Second)
+	assert.Equal(t, 45*time.Second, c.Remaining(game.WhitePiece))
+	assert.Equal(t, 75*time.Second, c.Remaining(game.BlackPiece))
+}
+
+func TestClock_MultiStage(t *testing.T) {
+	// 40 moves in 90 minutes, then 30 minutes for the rest of the game, with 30 seconds a move
+	control := TimeControl{
+		Stages:    []Stage{{Moves: 40, Time: 90 * time.Minute}, {Time: 30 * time.Minute}},
+		Mode:      Fischer,
+		Increment: 30 * time.Second,
+	}
+	c, source := newTestClock(t, control)
+
+	for range 39 {
+		move(t, c, source, time.Minute)
+		move(t, c, source, time.Minute)
+	}
+	assert.Equal(t, 90*time.Minute-39*30*time.Second, c.Remaining(game.WhitePiece))
+
+	move(t, c, source, time.Minute)
+	assert.Equal(t, 90*time.Minute-40*30*time.Second+30*time.Minute, c.Remaining(game.WhitePiece))
+	assert.Equal(t, 90*time.Minute-39*30*time.Second, c.Remaining(game.BlackPiece))
+
+	// the last stage is for the rest of the game
+	for range 40 {
+		move(t, c, source, time.Minute)
+		move(t, c, source, time.Minute)
+	}
+	assert.Equal(t, 90*time.Minute-80*30*time.Second+30*time.Minute, c.Remaining(game.WhitePiece))
+}
+
+func TestClock_RepeatingStage(t *testing.T) {
+	c, source := newTestClock(t, TimeControl{Stages: []Stage{{Moves: 2, Time: time.Minute}}})
+
+	move(t, c, source, 10*time.Second)
+	move(t, c, source, 10*time.Second)
+	move(t, c, source, 10*time.Second)
+	assert.Equal(t, 100*time.Second, c.Remaining(game.WhitePiece))
+	move(t, c, source, 10*time.Second)
+	move(t, c, source, 10*time.Second)
+	move(t, c, source, 10*time.Second)
+	move(t, c, source, 10*time.Second)
+	assert.Equal(t, 2*time.Minute+20*time.Second, c.Remaining(game.WhitePiece))
+}
+
+func TestClock_DailyLimitPerMove(t *testing.T) {
+	control, err := ParseChessCom("1/259200")
+	require.NoError(t, err)
+	c, source := newTestClock(t, control)
+
+	move(t, c, source, 2*24*time.Hour)
+	assert.Equal(t, 72*time.Hour, c.Remaining(game.WhitePiece))
+	source.advance(71 * time.Hour)
+	assert.Equal(t, time.Hour, c.Remaining(game.BlackPiece))
+	require.NoError(t, c.Press())
+	assert.Equal(t, 72*time.Hour, c.Remaining(game.BlackPiece))
+}
+
+func TestClock_StopAndStart(t *testing.T) {
+	c, source := newTestClock(t, NewFischer(time.Minute, 10*time.Second))
+
+	source.advance(10 * time.Second)
+	c.Stop()
+	assert.Equal(t, game.NoColor, c.Running())
+	assert.Error(t, c.Press())
+
+	// stopping counts no move and adds no increment
+	source.advance(time.Hour)
+	assert.Equal(t, 50*time.Second, c.Remaining(game.WhitePiece))
+	assert.Equal(t, 0, c.Moves(game.WhitePiece))
+
+	require.NoError(t, c.Start(game.WhitePiece))
+	move(t, c, source, 10*time.Second)
+	assert.Equal(t, 50*time.Second, c.Remaining(game.WhitePiece))
+	assert.Error(t, c.Start(game.NoColor))
+}
+
+func TestNewClock_InvalidTimeControl(t *testing.T) {
+	tests := map[string]TimeControl{
+		"no stage":           {},
+		"no time":            {Stages: []Stage{{Moves: 40}}},
+		"rest of game first": {Stages: []Stage{{Time: time.Minute}, {Time: time.Minute}}},
+		"negative moves":     {Stages: []Stage{{Moves: -1, Time: time.Minute}}},
+		"negative increment": {Stages: []Stage{{Time: time.Minute}}, Increment: -time.Second},
+		"unknown mode":       {Stages: []Stage{{Time: time.Minute}}, Mode: Mode(42)},
+	}
+	for name, control := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewClock(control, nil)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package clock
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Mode is how a clock gives time back for each move.
+type Mode int
+
+const (
+	// SuddenDeath gives no time back, the stages are all the time there is.
+	SuddenDeath Mode = iota
+	// Fischer adds the increment after each move.
+	Fischer
+	// Bronstein gives back the time used for a move, up to the increment.
+	Bronstein
+	// SimpleDelay waits for the increment before the time of a move starts to run, the US delay.
+	SimpleDelay
+	// Hourglass adds the time one player uses to the time of the other.
+	Hourglass
+)
+
+// String returns the name of the mode.
+func (m Mode) String() string {
+	switch m {
+	case SuddenDeath:
+		return "Sudden Death"
+	case Fischer:
+		return "Fischer"
+	case Bronstein:
+		return "Bronstein"
+	case SimpleDelay:
+		return "Simple Delay"
+	case Hourglass:
+		return "Hourglass"
+	default:
+		return "Unknown"
+	}
+}
+
+// Stage is a period of a time control, e.g. 90 minutes for the first 40 moves.
+type Stage struct {
+	// Moves is the number of moves the time is for, 0 for the rest of the game
+	Moves int
+	Time  time.Duration
+}
+
+// TimeControl describes the time each player has. A player starts with the time of the first stage and the
+// time of each following stage is added once the moves of the one before are played. A last stage with moves
+// repeats, 40/120 gives another two hours every 40 moves.
+type TimeControl struct {
+	Stages []Stage
+	Mode   Mode
+	// Increment is the time added by Fischer, the most given back by Bronstein and the delay of SimpleDelay
+	Increment time.Duration
+	// Replace sets the time left to the time of a stage when it begins instead of adding it, as for the
+	// correspondence controls with a limit per move
+	Replace bool
+}
+
+// NewSuddenDeath returns a time control of base time for the game.
+func NewSuddenDeath(base time.Duration) TimeControl {
+	return TimeControl{Stages: []Stage{{Time: base}}, Mode: SuddenDeath}
+}
+
+// NewFischer returns a time control of base time for the game with increment added after each move.
+func NewFischer(base time.Duration, increment time.Duration) TimeControl {
+	return TimeControl{Stages: []Stage{{Time: base}}, Mode: Fischer, Increment: increment}
+}
+
+// NewBronstein returns a time control of base time for the game giving back the time of each move up to
+// delay.
+func NewBronstein(base time.Duration, delay time.Duration) TimeControl {
+	return TimeControl{Stages: []Stage{{Time: base}}, Mode: Bronstein, Increment: delay}
+}
+
+// NewSimpleDelay returns a time control of base time for the game whose clock waits delay before it runs.
+func NewSimpleDelay(base time.Duration, delay time.Duration) TimeControl {
+	return TimeControl{Stages: []Stage{{Time: base}}, Mode: SimpleDelay, Increment: delay}
+}
+
+// NewHourglass returns a time control where the players share twice base time.
+func NewHourglass(base time.Duration) TimeControl {
+	return TimeControl{Stages: []Stage{{Time: base}}, Mode: Hourglass}
+}
+
+// Validate returns an error when the time control can't be played: it has no stage, a stage has no time or a
+// stage other than the last is for the rest of the game.
+func (control TimeControl) Validate() error {
+	if len(control.Stages) == 0 {
+		return fmt.Errorf("time control has no stage")
+	}
+	for i, stage := range control.Stages {
+		if stage.Time <= 0 {
+			return fmt.Errorf("stage %d has no time", i+1)
+		}
+		if stage.Moves < 0 {
+			return fmt.Errorf("stage %d has %d moves", i+1, stage.Moves)
+		}
+		if stage.Moves == 0 && i < len(control.Stages)-1 {
+			return fmt.Errorf("stage %d is for the rest of the game but is followed by another stage", i+1)
+		}
+	}
+	if control.Increment < 0 {
+		return fmt.Errorf("increment %s is negative", control.Increment)
+	}
+	if control.Mode < SuddenDeath || control.Mode > Hourglass {
+		return fmt.Errorf("unknown mode %d", control.Mode)
+	}
+	return nil
+}
+
+// ParseChessCom parses the time_control of a chess.com game: "600" for sudden death, "180+2" for a Fischer
+// increment, both in seconds, and "1/259200" for daily games with a limit of seconds per move.
+func ParseChessCom(text string) (TimeControl, error) {
+	text = strings.TrimSpace(text)
+	if moves, limit, found := strings.Cut(text, "/"); found {
+		if moves != "1" {
+			return TimeControl{}, fmt.Errorf("invalid time control %q, daily games have one move per period", text)
+		}
+		perMove, err := parseSeconds(limit)
+		if err != nil {
+			return TimeControl{}, fmt.Errorf("invalid time control %q: %w", text, err)
+		}
+		return TimeControl{Stages: []Stage{{Moves: 1, Time: perMove}}, Mode: SuddenDeath, Replace: true}, nil
+	}
+
+	base, increment, hasIncrement := strings.Cut(text, "+")
+	baseTime, err := parseSeconds(base)
+	if err != nil {
+		return TimeControl{}, fmt.Errorf("invalid time control %q: %w", text, err)
+	}
+	if !hasIncrement {
+		return NewSuddenDeath(baseTime), nil
+	}
+	incrementTime, err := parseSeconds(increment)
+	if err != nil {
+		return TimeControl{}, fmt.Errorf("invalid time control %q: %w", text, err)
+	}
+	return NewFischer(baseTime, incrementTime), nil
+}
+
+func parseSeconds(text string) (time.Duration, error) {
+	seconds, err := strconv.ParseFloat(text, 64)
+	if err != nil || !(seconds >= 0) || math.IsInf(seconds, 0) {
+		return 0, fmt.Errorf("invalid number of seconds %q", text)
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
@@ -0,0 +1,42 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseChessCom(t *testing.T) {
+	tests := map[string]TimeControl{
+		"600":      NewSuddenDeath(10 * time.Minute),
+		"180+2":    NewFischer(3*time.Minute, 2*time.Second),
+		" 60+0 ":   NewFischer(time.Minute, 0),
+		"1/259200": {Stages: []Stage{{Moves: 1, Time: 72 * time.Hour}}, Replace: true},
+		"1/86400":  {Stages: []Stage{{Moves: 1, Time: 24 * time.Hour}}, Replace: true},
+	}
+	for text, want := range tests {
+		t.Run(text, func(t *testing.T) {
+			control, err := ParseChessCom(text)
+			require.NoError(t, err)
+			assert.Equal(t, want, control)
+			assert.NoError(t, control.Validate())
+		})
+	}
+}
+
+func TestParseChessCom_Invalid(t *testing.T) {
+	for _, text := range []string{"", "-", "abc", "180+", "+2", "180+x", "2/86400", "1/", "-60", "NaN", "Inf"} {
+		t.Run(text, func(t *testing.T) {
+			_, err := ParseChessCom(text)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestMode_String(t *testing.T) {
+	assert.Equal(t, "Fischer", Fischer.String())
+	assert.Equal(t, "Simple Delay", SimpleDelay.String())
+	assert.Equal(t, "Unknown", Mode(42).String())
+}
@@ -0,0 +1,159 @@
+package chess
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jerhon/chess/pkg/chess/clock"
+	"github.com/jerhon/chess/pkg/chess/game"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// timedGame times a game with control, returning the function that moves the time of its clock on.
+func timedGame(t *testing.T, g *ChessGame, control clock.TimeControl) func(time.Duration) {
+	t.Helper()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	c, err := clock.NewClock(control, func() time.Time { return now })
+	require.NoError(t, err)
+	require.NoError(t, g.SetClock(c))
+	return func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestSetClock_MovesPressTheClock(t *testing.T) {
+	g := NewGame()
+	advance := timedGame(t, g, clock.NewFischer(time.Minute, 2*time.Second))
+	assert.Equal(t, game.WhitePiece, g.Clock().Running())
+
+	advance(10 * time.Second)
+	playMoves(t, g, "e4")
+	assert.Equal(t, game.BlackPiece, g.Clock().Running())
+	assert.Equal(t, 52*time.Second, g.Clock().Remaining(game.WhitePiece))
+
+	advance(5 * time.Second)
+	playMoves(t, g, "e5")
+	assert.Equal(t, 57*time.Second, g.Clock().Remaining(game.BlackPiece))
+	assert.Equal(t, 1, g.Clock().Moves(game.BlackPiece))
+}
+
+func TestSetClock_UndoRunsTheClockOfThePlayerToMove(t *testing.T) {
+	g := NewGame()
+	advance := timedGame(t, g, clock.NewSuddenDeath(time.Minute))
+
+	advance(5 * time.Second)
+	playMoves(t, g, "e4")
+	require.True(t, g.Undo())
+	assert.Equal(t, game.WhitePiece, g.Clock().Running())
+
+	advance(5 * time.Second)
+	playMoves(t, g, "d4")
+	assert.Equal(t, game.BlackPiece, g.Clock().Running())
+	assert.Equal(t, 50*time.Second, g.Clock().Remaining(game.WhitePiece))
+	assert.Equal(t, time.Minute, g.Clock().Remaining(game.BlackPiece))
+
+	require.True(t, g.Undo())
+	require.True(t, g.Redo())
+	assert.Equal(t, game.BlackPiece, g.Clock().Running())
+	require.NoError(t, g.GoToPly(0))
+	assert.Equal(t, game.WhitePiece, g.Clock().Running())
+}
+
+func TestSetClock_UndoAfterMateRestartsTheClock(t *testing.T) {
+	g := NewGame()
+	advance := timedGame(t, g, clock.NewSuddenDeath(time.Minute))
+	playMoves(t, g, "f3", "e5", "g4", "Qh4#")
+	assert.Equal(t, game.NoColor, g.Clock().Running())
+
+	require.True(t, g.Undo())
+	assert.Equal(t, game.BlackPiece, g.Clock().Running())
+
+	// replaying the mate stops the clock again
+	require.True(t, g.Redo())
+	assert.Equal(t, game.NoColor, g.Clock().Running())
+
+	require.True(t, g.Undo())
+	advance(10 * time.Second)
+	playMoves(t, g, "Nc6")
+	assert.Equal(t, 50*time.Second, g.Clock().Remaining(game.BlackPiece))
+	assert.Equal(t, game.WhitePiece, g.Clock().Running())
+}
+
+func TestSetClock_FlagFallLoses(t *testing.T) {
+	g := NewGame()
+	advance := timedGame(t, g, clock.NewSuddenDeath(time.Minute))
+	playMoves(t, g, "e4")
+
+	advance(time.Minute)
+	assert.Equal(t, game.WhiteWins, g.GetResult())
+	assert.Equal(t, game.ReasonTimeout, g.GetResultReason())
+	// reading the result leaves the game as it was
+	assert.Nil(t, g.ending)
+	assert.Equal(t, game.NoColor, g.Clock().Running())
+
+	_, err := g.TrySanMove("e5")
+	assert.ErrorContains(t, err, "game is over")
+}
+
+func TestSetClock_FlagFallAgainstALoneKingDraws(t *testing.T) {
+	// black runs out of time, but white has only the king left
+	g := newGameFromFen(t, "4k3/8/8/8/8/8/r7/4K3 b - - 0 60")
+	advance := timedGame(t, g, clock.NewSuddenDeath(time.Minute))
+
+	advance(2 * time.Minute)
+	assert.Equal(t, game.DrawInsufficientMaterial, g.GetResult())
+	assert.Equal(t, game.ReasonTimeout, g.GetResultReason())
+
+	// white running out of time against the rook loses
+	g = newGameFromFen(t, "4k3/8/8/8/8/8/r7/4K3 w - - 0 60")
+	advance = timedGame(t, g, clock.NewSuddenDeath(time.Minute))
+	advance(2 * time.Minute)
+	assert.Equal(t, game.BlackWins, g.GetResult())
+}
+
+func TestSetClock_FlagFallMatrix(t *testing.T) {
+	// the player to move runs out of time
+	tests := []struct {
+		name   string
+		fen    string
+		result game.GameResult
+		reason game.ResultReason
+	}{
+		{"knight side against a pawn", "4k3/4p3/8/8/8/8/8/4K1N1 w - - 0 60", game.BlackWins, game.ReasonTimeout},
+		{"pawn side against a knight", "4k3/4p3/8/8/8/8/8/4K1N1 b - - 0 60", game.WhiteWins, game.ReasonTimeout},
+		{"pawn side against a bishop", "4k3/p7/8/8/8/8/8/2B1K3 b - - 0 60", game.WhiteWins, game.ReasonTimeout},
+		{"bishops on opposite colors", "2b1k3/8/8/8/8/8/8/2B1K3 w - - 0 60", game.BlackWins, game.ReasonTimeout},
+		{"bishops on opposite colors, black flagged", "2b1k3/8/8/8/8/8/8/2B1K3 b - - 0 60", game.WhiteWins, game.ReasonTimeout},
+		{"lone king against a knight", "4k3/8/8/8/8/8/8/4K1N1 w - - 0 60", game.DrawInsufficientMaterial, game.ReasonInsufficientMaterial},
+		{"bishops on the same color", "4kb2/8/8/8/8/8/8/2B1K3 w - - 0 60", game.DrawInsufficientMaterial, game.ReasonInsufficientMaterial},
+		{"locked wall", "4k3/8/8/1p1p1p1p/pPpPpPpP/P1P1P1P1/8/4K3 w - - 0 60", game.DrawDeadPosition, game.ReasonDeadPosition},
+		{"locked wall open to en passant", "4k3/8/4p3/1p1pPp1p/pPpP1PpP/P1P3P1/8/4K3 w - d6 0 60", game.BlackWins, game.ReasonTimeout},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			g := newGameFromFen(t, test.fen)
+			advance := timedGame(t, g, clock.NewSuddenDeath(time.Minute))
+
+			advance(2 * time.Minute)
+			assert.Equal(t, test.result, g.GetResult())
+			assert.Equal(t, test.reason, g.GetResultReason())
+		})
+	}
+}
+
+func TestSetClock_StopsWhenTheGameEnds(t *testing.T) {
+	g := NewGame()
+	advance := timedGame(t, g, clock.NewSuddenDeath(time.Minute))
+	playMoves(t, g, "f3", "e5", "g4", "Qh4#")
+	assert.Equal(t, game.NoColor, g.Clock().Running())
+
+	advance(time.Hour)
+	assert.Equal(t, game.BlackWins, g.GetResult())
+	assert.Equal(t, game.ReasonCheckmate, g.GetResultReason())
+
+	g = NewGame()
+	advance = timedGame(t, g, clock.NewSuddenDeath(time.Minute))
+	require.NoError(t, g.Resign(game.WhitePiece))
+	assert.Equal(t, game.NoColor, g.Clock().Running())
+	advance(time.Hour)
+	assert.Equal(t, game.ReasonResignation, g.GetResultReason())
+}
@@ -0,0 +1,255 @@
+// Package engine plays chess: it searches a position with iterative deepening negamax alpha-beta, a
+// quiescence search and a transposition table, within the limits of a UCI go command.
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jerhon/chess/pkg/chess"
+	"github.com/jerhon/chess/pkg/chess/game"
+	"github.com/jerhon/chess/pkg/chess_uci"
+)
+
+const (
+	// DefaultHash is the default size of the transposition table in megabytes
+	DefaultHash = 16
+	minHash     = 1
+	maxHash     = 1024
+)
+
+// Engine searches chess positions. It implements chess_uci.Engine so it can be served over UCI, and can be
+// used directly as a library through SearchGame.
+type Engine struct {
+	// mutex guards the table and clock, which the UCI server may use while a search runs
+	mutex sync.Mutex
+	// hashMegabytes is the size of the transposition table
+	hashMegabytes int
+	table         *transpositionTable
+	// clock times the running search so PonderHit can start it
+	clock *searchClock
+	// chess960 writes castling moves as the king taking its own rook, set with the UCI_Chess960 option
+	chess960 bool
+}
+
+// Result is the outcome of a search.
+type Result struct {
+	// Move is the best move in UCI notation, empty when the position has no legal moves
+	Move string
+	// PV is the principal variation in UCI notation, starting with Move
+	PV []string
+	// Score is the score of the position for the side to move
+	Score chess_uci.UciScore
+	// Depth is the depth of the last completed iteration
+	Depth int
+	Nodes int
+}
+
+// BestMove returns the result as a UCI bestmove, pondering on the expected reply.
+func (r Result) BestMove() chess_uci.BestMove {
+	best := chess_uci.BestMove{Move: r.Move}
+	if len(r.PV) > 1 {
+		best.Ponder = r.PV[1]
+	}
+	return best
+}
+
+func NewEngine() *Engine {
+	return &Engine{hashMegabytes: DefaultHash, table: newTranspositionTable(DefaultHash)}
+}
+
+func (e *Engine) Name() string   { return "jerhon chess" }
+func (e *Engine) Author() string { return "the jerhon/chess authors" }
+
+func (e *Engine) Options() []chess_uci.UciOption {
+	hashDefault, checkDefault := strconv.Itoa(DefaultHash), "false"
+	minimum, maximum := minHash, maxHash
+	return []chess_uci.UciOption{
+		{OptionName: "Hash", Type: "spin", Default: &hashDefault, Min: &minimum, Max: &maximum},
+		{OptionName: "Clear Hash", Type: "button"},
+		{OptionName: "Ponder", Type: "check", Default: &checkDefault},
+		{OptionName: "UCI_Chess960", Type: "check", Default: &checkDefault},
+	}
+}
+
+// SetOption sets the size of the transposition table in megabytes with Hash or empties it with Clear Hash.
+// UCI_Chess960 switches castling moves to the king takes rook notation. Ponder is accepted as the engine
+// ponders whenever asked to.
+func (e *Engine) SetOption(name string, value *string) error {
+	switch name {
+	case "Hash":
+		if value == nil {
+			return fmt.Errorf("option Hash requires a value")
+		}
+		megabytes, err := strconv.Atoi(*value)
+		if err != nil || megabytes < minHash || megabytes > maxHash {
+			return fmt.Errorf("option Hash must be between %d and %d, got %s", minHash, maxHash, *value)
+		}
+		e.replaceTable(megabytes)
+	case "Clear Hash":
+		e.replaceTable(0)
+	case "Ponder":
+	case "UCI_Chess960":
+		if value == nil || (*value != "true" && *value != "false") {
+			return fmt.Errorf("option UCI_Chess960 must be true or false")
+		}
+		e.mutex.Lock()
+		e.chess960 = *value == "true"
+		e.mutex.Unlock()
+	default:
+		return fmt.Errorf("unknown option %s", name)
+	}
+	return nil
+}
+
+// NewGame empties the transposition table.
+func (e *Engine) NewGame() {
+	e.replaceTable(0)
+}
+
+// replaceTable swaps in a new empty table of the given size, or of the current size when megabytes is zero.
+// Any running search keeps the table it started with.
+func (e *Engine) replaceTable(megabytes int) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if megabytes > 0 {
+		e.hashMegabytes = megabytes
+	}
+	e.table = newTranspositionTable(e.hashMegabytes)
+}
+
+// Search implements chess_uci.Engine.
+func (e *Engine) Search(ctx context.Context, chessGame *chess.ChessGame, limits chess_uci.CmdGo, info func(chess_uci.SearchInfo)) chess_uci.BestMove {
+	return e.SearchGame(ctx, chessGame, limits, info).BestMove()
+}
+
+// PonderHit starts the clock of a search started with ponder.
+func (e *Engine) PonderHit() {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if e.clock != nil {
+		e.clock.start(time.Now())
+	}
+}
+
+// SearchGame searches the current position of the game within the limits of a go command until they are
+// reached or ctx is cancelled. The game's earlier positions are used to recognise repetitions. info, which may
+// be nil, is called after every completed iteration. The first iteration is always completed so a legal move
+// is returned whenever there is one.
+func (e *Engine) SearchGame(ctx context.Context, chessGame *chess.ChessGame, limits chess_uci.CmdGo, info func(chess_uci.SearchInfo)) Result {
+	start := time.Now()
+	position := chessGame.GetPosition()
+	searchLimits := newSearchLimits(limits, position.PlayerToMove)
+	clock := newSearchClock(searchLimits)
+	if !limits.Ponder {
+		clock.start(start)
+	}
+
+	e.mutex.Lock()
+	e.clock = clock
+	s := &searcher{ctx: ctx, table: e.table, clock: clock, limits: searchLimits}
+	chess960 := e.chess960
+	e.mutex.Unlock()
+	defer func() {
+		e.mutex.Lock()
+		e.clock = nil
+		e.mutex.Unlock()
+	}()
+
+	for _, played := range chessGame.Positions() {
+		s.path = append(s.path, played.Hash())
+	}
+
+	legalMoves := chessGame.GetLegalMoves()
+	if len(legalMoves) == 0 {
+		if chessGame.IsCheck() {
+			return Result{Score: chess_uci.UciScore{Mate: true}}
+		}
+		return Result{}
+	}
+	s.rootMoves = rootMoves(legalMoves, limits.SearchMoves)
+
+	result := Result{}
+	for depth := 1; depth <= searchLimits.depth; depth++ {
+		score := s.negamax(position, depth, 0, -infinity, infinity)
+		if s.stopped {
+			break
+		}
+		s.canStop = true
+
+		result = Result{Score: uciScore(score), Depth: depth, Nodes: s.nodes}
+		for _, move := range s.pv[0][:s.pvLength[0]] {
+			result.PV = append(result.PV, move.uciString(chess960))
+		}
+		result.Move = result.PV[0]
+		if info != nil {
+			info(s.searchInfo(result, start))
+		}
+
+		if searchLimits.mate > 0 && score > mateBound && result.Score.Value <= searchLimits.mate {
+			break
+		}
+		// a single legal move is played without thinking unless the search is pondering or infinite
+		if len(legalMoves) == 1 && searchLimits.maximum > 0 && !limits.Ponder {
+			break
+		}
+		if clock.pastOptimum(time.Now()) || (searchLimits.nodes > 0 && s.nodes >= searchLimits.nodes) || ctx.Err() != nil {
+			break
+		}
+	}
+	return result
+}
+
+// rootMoves returns the legal moves allowed by searchmoves, or nil to search every move when there is no
+// restriction or none of the moves are legal. Castling may be given in either notation.
+func rootMoves(legalMoves []game.ChessMove, searchMoves []string) map[moveKey]bool {
+	allowed := map[moveKey]bool{}
+	for _, uci := range searchMoves {
+		for _, move := range legalMoves {
+			for _, promotion := range append([]game.PieceType{game.NoPiece}, game.PromotionPieces...) {
+				if move.IsPromotion != (promotion != game.NoPiece) {
+					continue
+				}
+				if move.UciString(promotion) == uci || move.Chess960UciString(promotion) == uci {
+					allowed[newMoveKey(move, promotion)] = true
+				}
+			}
+		}
+	}
+	if len(allowed) == 0 {
+		return nil
+	}
+	return allowed
+}
+
+// uciScore converts a search score into centipawns or moves to mate.
+func uciScore(score int) chess_uci.UciScore {
+	switch {
+	case score > mateBound:
+		return chess_uci.UciScore{Mate: true, Value: (mateScore - score + 1) / 2}
+	case score < -mateBound:
+		return chess_uci.UciScore{Mate: true, Value: -(mateScore + score) / 2}
+	}
+	return chess_uci.UciScore{Value: score}
+}
+
+func (s *searcher) searchInfo(result Result, start time.Time) chess_uci.SearchInfo {
+	elapsed := time.Since(start)
+	depth, selDepth, nodes, hashFull := result.Depth, s.selDepth, s.nodes, s.table.hashFull()
+	milliseconds := int(elapsed.Milliseconds())
+	nps := int(int64(nodes) * int64(time.Second) / max(int64(elapsed), 1))
+	score := result.Score
+	return chess_uci.SearchInfo{
+		Depth:    &depth,
+		SelDepth: &selDepth,
+		Score:    &score,
+		Nodes:    &nodes,
+		Nps:      &nps,
+		HashFull: &hashFull,
+		Time:     &milliseconds,
+		PV:       result.PV,
+	}
+}
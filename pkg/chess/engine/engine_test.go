@@ -0,0 +1,260 @@
+package engine
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jerhon/chess/pkg/chess"
+	"github.com/jerhon/chess/pkg/chess/fen"
+	"github.com/jerhon/chess/pkg/chess/game"
+	"github.com/jerhon/chess/pkg/chess_uci"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func gameFromFen(t *testing.T, fenString string) *chess.ChessGame {
+	t.Helper()
+	position, err := fen.ParseFen(fenString)
+	require.NoError(t, err)
+	return chess.NewGameFromPosition(&position)
+}
+
+func intPtr(n int) *int { return &n }
+
+func TestSearchGame_FindsBestMove(t *testing.T) {
+	tests := []struct {
+		name  string
+		fen   string
+		depth int
+		moves []string
+		score chess_uci.UciScore
+	}{
+		{"back rank mate", "6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1", 2, []string{"a1a8"}, chess_uci.UciScore{Mate: true, Value: 1}},
+		{"rook ladder mate in two", "7k/8/8/8/8/8/R7/1R4K1 w - - 0 1", 4, []string{"a2a7", "b1b7"}, chess_uci.UciScore{Mate: true, Value: 2}},
+		{"black mates", "r5k1/8/8/8/8/8/5PPP/6K1 b - - 0 1", 2, []string{"a8a1"}, chess_uci.UciScore{Mate: true, Value: 1}},
+		{"takes a hanging queen", "4k3/8/8/3q4/8/8/3R4/4K3 w - - 0 1", 3, []string{"d2d5"}, chess_uci.UciScore{}},
+		{"promotes", "8/P6k/8/8/8/8/8/K7 w - - 0 1", 3, []string{"a7a8q"}, chess_uci.UciScore{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := NewEngine().SearchGame(context.Background(), gameFromFen(t, tt.fen), chess_uci.CmdGo{Depth: intPtr(tt.depth)}, nil)
+
+			assert.Contains(t, tt.moves, result.Move)
+			assert.Equal(t, result.Move, result.PV[0])
+			assert.Equal(t, tt.depth, result.Depth)
+			if tt.score.Mate {
+				assert.Equal(t, tt.score, result.Score)
+			} else {
+				assert.Greater(t, result.Score.Value, 300)
+			}
+		})
+	}
+}
+
+func TestSearchGame_NoLegalMoves(t *testing.T) {
+	checkmate := NewEngine().SearchGame(context.Background(), gameFromFen(t, "R5k1/5ppp/8/8/8/8/8/6K1 b - - 0 1"), chess_uci.CmdGo{Depth: intPtr(3)}, nil)
+	assert.Equal(t, Result{Score: chess_uci.UciScore{Mate: true}}, checkmate)
+	assert.Equal(t, chess_uci.BestMove{}, checkmate.BestMove())
+
+	stalemate := NewEngine().SearchGame(context.Background(), gameFromFen(t, "7k/5Q2/6K1/8/8/8/8/8 b - - 0 1"), chess_uci.CmdGo{Depth: intPtr(3)}, nil)
+	assert.Equal(t, Result{}, stalemate)
+}
+
+func TestSearchGame_ReportsEachIteration(t *testing.T) {
+	chessGame := chess.NewGame()
+	infos := []chess_uci.SearchInfo{}
+	result := NewEngine().SearchGame(context.Background(), chessGame, chess_uci.CmdGo{Depth: intPtr(4)}, func(info chess_uci.SearchInfo) {
+		infos = append(infos, info)
+	})
+
+	require.Len(t, infos, 4)
+	for i, info := range infos {
+		assert.Equal(t, i+1, *info.Depth)
+		assert.NotEmpty(t, info.PV)
+		assert.NotNil(t, info.Nodes)
+		assert.NotNil(t, info.Nps)
+		assert.NotNil(t, info.Time)
+	}
+	assert.Equal(t, infos[3].PV, result.PV)
+	assert.Len(t, result.PV, 4)
+
+	// the principal variation is a sequence of legal moves
+	for _, move := range result.PV {
+		_, err := chessGame.TryUciMove(move)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, result.PV[1], result.BestMove().Ponder)
+}
+
+func TestSearchGame_NodeLimit(t *testing.T) {
+	result := NewEngine().SearchGame(context.Background(), chess.NewGame(), chess_uci.CmdGo{Nodes: intPtr(2000)}, nil)
+
+	assert.NotEmpty(t, result.Move)
+	assert.LessOrEqual(t, result.Nodes, 2000)
+}
+
+func TestSearchGame_MoveTime(t *testing.T) {
+	start := time.Now()
+	result := NewEngine().SearchGame(context.Background(), chess.NewGame(), chess_uci.CmdGo{MoveTime: intPtr(100)}, nil)
+
+	assert.NotEmpty(t, result.Move)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestSearchGame_ClockTime(t *testing.T) {
+	// a second for the whole game leaves about 30ms for this move
+	start := time.Now()
+	result := NewEngine().SearchGame(context.Background(), chess.NewGame(), chess_uci.CmdGo{WTime: intPtr(1000), BTime: intPtr(1000)}, nil)
+
+	assert.NotEmpty(t, result.Move)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+}
+
+func TestSearchGame_InfiniteStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	start := time.Now()
+	result := NewEngine().SearchGame(ctx, chess.NewGame(), chess_uci.CmdGo{Infinite: true}, nil)
+
+	assert.NotEmpty(t, result.Move)
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
+
+func TestSearchGame_SearchMoves(t *testing.T) {
+	chessGame := gameFromFen(t, "4k3/8/8/3q4/8/8/3R4/4K3 w - - 0 1")
+	result := NewEngine().SearchGame(context.Background(), chessGame, chess_uci.CmdGo{Depth: intPtr(3), SearchMoves: []string{"e1f1", "d2d3"}}, nil)
+
+	assert.Contains(t, []string{"e1f1", "d2d3"}, result.Move)
+}
+
+func TestSearchGame_PlaysSingleMoveImmediately(t *testing.T) {
+	chessGame := gameFromFen(t, "7k/8/8/8/8/8/5r2/7K w - - 0 1")
+	result := NewEngine().SearchGame(context.Background(), chessGame, chess_uci.CmdGo{WTime: intPtr(600000), BTime: intPtr(600000)}, nil)
+
+	assert.Equal(t, "h1g1", result.Move)
+	assert.Equal(t, 1, result.Depth)
+}
+
+func TestSearchGame_PonderWaitsForPonderHit(t *testing.T) {
+	engine := NewEngine()
+	done := make(chan Result, 1)
+	go func() {
+		done <- engine.SearchGame(context.Background(), chess.NewGame(), chess_uci.CmdGo{Ponder: true, MoveTime: intPtr(50)}, nil)
+	}()
+
+	select {
+	case <-done:
+		t.Fatalf("ponder search finished before ponderhit")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	engine.PonderHit()
+	select {
+	case result := <-done:
+		assert.NotEmpty(t, result.Move)
+	case <-time.After(2 * time.Second):
+		t.Fatalf("search did not stop after ponderhit")
+	}
+}
+
+func TestSearchGame_ScoresRepetitionAsDraw(t *testing.T) {
+	// white is a queen down and can only hold the game by repeating the position
+	chessGame := gameFromFen(t, "k7/8/8/4q3/8/8/8/7K w - - 0 1")
+	for _, move := range []string{"h1g1", "a8b8", "g1h1", "b8a8", "h1g1", "a8b8"} {
+		_, err := chessGame.TryUciMove(move)
+		require.NoError(t, err)
+	}
+	result := NewEngine().SearchGame(context.Background(), chessGame, chess_uci.CmdGo{Depth: intPtr(4)}, nil)
+
+	assert.Equal(t, "g1h1", result.Move)
+	assert.Equal(t, chess_uci.UciScore{}, result.Score)
+}
+
+func TestSearchGame_MatesOnTheFiftiethMove(t *testing.T) {
+	// the mate reaches the fifty-move rule, which doesn't take it away
+	result := NewEngine().SearchGame(context.Background(), gameFromFen(t, "6k1/5ppp/8/8/8/8/8/R5K1 w - - 99 80"), chess_uci.CmdGo{Depth: intPtr(2)}, nil)
+
+	assert.Equal(t, "a1a8", result.Move)
+	assert.Equal(t, chess_uci.UciScore{Mate: true, Value: 1}, result.Score)
+}
+
+func TestEngine_SetOption(t *testing.T) {
+	engine := NewEngine()
+
+	require.NoError(t, engine.SetOption("Hash", strPtr("1")))
+	assert.Equal(t, 1024*1024/entrySize, len(engine.table.entries))
+	require.NoError(t, engine.SetOption("Clear Hash", nil))
+	assert.Equal(t, 1024*1024/entrySize, len(engine.table.entries))
+
+	assert.Error(t, engine.SetOption("Hash", strPtr("0")))
+	assert.Error(t, engine.SetOption("Hash", nil))
+	assert.Error(t, engine.SetOption("Threads", strPtr("4")))
+}
+
+func strPtr(s string) *string { return &s }
+
+func TestEngine_Chess960CastlingNotation(t *testing.T) {
+	limits := chess_uci.CmdGo{Depth: intPtr(1), SearchMoves: []string{"e1h1"}}
+	engine := NewEngine()
+
+	result := engine.SearchGame(context.Background(), gameFromFen(t, "4k3/8/8/8/8/8/8/4K2R w K - 0 1"), limits, nil)
+	assert.Equal(t, "e1g1", result.Move)
+
+	require.NoError(t, engine.SetOption("UCI_Chess960", strPtr("true")))
+	result = engine.SearchGame(context.Background(), gameFromFen(t, "4k3/8/8/8/8/8/8/4K2R w K - 0 1"), limits, nil)
+	assert.Equal(t, "e1h1", result.Move)
+
+	assert.Error(t, engine.SetOption("UCI_Chess960", strPtr("yes")))
+}
+
+func TestMoveKey_CastlingDiffersFromKingMove(t *testing.T) {
+	position, err := fen.ParseFen("4k3/8/8/8/8/8/8/RK6 w A - 0 1")
+	require.NoError(t, err)
+	movement := game.NewChessMovement(&position)
+	movement.Calculate()
+
+	keys := map[moveKey]string{}
+	for _, move := range movement.Moves {
+		if move.To.String() == "c1" {
+			keys[newMoveKey(move, game.NoPiece)] = move.Chess960UciString(game.NoPiece)
+		}
+	}
+	require.Len(t, keys, 2)
+	for key, uci := range keys {
+		assert.Equal(t, "b1c1", key.String())
+		assert.Equal(t, uci, key.uciString(true))
+	}
+}
+
+func TestEngine_ServesUci(t *testing.T) {
+	commandReader, commandWriter := io.Pipe()
+	responseReader, responseWriter := io.Pipe()
+	serverDone := make(chan error, 1)
+	go func() {
+		serverDone <- chess_uci.NewServer(NewEngine(), commandReader, responseWriter).Run()
+		responseWriter.Close()
+	}()
+
+	go io.WriteString(commandWriter, "uci\nposition startpos moves e2e4\ngo depth 3\n")
+	lines := []string{}
+	scanner := bufio.NewScanner(responseReader)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+		if strings.HasPrefix(scanner.Text(), "bestmove ") {
+			break
+		}
+	}
+	commandWriter.Close()
+	go io.Copy(io.Discard, responseReader)
+	require.NoError(t, <-serverDone)
+
+	assert.Contains(t, lines, "uciok")
+	require.GreaterOrEqual(t, len(lines), 2)
+	assert.True(t, strings.HasPrefix(lines[len(lines)-2], "info depth 3 "), lines)
+	assert.True(t, strings.HasPrefix(lines[len(lines)-1], "bestmove "), lines)
+}
@@ -0,0 +1,411 @@
+package engine
+
+import (
+	"context"
+	"time"
+
+	"github.com/jerhon/chess/pkg/chess/eval"
+	"github.com/jerhon/chess/pkg/chess/game"
+)
+
+const (
+	// maxPly bounds the length of a line searched, including check extensions and the quiescence search
+	maxPly = 128
+	// mateScore is the score of giving mate at the root, a mate n plies away scores mateScore - n
+	mateScore = 100000
+	// mateBound is the lowest score that is a mate
+	mateBound = mateScore - maxPly
+	infinity  = mateScore + 1
+	// stopCheckInterval is how many nodes are searched between checks of the clock and the context
+	stopCheckInterval = 2048
+)
+
+// moveKey packs a move into 16 bits: the from square, the to square and the promotion piece. Castling is the
+// king taking its own rook with the top bit set, as in Chess960 the king's own move can be the same as a
+// normal king move.
+type moveKey uint16
+
+const (
+	noMove     moveKey = 0
+	castleFlag moveKey = 1 << 15
+)
+
+func newMoveKey(move game.ChessMove, promotion game.PieceType) moveKey {
+	if move.IsCastle {
+		return moveKey(move.From.Location.ToIndex()) | moveKey(move.CastlingRook.ToIndex())<<6 | castleFlag
+	}
+	key := moveKey(move.From.Location.ToIndex()) | moveKey(move.To.ToIndex())<<6
+	if move.IsPromotion {
+		for i, piece := range game.PromotionPieces {
+			if piece == promotion {
+				key |= moveKey(i+1) << 12
+			}
+		}
+	}
+	return key
+}
+
+// String returns the move in UCI notation.
+func (k moveKey) String() string {
+	return k.uciString(false)
+}
+
+// uciString returns the move in UCI notation, writing castling as the king taking its rook when chess960 is set.
+func (k moveKey) uciString(chess960 bool) string {
+	from, to := game.LocationFromIndex(int(k&63)), game.LocationFromIndex(int(k>>6&63))
+	if k&castleFlag != 0 && !chess960 {
+		if to.File > from.File {
+			to.File = game.FileG
+		} else {
+			to.File = game.FileC
+		}
+	}
+	uci := from.String() + to.String()
+	if promotion := int(k >> 12 & 7); promotion > 0 {
+		uci += string(rune(game.PromotionPieces[promotion-1]) + 'a' - 'A')
+	}
+	return uci
+}
+
+// pieceTypes orders the piece types for the piece tables.
+var pieceTypes = [6]game.PieceType{game.Pawn, game.Knight, game.Bishop, game.Rook, game.Queen, game.King}
+
+// colorIndex returns 0 for white and 1 for black.
+func colorIndex(color game.ColorType) int {
+	if color == game.BlackPiece {
+		return 1
+	}
+	return 0
+}
+
+// pieceValues rank the pieces for move ordering, in pieceTypes order.
+var pieceValues = [6]int{100, 320, 330, 500, 900, 0}
+
+// pieceIndex returns the index of a piece type in pieceTypes, or -1 for an empty square.
+func pieceIndex(piece game.PieceType) int {
+	for i, pieceType := range pieceTypes {
+		if pieceType == piece {
+			return i
+		}
+	}
+	return -1
+}
+
+// evaluate returns the static score of a position in centipawns from the point of view of the side to move.
+func evaluate(position *game.ChessPosition) int {
+	return eval.Evaluate(position).Relative(position.PlayerToMove)
+}
+
+// candidate is a move being ordered for the search.
+type candidate struct {
+	move      game.ChessMove
+	promotion game.PieceType
+	key       moveKey
+	capture   bool
+	order     int
+}
+
+// Move ordering scores, searched highest first: the transposition table move, then captures and queen
+// promotions by most valuable victim and least valuable attacker, then the killer moves, then the remaining
+// quiet moves by their history score.
+const (
+	orderTableMove  = 1 << 30
+	orderCapture    = 1 << 20
+	orderKiller     = 1 << 19
+	maxHistoryScore = orderKiller / 2
+)
+
+// searcher holds the state of a single search.
+type searcher struct {
+	ctx    context.Context
+	table  *transpositionTable
+	clock  *searchClock
+	limits searchLimits
+
+	nodes    int
+	selDepth int
+	// canStop is false while the first iteration is searched, so a search always returns a move
+	canStop bool
+	stopped bool
+
+	// path holds the hashes of the game's positions followed by the positions of the line being searched
+	path []uint64
+	// rootMoves restricts the moves searched at the root when it is not nil
+	rootMoves map[moveKey]bool
+
+	killers [maxPly][2]moveKey
+	history [2][64][64]int
+	// pv is the triangular principal variation table, pv[ply] holds the line from ply
+	pv       [maxPly][maxPly]moveKey
+	pvLength [maxPly]int
+}
+
+// shouldStop checks the node limit on every node and the clock and context every stopCheckInterval nodes.
+func (s *searcher) shouldStop() bool {
+	if s.stopped {
+		return true
+	}
+	if !s.canStop {
+		return false
+	}
+	if s.limits.nodes > 0 && s.nodes >= s.limits.nodes {
+		s.stopped = true
+	} else if s.nodes%stopCheckInterval == 0 {
+		s.stopped = s.ctx.Err() != nil || s.clock.pastMaximum(time.Now())
+	}
+	return s.stopped
+}
+
+// isRepetition reports whether the position at the end of the path occurred earlier, looking back no further
+// than the last capture or pawn move.
+func (s *searcher) isRepetition(halfmoveClock int) bool {
+	last := len(s.path) - 1
+	for i := last - 4; i >= 0 && i >= last-halfmoveClock; i -= 2 {
+		if s.path[i] == s.path[last] {
+			return true
+		}
+	}
+	return false
+}
+
+// negamax searches a position to depth with alpha-beta pruning and returns its score from the point of view
+// of the side to move. The position's hash is the last entry of the path.
+func (s *searcher) negamax(position *game.ChessPosition, depth int, ply int, alpha int, beta int) int {
+	s.pvLength[ply] = ply
+	if s.shouldStop() {
+		return 0
+	}
+	s.nodes++
+	s.selDepth = max(s.selDepth, ply)
+	hash := s.path[len(s.path)-1]
+
+	if ply > 0 && s.isRepetition(position.HalfmoveClock) {
+		return 0
+	}
+
+	movement := game.NewChessMovement(position)
+	movement.Calculate()
+	inCheck := movement.Check[position.PlayerToMove]
+	if len(movement.Moves) == 0 {
+		if inCheck {
+			return -mateScore + ply
+		}
+		return 0
+	}
+	// a mate on the move that reaches the fifty-move rule stands, so the rule is checked after it
+	if ply > 0 && (position.HalfmoveClock >= 100 || movement.Result.IsDraw()) {
+		return 0
+	}
+	if ply >= maxPly-1 {
+		return evaluate(position)
+	}
+
+	if inCheck {
+		depth++
+	}
+	if depth <= 0 {
+		return s.quiescence(position, movement, ply, alpha, beta)
+	}
+
+	tableMove := noMove
+	if entry, found := s.table.probe(hash); found {
+		tableMove = entry.move
+		score := scoreFromTable(int(entry.score), ply)
+		if ply > 0 && int(entry.depth) >= depth {
+			switch {
+			case entry.bound == boundExact,
+				entry.bound == boundLower && score >= beta,
+				entry.bound == boundUpper && score <= alpha:
+				return score
+			}
+		}
+	}
+
+	candidates := s.orderMoves(position, movement.Moves, tableMove, ply, false)
+	originalAlpha := alpha
+	bestScore, bestMove := -infinity, noMove
+	for i := range candidates {
+		c := nextCandidate(candidates, i)
+		if ply == 0 && s.rootMoves != nil && !s.rootMoves[c.key] {
+			continue
+		}
+
+		child := position.ApplyMove(c.move, c.promotion)
+		s.path = append(s.path, child.Hash())
+		score := -s.negamax(child, depth-1, ply+1, -beta, -alpha)
+		s.path = s.path[:len(s.path)-1]
+		if s.stopped {
+			return 0
+		}
+
+		if score > bestScore {
+			bestScore, bestMove = score, c.key
+		}
+		if score > alpha {
+			alpha = score
+			s.updatePV(ply, c.key)
+		}
+		if alpha >= beta {
+			if !c.capture && c.promotion == game.NoPiece {
+				s.rememberQuietCutoff(position.PlayerToMove, c.key, depth, ply)
+			}
+			break
+		}
+	}
+
+	bound := boundExact
+	if bestScore <= originalAlpha {
+		bound = boundUpper
+	} else if bestScore >= beta {
+		bound = boundLower
+	}
+	s.table.store(hash, depth, scoreToTable(bestScore, ply), bound, bestMove)
+	return bestScore
+}
+
+// quiescence searches captures and queen promotions until the position is quiet, so the static evaluation is
+// not taken in the middle of an exchange. In check every move is searched.
+func (s *searcher) quiescence(position *game.ChessPosition, movement *game.ChessMovement, ply int, alpha int, beta int) int {
+	s.pvLength[ply] = ply
+	if ply >= maxPly-1 {
+		return evaluate(position)
+	}
+	inCheck := movement.Check[position.PlayerToMove]
+	if !inCheck {
+		standPat := evaluate(position)
+		if standPat >= beta {
+			return standPat
+		}
+		alpha = max(alpha, standPat)
+	}
+
+	candidates := s.orderMoves(position, movement.Moves, noMove, ply, !inCheck)
+	for i := range candidates {
+		c := nextCandidate(candidates, i)
+
+		if s.shouldStop() {
+			return 0
+		}
+		s.nodes++
+		s.selDepth = max(s.selDepth, ply+1)
+
+		child := position.ApplyMove(c.move, c.promotion)
+		childMovement := game.NewChessMovement(child)
+		childMovement.Calculate()
+
+		var score int
+		switch {
+		case len(childMovement.Moves) == 0 && childMovement.Check[child.PlayerToMove]:
+			score = mateScore - ply - 1
+			s.pvLength[ply+1] = ply + 1
+		case len(childMovement.Moves) == 0 || childMovement.Result.IsDraw():
+			score = 0
+			s.pvLength[ply+1] = ply + 1
+		default:
+			score = -s.quiescence(child, childMovement, ply+1, -beta, -alpha)
+		}
+		if s.stopped {
+			return 0
+		}
+
+		if score > alpha {
+			alpha = score
+			s.updatePV(ply, c.key)
+		}
+		if alpha >= beta {
+			break
+		}
+	}
+	return alpha
+}
+
+// updatePV makes move followed by the line below it the principal variation from ply.
+func (s *searcher) updatePV(ply int, move moveKey) {
+	s.pv[ply][ply] = move
+	next := s.pvLength[ply+1]
+	copy(s.pv[ply][ply+1:next], s.pv[ply+1][ply+1:next])
+	s.pvLength[ply] = max(next, ply+1)
+}
+
+// rememberQuietCutoff records a quiet move that caused a beta cutoff as a killer for its ply and in the
+// history table.
+func (s *searcher) rememberQuietCutoff(color game.ColorType, move moveKey, depth int, ply int) {
+	if s.killers[ply][0] != move {
+		s.killers[ply][1] = s.killers[ply][0]
+		s.killers[ply][0] = move
+	}
+
+	history := &s.history[colorIndex(color)]
+	from, to := int(move&63), int(move>>6&63)
+	history[from][to] += depth * depth
+	if history[from][to] > maxHistoryScore {
+		// age every score so recent cutoffs keep their weight
+		for from := range history {
+			for to := range history[from] {
+				history[from][to] /= 2
+			}
+		}
+	}
+}
+
+// orderMoves expands the legal moves into candidates with an ordering score. Promotions become one
+// candidate per promotion piece. When tactical is set only captures and queen promotions are kept.
+func (s *searcher) orderMoves(position *game.ChessPosition, moves []game.ChessMove, tableMove moveKey, ply int, tactical bool) []candidate {
+	candidates := make([]candidate, 0, len(moves)+8)
+	for _, move := range moves {
+		attacker := pieceIndex(move.From.Piece.Piece)
+		victim := pieceIndex(position.Board.GetSquare(move.To).Piece.Piece)
+		if victim < 0 && move.From.Piece.Piece == game.Pawn && move.To == position.EnPassantSquare {
+			victim = pieceIndex(game.Pawn)
+		}
+		capture := victim >= 0
+
+		promotions := []game.PieceType{game.NoPiece}
+		if move.IsPromotion {
+			promotions = game.PromotionPieces
+		}
+		for _, promotion := range promotions {
+			if tactical && (promotion == game.NoPiece && !capture || promotion != game.NoPiece && promotion != game.Queen) {
+				continue
+			}
+
+			c := candidate{move: move, promotion: promotion, key: newMoveKey(move, promotion), capture: capture}
+			switch {
+			case c.key == tableMove:
+				c.order = orderTableMove
+			case promotion != game.NoPiece && promotion != game.Queen:
+				// underpromotions are rarely best
+				c.order = -orderCapture
+			case capture || promotion == game.Queen:
+				c.order = orderCapture - attacker
+				if capture {
+					c.order += pieceValues[victim] * 8
+				}
+				if promotion == game.Queen {
+					c.order += pieceValues[pieceIndex(game.Queen)]
+				}
+			case c.key == s.killers[ply][0]:
+				c.order = orderKiller
+			case c.key == s.killers[ply][1]:
+				c.order = orderKiller - 1
+			default:
+				c.order = s.history[colorIndex(position.PlayerToMove)][c.key&63][c.key>>6&63]
+			}
+			candidates = append(candidates, c)
+		}
+	}
+	return candidates
+}
+
+// nextCandidate moves the best ordered of the candidates from i on to i and returns it. Picking lazily avoids
+// sorting moves that are never searched after a cutoff.
+func nextCandidate(candidates []candidate, i int) candidate {
+	best := i
+	for j := i + 1; j < len(candidates); j++ {
+		if candidates[j].order > candidates[best].order {
+			best = j
+		}
+	}
+	candidates[i], candidates[best] = candidates[best], candidates[i]
+	return candidates[i]
+}
@@ -0,0 +1,115 @@
+package engine
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/jerhon/chess/pkg/chess/game"
+	"github.com/jerhon/chess/pkg/chess_uci"
+)
+
+const (
+	// maxDepth is the deepest iteration searched when the go command has no depth
+	maxDepth = 64
+	// defaultMovesToGo is the number of moves the remaining time is shared between when the go command has
+	// no movestogo
+	defaultMovesToGo = 30
+	// moveOverhead is kept back from every time limit for the move to reach the GUI
+	moveOverhead = 20 * time.Millisecond
+)
+
+// searchLimits are the limits of a search taken from a go command.
+type searchLimits struct {
+	depth int
+	// nodes is the node limit, zero when there is none
+	nodes int
+	// mate is the number of moves to find a mate in, zero when not searching for a mate
+	mate int
+	// optimum is the time after which no new iteration is started and maximum the time at which the search
+	// is stopped. Both are zero when the search is not timed.
+	optimum time.Duration
+	maximum time.Duration
+}
+
+// newSearchLimits derives the limits of a search for the side to move. A movetime is used as it is, otherwise
+// the side's remaining time is shared between the moves to go with most of the increment added. An infinite
+// search has neither a time nor a depth limit.
+func newSearchLimits(limits chess_uci.CmdGo, side game.ColorType) searchLimits {
+	result := searchLimits{depth: maxDepth}
+	if limits.Depth != nil && *limits.Depth > 0 {
+		result.depth = min(*limits.Depth, maxDepth)
+	}
+	if limits.Nodes != nil && *limits.Nodes > 0 {
+		result.nodes = *limits.Nodes
+	}
+	if limits.Mate != nil && *limits.Mate > 0 {
+		result.mate = *limits.Mate
+		result.depth = min(result.depth, 2*result.mate-1)
+	}
+	if limits.Infinite {
+		return result
+	}
+
+	if limits.MoveTime != nil {
+		result.maximum = max(time.Duration(*limits.MoveTime)*time.Millisecond-moveOverhead, time.Millisecond)
+		result.optimum = result.maximum
+		return result
+	}
+
+	remaining, increment := limits.WTime, limits.WInc
+	if side == game.BlackPiece {
+		remaining, increment = limits.BTime, limits.BInc
+	}
+	if remaining == nil {
+		return result
+	}
+	movesToGo := defaultMovesToGo
+	if limits.MovesToGo != nil && *limits.MovesToGo > 0 {
+		movesToGo = min(*limits.MovesToGo, defaultMovesToGo)
+	}
+	inc := time.Duration(0)
+	if increment != nil {
+		inc = time.Duration(*increment) * time.Millisecond
+	}
+
+	// never plan to use more than three quarters of the clock on one move
+	available := max(time.Duration(*remaining)*time.Millisecond-moveOverhead, time.Millisecond)
+	limit := available * 3 / 4
+	result.optimum = max(min(available/time.Duration(movesToGo)+inc*3/4, limit), time.Millisecond)
+	result.maximum = min(result.optimum*3, limit)
+	return result
+}
+
+// searchClock times a search. A pondering search runs untimed until start is called on ponderhit.
+type searchClock struct {
+	optimum time.Duration
+	maximum time.Duration
+	// optimumAt and maximumAt are the deadlines in Unix nanoseconds, zero until the clock is started
+	optimumAt atomic.Int64
+	maximumAt atomic.Int64
+}
+
+func newSearchClock(limits searchLimits) *searchClock {
+	return &searchClock{optimum: limits.optimum, maximum: limits.maximum}
+}
+
+// start sets the deadlines from now. It does nothing for an untimed search.
+func (c *searchClock) start(now time.Time) {
+	if c.maximum == 0 {
+		return
+	}
+	c.optimumAt.Store(now.Add(c.optimum).UnixNano())
+	c.maximumAt.Store(now.Add(c.maximum).UnixNano())
+}
+
+// pastOptimum reports whether there is no time to start another iteration.
+func (c *searchClock) pastOptimum(now time.Time) bool {
+	at := c.optimumAt.Load()
+	return at != 0 && now.UnixNano() >= at
+}
+
+// pastMaximum reports whether the search must stop.
+func (c *searchClock) pastMaximum(now time.Time) bool {
+	at := c.maximumAt.Load()
+	return at != 0 && now.UnixNano() >= at
+}
@@ -0,0 +1,61 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jerhon/chess/pkg/chess/game"
+	"github.com/jerhon/chess/pkg/chess_uci"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSearchLimits(t *testing.T) {
+	tests := []struct {
+		name    string
+		command chess_uci.CmdGo
+		side    game.ColorType
+		limit   searchLimits
+	}{
+		{"no limits", chess_uci.CmdGo{}, game.WhitePiece, searchLimits{depth: maxDepth}},
+		{"depth and nodes", chess_uci.CmdGo{Depth: intPtr(5), Nodes: intPtr(1000)}, game.WhitePiece, searchLimits{depth: 5, nodes: 1000}},
+		{"mate", chess_uci.CmdGo{Mate: intPtr(3)}, game.WhitePiece, searchLimits{depth: 5, mate: 3}},
+		{"movetime", chess_uci.CmdGo{MoveTime: intPtr(1000)}, game.WhitePiece, searchLimits{depth: maxDepth, optimum: 980 * time.Millisecond, maximum: 980 * time.Millisecond}},
+		{
+			"white clock with increment",
+			chess_uci.CmdGo{WTime: intPtr(60020), BTime: intPtr(1000), WInc: intPtr(1000)},
+			game.WhitePiece,
+			searchLimits{depth: maxDepth, optimum: 2750 * time.Millisecond, maximum: 8250 * time.Millisecond},
+		},
+		{
+			"black clock with moves to go",
+			chess_uci.CmdGo{WTime: intPtr(60020), BTime: intPtr(10020), MovesToGo: intPtr(10)},
+			game.BlackPiece,
+			searchLimits{depth: maxDepth, optimum: 1000 * time.Millisecond, maximum: 3000 * time.Millisecond},
+		},
+		{
+			"last move before the time control keeps a reserve",
+			chess_uci.CmdGo{WTime: intPtr(4020), MovesToGo: intPtr(1)},
+			game.WhitePiece,
+			searchLimits{depth: maxDepth, optimum: 3000 * time.Millisecond, maximum: 3000 * time.Millisecond},
+		},
+		{"infinite ignores the clock", chess_uci.CmdGo{WTime: intPtr(60000), Infinite: true}, game.WhitePiece, searchLimits{depth: maxDepth}},
+		{"clock of the other side only", chess_uci.CmdGo{WTime: intPtr(60000)}, game.BlackPiece, searchLimits{depth: maxDepth}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.limit, newSearchLimits(tt.command, tt.side))
+		})
+	}
+}
+
+func TestSearchClock(t *testing.T) {
+	clock := newSearchClock(searchLimits{optimum: time.Second, maximum: 3 * time.Second})
+	now := time.Now()
+	assert.False(t, clock.pastMaximum(now.Add(time.Hour)), "the clock has not started")
+
+	clock.start(now)
+	assert.False(t, clock.pastOptimum(now))
+	assert.True(t, clock.pastOptimum(now.Add(time.Second)))
+	assert.False(t, clock.pastMaximum(now.Add(2*time.Second)))
+	assert.True(t, clock.pastMaximum(now.Add(3*time.Second)))
+}
@@ -0,0 +1,79 @@
+package engine
+
+// boundType records how a stored score relates to the true score of a position.
+type boundType uint8
+
+const (
+	boundExact boundType = iota + 1
+	// boundLower is stored when the search failed high, the true score is at least the stored score
+	boundLower
+	// boundUpper is stored when the search failed low, the true score is at most the stored score
+	boundUpper
+)
+
+// ttEntry is a transposition table slot, 16 bytes.
+type ttEntry struct {
+	hash  uint64
+	score int32
+	move  moveKey
+	depth int8
+	bound boundType
+}
+
+// transpositionTable remembers the results of earlier searches by position hash. Each hash maps to a single
+// slot which is always replaced.
+type transpositionTable struct {
+	entries []ttEntry
+	used    int
+}
+
+// entrySize is the size of a ttEntry in bytes.
+const entrySize = 16
+
+func newTranspositionTable(megabytes int) *transpositionTable {
+	return &transpositionTable{entries: make([]ttEntry, max(1, megabytes*1024*1024/entrySize))}
+}
+
+func (t *transpositionTable) probe(hash uint64) (ttEntry, bool) {
+	entry := t.entries[hash%uint64(len(t.entries))]
+	return entry, entry.bound != 0 && entry.hash == hash
+}
+
+func (t *transpositionTable) store(hash uint64, depth int, score int, bound boundType, move moveKey) {
+	entry := &t.entries[hash%uint64(len(t.entries))]
+	if entry.bound == 0 {
+		t.used++
+	} else if entry.hash == hash && move == noMove {
+		// keep the best move of an earlier search of the same position
+		move = entry.move
+	}
+	*entry = ttEntry{hash: hash, score: int32(score), move: move, depth: int8(min(depth, 127)), bound: bound}
+}
+
+// hashFull returns how full the table is in permille, as reported by UCI hashfull.
+func (t *transpositionTable) hashFull() int {
+	return t.used * 1000 / len(t.entries)
+}
+
+// scoreToTable converts a mate score relative to the root into one relative to the stored position, so it
+// stays correct when the position is reached at another ply.
+func scoreToTable(score int, ply int) int {
+	switch {
+	case score > mateBound:
+		return score + ply
+	case score < -mateBound:
+		return score - ply
+	}
+	return score
+}
+
+// scoreFromTable reverses scoreToTable.
+func scoreFromTable(score int, ply int) int {
+	switch {
+	case score > mateBound:
+		return score - ply
+	case score < -mateBound:
+		return score + ply
+	}
+	return score
+}
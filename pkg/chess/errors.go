@@ -0,0 +1,69 @@
+package chess
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jerhon/chess/pkg/chess/game"
+	"github.com/jerhon/chess/pkg/chess/san"
+)
+
+// ErrGameOver is returned for a move or an action once the game has ended.
+type ErrGameOver struct {
+	Result game.GameResult
+	Reason game.ResultReason
+}
+
+func (e *ErrGameOver) Error() string {
+	return fmt.Sprintf("game is over: %s", e.Result)
+}
+
+// ErrMalformedSAN is returned for a move that can't be read as SAN.
+type ErrMalformedSAN struct {
+	San string
+	// Position is where in San the problem was found
+	Position san.SanTokenPosition
+	Err      error
+}
+
+func (e *ErrMalformedSAN) Error() string {
+	return fmt.Sprintf("invalid SAN %s: %v", e.San, e.Err)
+}
+
+func (e *ErrMalformedSAN) Unwrap() error {
+	return e.Err
+}
+
+// ErrNoMatchingMove is returned for a move in SAN that no legal move of the position matches.
+type ErrNoMatchingMove struct {
+	San string
+}
+
+func (e *ErrNoMatchingMove) Error() string {
+	return fmt.Sprintf("illegal move %s, no legal move matches it", e.San)
+}
+
+// ErrAmbiguousMove is returned for a move in SAN that more than one legal move matches.
+type ErrAmbiguousMove struct {
+	San string
+	// Candidates are the moves that match in SAN, e.g. Nbd2 and Nfd2 for Nd2
+	Candidates []string
+}
+
+func (e *ErrAmbiguousMove) Error() string {
+	return fmt.Sprintf("ambiguous move %s, it could be %s", e.San, strings.Join(e.Candidates, " or "))
+}
+
+// ErrIllegalCastle is returned for a castle the player to move can't make.
+type ErrIllegalCastle struct {
+	KingSide bool
+	Reason   game.CastleFailure
+}
+
+func (e *ErrIllegalCastle) Error() string {
+	side := "queen side"
+	if e.KingSide {
+		side = "king side"
+	}
+	return fmt.Sprintf("cannot castle %s, %s", side, e.Reason)
+}
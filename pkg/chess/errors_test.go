@@ -0,0 +1,106 @@
+package chess
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jerhon/chess/pkg/chess/game"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrySanMove_ErrGameOver(t *testing.T) {
+	g := NewGame()
+	playMoves(t, g, "f3", "e5", "g4", "Qh4#")
+
+	_, err := g.TrySanMove("e4")
+	var gameOver *ErrGameOver
+	require.True(t, errors.As(err, &gameOver))
+	assert.Equal(t, game.BlackWins, gameOver.Result)
+	assert.Equal(t, game.ReasonCheckmate, gameOver.Reason)
+}
+
+func TestTrySanMove_ErrMalformedSAN(t *testing.T) {
+	tests := []struct {
+		san    string
+		offset int
+	}{
+		{"e4?", 2},
+		{"Nz3", 1},
+		{"N", 1},
+		{"", 0},
+		{"Nf3x", 4},
+		{"exd6 e.x.", 7},
+	}
+	for _, test := range tests {
+		t.Run(test.san, func(t *testing.T) {
+			g := NewGame()
+			_, err := g.TrySanMove(test.san)
+			var malformed *ErrMalformedSAN
+			require.True(t, errors.As(err, &malformed), "%v", err)
+			assert.Equal(t, test.san, malformed.San)
+			assert.Equal(t, test.offset, malformed.Position.Offset)
+		})
+	}
+}
+
+func TestTrySanMove_ErrNoMatchingMove(t *testing.T) {
+	for _, san := range []string{"e5", "Nd4", "Ke2", "exd5"} {
+		t.Run(san, func(t *testing.T) {
+			g := NewGame()
+			_, err := g.TrySanMove(san)
+			var noMatch *ErrNoMatchingMove
+			require.True(t, errors.As(err, &noMatch), "%v", err)
+			assert.Equal(t, san, noMatch.San)
+			assert.ErrorContains(t, err, "no legal move matches")
+		})
+	}
+
+	g := newGameFromFen(t, "8/4P3/8/8/8/8/k7/4K3 w - - 0 1")
+	_, err := g.TrySanMove("e8=K")
+	var noMatch *ErrNoMatchingMove
+	assert.True(t, errors.As(err, &noMatch), "%v", err)
+}
+
+func TestTrySanMove_ErrAmbiguousMove(t *testing.T) {
+	g := newGameFromFen(t, "4k3/8/8/8/8/8/8/1N2KN2 w - - 0 1")
+	_, err := g.TrySanMove("Nd2")
+	var ambiguous *ErrAmbiguousMove
+	require.True(t, errors.As(err, &ambiguous), "%v", err)
+	assert.ElementsMatch(t, []string{"Nbd2", "Nfd2"}, ambiguous.Candidates)
+	assert.ErrorContains(t, err, "Nd2")
+
+	_, err = g.TrySanMove("Nfd2")
+	assert.NoError(t, err)
+}
+
+func TestTrySanMove_ErrIllegalCastle(t *testing.T) {
+	tests := []struct {
+		name     string
+		fen      string
+		san      string
+		kingSide bool
+		reason   game.CastleFailure
+	}{
+		{"rights lost", "r3k2r/8/8/8/8/8/8/R3K2R w Qkq - 0 1", "O-O", true, game.CastleNoRights},
+		{"rook gone", "r3k2r/8/8/8/8/8/8/4K2R w KQkq - 0 1", "O-O-O", false, game.CastleNoRights},
+		{"in check", "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", "O-O", true, game.CastleAllowed},
+		{"path blocked", "r3k2r/8/8/8/8/8/8/RN2K2R w KQkq - 0 1", "O-O-O", false, game.CastlePathBlocked},
+		{"through check", "r3k2r/8/8/8/8/8/5r2/R3K2R w KQkq - 0 1", "O-O", true, game.CastleThroughCheck},
+		{"in check", "r3k2r/8/8/8/8/8/4r3/R3K2R w KQkq - 0 1", "O-O", true, game.CastleInCheck},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			g := newGameFromFen(t, test.fen)
+			_, err := g.TrySanMove(test.san)
+			if test.reason == game.CastleAllowed {
+				assert.NoError(t, err)
+				return
+			}
+			var illegal *ErrIllegalCastle
+			require.True(t, errors.As(err, &illegal), "%v", err)
+			assert.Equal(t, test.kingSide, illegal.KingSide)
+			assert.Equal(t, test.reason, illegal.Reason)
+		})
+	}
+}
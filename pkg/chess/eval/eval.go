@@ -0,0 +1,317 @@
+// Package eval scores chess positions statically. The score is made of named terms, each given for both
+// sides, so the reason one side is better can be shown as well as by how much.
+package eval
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jerhon/chess/pkg/chess/game"
+)
+
+// Term is one part of the evaluation.
+type Term int
+
+const (
+	// Material is the value of the pieces
+	Material Term = iota
+	// PieceSquares rewards pieces standing on good squares
+	PieceSquares
+	// PawnStructure penalizes doubled and isolated pawns and rewards passed pawns
+	PawnStructure
+	// KingSafety rewards a pawn shield and penalizes open files and attacks near the king
+	KingSafety
+	// Mobility rewards pieces with more squares to move to
+	Mobility
+	// BishopPair rewards keeping both bishops
+	BishopPair
+)
+
+// Terms lists every term in the order they are reported.
+var Terms = []Term{Material, PieceSquares, PawnStructure, KingSafety, Mobility, BishopPair}
+
+func (t Term) String() string {
+	switch t {
+	case Material:
+		return "Material"
+	case PieceSquares:
+		return "Piece squares"
+	case PawnStructure:
+		return "Pawn structure"
+	case KingSafety:
+		return "King safety"
+	case Mobility:
+		return "Mobility"
+	case BishopPair:
+		return "Bishop pair"
+	default:
+		return "Unknown"
+	}
+}
+
+// Breakdown holds the score of each term for one side in centipawns, indexed by Term.
+type Breakdown [6]int
+
+// Total returns the sum of the terms.
+func (b Breakdown) Total() int {
+	total := 0
+	for _, value := range b {
+		total += value
+	}
+	return total
+}
+
+// Evaluation is the static score of a position.
+type Evaluation struct {
+	// Total is the score in centipawns from white's point of view, the white breakdown less the black one
+	Total int
+	// Phase runs from 24 with all the pieces on the board down to 0 when only kings and pawns are left. Every
+	// term is tapered between its middlegame and endgame weight by the phase.
+	Phase int
+	White Breakdown
+	Black Breakdown
+}
+
+// Side returns the breakdown of one side.
+func (e Evaluation) Side(color game.ColorType) Breakdown {
+	if color == game.BlackPiece {
+		return e.Black
+	}
+	return e.White
+}
+
+// Relative returns the total from the point of view of the given side.
+func (e Evaluation) Relative(color game.ColorType) int {
+	if color == game.BlackPiece {
+		return -e.Total
+	}
+	return e.Total
+}
+
+// String formats the evaluation as a table of the terms for each side and the difference between them.
+func (e Evaluation) String() string {
+	builder := strings.Builder{}
+	fmt.Fprintf(&builder, "%-15s %6s %6s %6s\n", "Term", "White", "Black", "Total")
+	for _, term := range Terms {
+		fmt.Fprintf(&builder, "%-15s %6d %6d %+6d\n", term, e.White[term], e.Black[term], e.White[term]-e.Black[term])
+	}
+	fmt.Fprintf(&builder, "%-15s %6d %6d %+6d\n", "Total", e.White.Total(), e.Black.Total(), e.Total)
+	return builder.String()
+}
+
+// score is a weight or a term's value in the middlegame and the endgame.
+type score struct {
+	mg int
+	eg int
+}
+
+func (s score) add(other score) score {
+	return score{s.mg + other.mg, s.eg + other.eg}
+}
+
+func (s score) times(n int) score {
+	return score{s.mg * n, s.eg * n}
+}
+
+// taper blends the middlegame and endgame values by the phase.
+func (s score) taper(phase int) int {
+	return (s.mg*phase + s.eg*(maxPhase-phase)) / maxPhase
+}
+
+// Evaluate scores a position.
+func Evaluate(position *game.ChessPosition) Evaluation {
+	e := evaluator{board: position.Board, occupied: position.Board.Occupied()}
+	for i, color := range game.AllColors {
+		e.pieces[i] = position.Board.ColorBitboard(color)
+		e.pawns[i] = position.Board.PieceBitboard(game.ChessPiece{Piece: game.Pawn, Color: color})
+	}
+	for i, color := range game.AllColors {
+		e.pawnAttacks[i] = e.attacksOf(game.ChessPiece{Piece: game.Pawn, Color: color})
+	}
+
+	terms := [2][6]score{}
+	phase := 0
+	for i, color := range game.AllColors {
+		for piece, pieceType := range pieceTypes {
+			count := position.Board.PieceBitboard(game.ChessPiece{Piece: pieceType, Color: color}).Count()
+			terms[i][Material] = terms[i][Material].add(pieceValues[piece].times(count))
+			phase += phaseWeights[piece] * count
+			if pieceType == game.Bishop && count >= 2 {
+				terms[i][BishopPair] = bishopPairBonus
+			}
+		}
+		terms[i][PieceSquares] = e.pieceSquares(color)
+		terms[i][PawnStructure] = e.pawnStructure(color)
+		terms[i][KingSafety] = e.kingSafety(color)
+		terms[i][Mobility] = e.mobility(color)
+	}
+
+	evaluation := Evaluation{Phase: min(phase, maxPhase)}
+	for _, term := range Terms {
+		evaluation.White[term] = terms[0][term].taper(evaluation.Phase)
+		evaluation.Black[term] = terms[1][term].taper(evaluation.Phase)
+	}
+	evaluation.Total = evaluation.White.Total() - evaluation.Black.Total()
+	return evaluation
+}
+
+// evaluator holds the bitboards shared by the terms, indexed by color with white first.
+type evaluator struct {
+	board       *game.ChessBoard
+	occupied    game.Bitboard
+	pieces      [2]game.Bitboard
+	pawns       [2]game.Bitboard
+	pawnAttacks [2]game.Bitboard
+}
+
+func colorIndex(color game.ColorType) int {
+	if color == game.BlackPiece {
+		return 1
+	}
+	return 0
+}
+
+// relativeRank returns the rank of a square counted from the given side, 0 being its first rank.
+func relativeRank(index int, color game.ColorType) int {
+	if color == game.BlackPiece {
+		return 7 - index/8
+	}
+	return index / 8
+}
+
+// attacksOf returns every square attacked by the given piece and color.
+func (e *evaluator) attacksOf(piece game.ChessPiece) game.Bitboard {
+	attacks := game.Bitboard(0)
+	for index := range e.board.PieceBitboard(piece).Squares {
+		attacks |= game.PieceAttacks(piece, index, e.occupied)
+	}
+	return attacks
+}
+
+func (e *evaluator) pieceSquares(color game.ColorType) score {
+	total := score{}
+	for piece, pieceType := range pieceTypes {
+		for index := range e.board.PieceBitboard(game.ChessPiece{Piece: pieceType, Color: color}).Squares {
+			// the tables start at a8, so a white square is found by flipping its rank
+			if color == game.WhitePiece {
+				index ^= 56
+			}
+			total = total.add(score{pieceSquareTables[piece][0][index], pieceSquareTables[piece][1][index]})
+		}
+	}
+	return total
+}
+
+func (e *evaluator) pawnStructure(color game.ColorType) score {
+	own, enemy := e.pawns[colorIndex(color)], e.pawns[colorIndex(color.OppositeColor())]
+	total := score{}
+	for file := 0; file < 8; file++ {
+		if count := (own & fileMasks[file]).Count(); count > 1 {
+			total = total.add(doubledPawnPenalty.times(count - 1))
+		}
+	}
+	for index := range own.Squares {
+		if own&adjacentFileMasks[index%8] == 0 {
+			total = total.add(isolatedPawnPenalty)
+		}
+		if enemy&passedPawnMasks[colorIndex(color)][index] == 0 {
+			total = total.add(passedPawnBonus[relativeRank(index, color)])
+		}
+	}
+	return total
+}
+
+func (e *evaluator) kingSafety(color game.ColorType) score {
+	kings := e.board.PieceBitboard(game.ChessPiece{Piece: game.King, Color: color})
+	if kings == 0 {
+		return score{}
+	}
+	king := kings.First()
+	total := score{}
+
+	own := e.pawns[colorIndex(color)]
+	forward := 8
+	if color == game.BlackPiece {
+		forward = -8
+	}
+	for file := max(king%8-1, 0); file <= min(king%8+1, 7); file++ {
+		if own&fileMasks[file] == 0 {
+			total = total.add(openFilePenalty)
+			continue
+		}
+		// a shield only counts while the king is still at home
+		if relativeRank(king, color) > 1 {
+			continue
+		}
+		front := king - king%8 + file + forward
+		if own.Has(front) {
+			total = total.add(shieldPawnBonus)
+		} else if own.Has(front + forward) {
+			total = total.add(shieldPawnFarBonus)
+		}
+	}
+
+	zone := game.PieceAttacks(game.ChessPiece{Piece: game.King, Color: color}, king, e.occupied) | kings
+	enemy := color.OppositeColor()
+	for piece, pieceType := range pieceTypes {
+		if kingAttackWeights[piece] == 0 {
+			continue
+		}
+		attacks := e.attacksOf(game.ChessPiece{Piece: pieceType, Color: enemy}) & zone
+		total = total.add(attackPenalty.times(kingAttackWeights[piece] * attacks.Count()))
+	}
+	return total
+}
+
+// mobility counts, for each piece, the squares it attacks that are not held by its own side or attacked by
+// an enemy pawn.
+func (e *evaluator) mobility(color game.ColorType) score {
+	safe := ^e.pieces[colorIndex(color)] &^ e.pawnAttacks[colorIndex(color.OppositeColor())]
+	total := score{}
+	for piece, pieceType := range pieceTypes {
+		if mobilityWeights[piece] == (score{}) {
+			continue
+		}
+		chessPiece := game.ChessPiece{Piece: pieceType, Color: color}
+		for index := range e.board.PieceBitboard(chessPiece).Squares {
+			squares := (game.PieceAttacks(chessPiece, index, e.occupied) & safe).Count()
+			total = total.add(mobilityWeights[piece].times(squares - mobilityBaselines[piece]))
+		}
+	}
+	return total
+}
+
+// Masks used by the pawn structure term.
+var (
+	fileMasks         [8]game.Bitboard
+	adjacentFileMasks [8]game.Bitboard
+	// passedPawnMasks holds the squares in front of a pawn on its own and the adjacent files, which must be
+	// free of enemy pawns for it to be passed
+	passedPawnMasks [2][64]game.Bitboard
+)
+
+func init() {
+	for file := 0; file < 8; file++ {
+		fileMasks[file] = game.Bitboard(0x0101010101010101) << file
+	}
+	for file := 0; file < 8; file++ {
+		if file > 0 {
+			adjacentFileMasks[file] |= fileMasks[file-1]
+		}
+		if file < 7 {
+			adjacentFileMasks[file] |= fileMasks[file+1]
+		}
+	}
+	for index := 0; index < 64; index++ {
+		files := fileMasks[index%8] | adjacentFileMasks[index%8]
+		for rank := 0; rank < 8; rank++ {
+			ranks := game.Bitboard(0xFF) << (rank * 8)
+			if rank > index/8 {
+				passedPawnMasks[0][index] |= files & ranks
+			}
+			if rank < index/8 {
+				passedPawnMasks[1][index] |= files & ranks
+			}
+		}
+	}
+}
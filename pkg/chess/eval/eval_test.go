@@ -0,0 +1,137 @@
+package eval
+
+import (
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/jerhon/chess/pkg/chess/fen"
+	"github.com/jerhon/chess/pkg/chess/game"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func evaluateFen(t *testing.T, fenString string) Evaluation {
+	t.Helper()
+	position, err := fen.ParseFen(fenString)
+	require.NoError(t, err)
+	return Evaluate(&position)
+}
+
+// mirrorFen swaps the colors of a position and flips the board so white plays the black side.
+func mirrorFen(fenString string) string {
+	fields := strings.Fields(fenString)
+	ranks := strings.Split(fields[0], "/")
+	for i, j := 0, len(ranks)-1; i < j; i, j = i+1, j-1 {
+		ranks[i], ranks[j] = ranks[j], ranks[i]
+	}
+	swapCase := func(text string) string {
+		return strings.Map(func(r rune) rune {
+			if unicode.IsUpper(r) {
+				return unicode.ToLower(r)
+			}
+			return unicode.ToUpper(r)
+		}, text)
+	}
+	fields[0] = swapCase(strings.Join(ranks, "/"))
+	fields[1] = map[string]string{"w": "b", "b": "w"}[fields[1]]
+	if fields[2] != "-" {
+		fields[2] = swapCase(fields[2])
+	}
+	return strings.Join(fields, " ")
+}
+
+func TestEvaluate_StartingPosition(t *testing.T) {
+	evaluation := Evaluate(game.NewStandardStartingPosition())
+
+	assert.Equal(t, 0, evaluation.Total)
+	assert.Equal(t, 24, evaluation.Phase)
+	assert.Equal(t, evaluation.White, evaluation.Black)
+	assert.Equal(t, 8*100+2*320+2*330+2*500+900, evaluation.White[Material])
+	assert.Equal(t, 30, evaluation.White[BishopPair])
+}
+
+func TestEvaluate_IsSymmetric(t *testing.T) {
+	for _, fenString := range []string{
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+		"r1bq1rk1/pp2bppp/2n1pn2/3p4/2PP4/2N1PN2/PP3PPP/R2QKB1R w KQ - 0 8",
+		"6k1/5ppp/8/8/8/8/5PPP/3R2K1 w - - 0 1",
+	} {
+		t.Run(fenString, func(t *testing.T) {
+			evaluation := evaluateFen(t, fenString)
+			mirrored := evaluateFen(t, mirrorFen(fenString))
+
+			assert.Equal(t, -evaluation.Total, mirrored.Total)
+			assert.Equal(t, evaluation.White, mirrored.Black)
+			assert.Equal(t, evaluation.Black, mirrored.White)
+		})
+	}
+}
+
+func TestEvaluate_TotalIsTheDifferenceOfTheBreakdowns(t *testing.T) {
+	evaluation := evaluateFen(t, "r1bq1rk1/pp2bppp/2n1pn2/3p4/2PP4/2N1PN2/PP3PPP/R2QKB1R w KQ - 0 8")
+
+	assert.Equal(t, evaluation.White.Total()-evaluation.Black.Total(), evaluation.Total)
+	assert.Equal(t, evaluation.Total, evaluation.Relative(game.WhitePiece))
+	assert.Equal(t, -evaluation.Total, evaluation.Relative(game.BlackPiece))
+	assert.Equal(t, evaluation.Black, evaluation.Side(game.BlackPiece))
+}
+
+func TestEvaluate_Material(t *testing.T) {
+	evaluation := evaluateFen(t, "rnb1kbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1")
+
+	assert.Greater(t, evaluation.Total, 800)
+	assert.Equal(t, 20, evaluation.Phase)
+	assert.Greater(t, evaluation.White[Material]-evaluation.Black[Material], 900)
+}
+
+func TestEvaluate_PawnStructure(t *testing.T) {
+	// only kings and pawns, so the endgame weights apply
+	evaluation := evaluateFen(t, "4k3/7p/8/8/8/P7/P7/4K3 w - - 0 1")
+
+	assert.Equal(t, 0, evaluation.Phase)
+	// doubled -20, two isolated -30, passed on the second and third ranks +30
+	assert.Equal(t, -20, evaluation.White[PawnStructure])
+	// isolated -15, passed on the second rank +10
+	assert.Equal(t, -5, evaluation.Black[PawnStructure])
+}
+
+func TestEvaluate_PassedPawns(t *testing.T) {
+	blocked := evaluateFen(t, "4k3/3p4/8/8/8/8/4P3/4K3 w - - 0 1")
+	passed := evaluateFen(t, "4k3/p7/8/8/8/8/4P3/4K3 w - - 0 1")
+
+	assert.Greater(t, passed.White[PawnStructure], blocked.White[PawnStructure])
+}
+
+func TestEvaluate_BishopPair(t *testing.T) {
+	evaluation := evaluateFen(t, "2b1kn2/8/8/8/8/8/8/2B1KB2 w - - 0 1")
+
+	assert.Equal(t, 0, evaluation.Black[BishopPair])
+	assert.Greater(t, evaluation.White[BishopPair], 30)
+}
+
+func TestEvaluate_KingSafety(t *testing.T) {
+	sheltered := evaluateFen(t, "r1bq1rk1/pppp1ppp/2n2n2/2b1p3/2B1P3/2N2N2/PPPP1PPP/R1BQ1RK1 w - - 0 1")
+	exposed := evaluateFen(t, "r1bq1rk1/pppp1ppp/2n2n2/2b1p3/2B1P3/2N2N2/PPPP4/R1BQ1RK1 w - - 0 1")
+
+	assert.Greater(t, sheltered.White[KingSafety], exposed.White[KingSafety])
+	assert.Equal(t, sheltered.Black[KingSafety], exposed.Black[KingSafety])
+}
+
+func TestEvaluate_Mobility(t *testing.T) {
+	centralized := evaluateFen(t, "4k3/8/8/8/4N3/8/8/4K3 w - - 0 1")
+	cornered := evaluateFen(t, "4k3/8/8/8/8/8/8/N3K3 w - - 0 1")
+
+	assert.Greater(t, centralized.White[Mobility], cornered.White[Mobility])
+}
+
+func TestEvaluation_String(t *testing.T) {
+	text := Evaluate(game.NewStandardStartingPosition()).String()
+
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	require.Len(t, lines, len(Terms)+2)
+	assert.True(t, strings.HasPrefix(lines[1], "Material"))
+	assert.True(t, strings.HasPrefix(lines[len(lines)-1], "Total"))
+	assert.True(t, strings.HasSuffix(lines[len(lines)-1], "+0"))
+}
@@ -0,0 +1,143 @@
+package eval
+
+import "github.com/jerhon/chess/pkg/chess/game"
+
+// pieceTypes orders the piece types for the tables below.
+var pieceTypes = [6]game.PieceType{game.Pawn, game.Knight, game.Bishop, game.Rook, game.Queen, game.King}
+
+// pieceValues are the material values in the middlegame and endgame, in pieceTypes order.
+var pieceValues = [6]score{{100, 120}, {320, 300}, {330, 320}, {500, 540}, {900, 950}, {0, 0}}
+
+// phaseWeights is how much each piece counts towards the game phase, in pieceTypes order. All the pieces of
+// the starting position add up to maxPhase.
+var phaseWeights = [6]int{0, 1, 1, 2, 4, 0}
+
+const maxPhase = 24
+
+// Piece-square tables give a bonus for each piece on each square from white's point of view, written as the
+// board is drawn with rank 8 first. The middlegame tables are from
+// https://www.chessprogramming.org/Simplified_Evaluation_Function; in the endgame pawns are rewarded for
+// advancing and the king for centralising, the other pieces use their middlegame tables.
+var (
+	pawnMiddlegame = [64]int{
+		0, 0, 0, 0, 0, 0, 0, 0,
+		50, 50, 50, 50, 50, 50, 50, 50,
+		10, 10, 20, 30, 30, 20, 10, 10,
+		5, 5, 10, 25, 25, 10, 5, 5,
+		0, 0, 0, 20, 20, 0, 0, 0,
+		5, -5, -10, 0, 0, -10, -5, 5,
+		5, 10, 10, -20, -20, 10, 10, 5,
+		0, 0, 0, 0, 0, 0, 0, 0,
+	}
+	pawnEndgame = [64]int{
+		0, 0, 0, 0, 0, 0, 0, 0,
+		80, 80, 80, 80, 80, 80, 80, 80,
+		50, 50, 50, 50, 50, 50, 50, 50,
+		30, 30, 30, 30, 30, 30, 30, 30,
+		15, 15, 15, 15, 15, 15, 15, 15,
+		5, 5, 5, 5, 5, 5, 5, 5,
+		0, 0, 0, 0, 0, 0, 0, 0,
+		0, 0, 0, 0, 0, 0, 0, 0,
+	}
+	knightTable = [64]int{
+		-50, -40, -30, -30, -30, -30, -40, -50,
+		-40, -20, 0, 0, 0, 0, -20, -40,
+		-30, 0, 10, 15, 15, 10, 0, -30,
+		-30, 5, 15, 20, 20, 15, 5, -30,
+		-30, 0, 15, 20, 20, 15, 0, -30,
+		-30, 5, 10, 15, 15, 10, 5, -30,
+		-40, -20, 0, 5, 5, 0, -20, -40,
+		-50, -40, -30, -30, -30, -30, -40, -50,
+	}
+	bishopTable = [64]int{
+		-20, -10, -10, -10, -10, -10, -10, -20,
+		-10, 0, 0, 0, 0, 0, 0, -10,
+		-10, 0, 5, 10, 10, 5, 0, -10,
+		-10, 5, 5, 10, 10, 5, 5, -10,
+		-10, 0, 10, 10, 10, 10, 0, -10,
+		-10, 10, 10, 10, 10, 10, 10, -10,
+		-10, 5, 0, 0, 0, 0, 5, -10,
+		-20, -10, -10, -10, -10, -10, -10, -20,
+	}
+	rookTable = [64]int{
+		0, 0, 0, 0, 0, 0, 0, 0,
+		5, 10, 10, 10, 10, 10, 10, 5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		0, 0, 0, 5, 5, 0, 0, 0,
+	}
+	queenTable = [64]int{
+		-20, -10, -10, -5, -5, -10, -10, -20,
+		-10, 0, 0, 0, 0, 0, 0, -10,
+		-10, 0, 5, 5, 5, 5, 0, -10,
+		-5, 0, 5, 5, 5, 5, 0, -5,
+		0, 0, 5, 5, 5, 5, 0, -5,
+		-10, 5, 5, 5, 5, 5, 0, -10,
+		-10, 0, 5, 0, 0, 0, 0, -10,
+		-20, -10, -10, -5, -5, -10, -10, -20,
+	}
+	kingMiddlegame = [64]int{
+		-30, -40, -40, -50, -50, -40, -40, -30,
+		-30, -40, -40, -50, -50, -40, -40, -30,
+		-30, -40, -40, -50, -50, -40, -40, -30,
+		-30, -40, -40, -50, -50, -40, -40, -30,
+		-20, -30, -30, -40, -40, -30, -30, -20,
+		-10, -20, -20, -20, -20, -20, -20, -10,
+		20, 20, 0, 0, 0, 0, 20, 20,
+		20, 30, 10, 0, 0, 10, 30, 20,
+	}
+	kingEndgame = [64]int{
+		-50, -40, -30, -20, -20, -30, -40, -50,
+		-30, -20, -10, 0, 0, -10, -20, -30,
+		-30, -10, 20, 30, 30, 20, -10, -30,
+		-30, -10, 30, 40, 40, 30, -10, -30,
+		-30, -10, 30, 40, 40, 30, -10, -30,
+		-30, -10, 20, 30, 30, 20, -10, -30,
+		-30, -30, 0, 0, 0, 0, -30, -30,
+		-50, -30, -30, -30, -30, -30, -30, -50,
+	}
+)
+
+// pieceSquareTables pairs the middlegame and endgame table of each piece, in pieceTypes order.
+var pieceSquareTables = [6][2]*[64]int{
+	{&pawnMiddlegame, &pawnEndgame},
+	{&knightTable, &knightTable},
+	{&bishopTable, &bishopTable},
+	{&rookTable, &rookTable},
+	{&queenTable, &queenTable},
+	{&kingMiddlegame, &kingEndgame},
+}
+
+// Pawn structure weights. passedPawnBonus is indexed by the rank of the pawn counted from its own side,
+// 0 being its first rank.
+var (
+	doubledPawnPenalty  = score{-10, -20}
+	isolatedPawnPenalty = score{-10, -15}
+	passedPawnBonus     = [8]score{{0, 0}, {5, 10}, {10, 20}, {20, 40}, {35, 70}, {60, 120}, {100, 200}, {0, 0}}
+)
+
+// King safety weights, which only apply in the middlegame.
+var (
+	// shieldPawnBonus is given for each pawn directly in front of the king and shieldPawnFarBonus for one a
+	// square further
+	shieldPawnBonus    = score{10, 0}
+	shieldPawnFarBonus = score{5, 0}
+	// openFilePenalty is given for each file next to the king without a pawn of its side
+	openFilePenalty = score{-15, 0}
+	// attackPenalty is given for each attack on the squares around the king, multiplied by the attacker's
+	// kingAttackWeights in pieceTypes order
+	attackPenalty     = score{-5, 0}
+	kingAttackWeights = [6]int{0, 2, 2, 3, 5, 0}
+)
+
+// Mobility weights for each square a piece can move to, less the average number of squares, in pieceTypes
+// order.
+var (
+	mobilityWeights   = [6]score{{0, 0}, {4, 4}, {5, 5}, {2, 4}, {1, 2}, {0, 0}}
+	mobilityBaselines = [6]int{0, 4, 6, 7, 13, 0}
+)
+
+var bishopPairBonus = score{30, 50}
@@ -0,0 +1,246 @@
+package fen
+
+// epd.go reads and writes Extended Position Description records: the first four fields of a FEN followed
+// by operations such as bm Qg6; id "WAC.001";
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jerhon/chess/pkg/chess/game"
+)
+
+// EpdOperation is an opcode and its operands. String operands are held without their quotes.
+type EpdOperation struct {
+	Opcode   string
+	Operands []string
+}
+
+// Epd is a position with the operations of an EPD record. The common opcodes are parsed into fields, any
+// others are kept in Operations in the order they were read.
+type Epd struct {
+	// Position holds the four position fields, with the move counters taken from hmvc and fmvn when given
+	Position game.ChessPosition
+
+	// ID names the position, opcode id
+	ID string
+	// BestMoves and AvoidMoves are the moves of bm and am as written, normally in SAN
+	BestMoves  []string
+	AvoidMoves []string
+	// Comments holds c0 to c9, empty when not given
+	Comments [10]string
+	// Depth is the analysis count depth of acd and Eval the centipawn evaluation of ce, nil when not given
+	Depth *int
+	Eval  *int
+	// PV is the predicted variation of pv
+	PV []string
+
+	Operations []EpdOperation
+}
+
+// ParseEpd parses one EPD record.
+func ParseEpd(epdString string) (Epd, error) {
+	fields := strings.Fields(epdString)
+	if len(fields) < 4 {
+		return Epd{}, fmt.Errorf("invalid EPD, expected 4 position fields: %q", epdString)
+	}
+
+	position, err := ParseFen(strings.Join(fields[:4], " ") + " 0 1")
+	if err != nil {
+		return Epd{}, err
+	}
+
+	// the operations start after the fourth field, found again in the original text as quoted operands may
+	// hold several spaces
+	rest := epdString
+	for i := 0; i < 4; i++ {
+		rest = strings.TrimLeft(rest, " \t")
+		rest = rest[len(fields[i]):]
+	}
+	operations, err := parseEpdOperations(rest)
+	if err != nil {
+		return Epd{}, err
+	}
+
+	epd := Epd{Position: position}
+	for _, operation := range operations {
+		if err := epd.setOperation(operation); err != nil {
+			return Epd{}, err
+		}
+	}
+	return epd, nil
+}
+
+// parseEpdOperations splits the operations of a record, each ending with a semicolon.
+func parseEpdOperations(text string) ([]EpdOperation, error) {
+	operations := []EpdOperation{}
+	tokens := []string{}
+	token, quoted, inToken := strings.Builder{}, false, false
+
+	endToken := func() {
+		if inToken {
+			tokens = append(tokens, token.String())
+			token.Reset()
+			inToken = false
+		}
+	}
+
+	for _, r := range text {
+		switch {
+		case quoted && r == '"':
+			quoted = false
+		case quoted:
+			token.WriteRune(r)
+		case r == '"':
+			quoted, inToken = true, true
+		case r == ';':
+			endToken()
+			if len(tokens) == 0 {
+				return nil, fmt.Errorf("invalid EPD, empty operation")
+			}
+			operations = append(operations, EpdOperation{Opcode: tokens[0], Operands: tokens[1:]})
+			tokens = []string{}
+		case r == ' ' || r == '\t':
+			endToken()
+		default:
+			token.WriteRune(r)
+			inToken = true
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("invalid EPD, unterminated string")
+	}
+	endToken()
+	if len(tokens) > 0 {
+		return nil, fmt.Errorf("invalid EPD, operation %s is missing its semicolon", tokens[0])
+	}
+	return operations, nil
+}
+
+func (epd *Epd) setOperation(operation EpdOperation) error {
+	single := func() (string, error) {
+		if len(operation.Operands) != 1 {
+			return "", fmt.Errorf("invalid EPD, %s expects one operand, saw %d", operation.Opcode, len(operation.Operands))
+		}
+		return operation.Operands[0], nil
+	}
+	number := func() (int, error) {
+		operand, err := single()
+		if err != nil {
+			return 0, err
+		}
+		value, err := strconv.Atoi(operand)
+		if err != nil {
+			return 0, fmt.Errorf("invalid EPD, %s expects a number, saw %q", operation.Opcode, operand)
+		}
+		return value, nil
+	}
+
+	var err error
+	switch opcode := operation.Opcode; {
+	case opcode == "id":
+		epd.ID, err = single()
+	case opcode == "bm":
+		epd.BestMoves = slices.Clone(operation.Operands)
+	case opcode == "am":
+		epd.AvoidMoves = slices.Clone(operation.Operands)
+	case opcode == "pv":
+		epd.PV = slices.Clone(operation.Operands)
+	case len(opcode) == 2 && opcode[0] == 'c' && opcode[1] >= '0' && opcode[1] <= '9':
+		epd.Comments[opcode[1]-'0'], err = single()
+	case opcode == "acd":
+		var depth int
+		depth, err = number()
+		epd.Depth = &depth
+	case opcode == "ce":
+		var eval int
+		eval, err = number()
+		epd.Eval = &eval
+	case opcode == "hmvc":
+		epd.Position.HalfmoveClock, err = number()
+	case opcode == "fmvn":
+		epd.Position.FullmoveNumber, err = number()
+	default:
+		epd.Operations = append(epd.Operations, operation)
+	}
+	return err
+}
+
+// ToEpdString writes an EPD record. The parsed opcodes are written first in the order bm, am, pv, acd, ce,
+// id, c0 to c9, then hmvc and fmvn when the move counters differ from 0 and 1, then the other operations.
+func ToEpdString(epd Epd) string {
+	builder := strings.Builder{}
+	fields := strings.Fields(ToFenString(&epd.Position))
+	builder.WriteString(strings.Join(fields[:4], " "))
+
+	write := func(opcode string, operands ...string) {
+		builder.WriteString(" " + opcode)
+		for _, operand := range operands {
+			builder.WriteString(" " + operand)
+		}
+		builder.WriteString(";")
+	}
+	quote := func(text string) string {
+		return `"` + text + `"`
+	}
+
+	if len(epd.BestMoves) > 0 {
+		write("bm", epd.BestMoves...)
+	}
+	if len(epd.AvoidMoves) > 0 {
+		write("am", epd.AvoidMoves...)
+	}
+	if len(epd.PV) > 0 {
+		write("pv", epd.PV...)
+	}
+	if epd.Depth != nil {
+		write("acd", strconv.Itoa(*epd.Depth))
+	}
+	if epd.Eval != nil {
+		write("ce", strconv.Itoa(*epd.Eval))
+	}
+	if epd.ID != "" {
+		write("id", quote(epd.ID))
+	}
+	for i, comment := range epd.Comments {
+		if comment != "" {
+			write(fmt.Sprintf("c%d", i), quote(comment))
+		}
+	}
+	if epd.Position.HalfmoveClock != 0 || epd.Position.FullmoveNumber != 1 {
+		write("hmvc", strconv.Itoa(epd.Position.HalfmoveClock))
+		write("fmvn", strconv.Itoa(epd.Position.FullmoveNumber))
+	}
+	for _, operation := range epd.Operations {
+		operands := slices.Clone(operation.Operands)
+		for i, operand := range operands {
+			if operand == "" || strings.ContainsAny(operand, " \t;\"") {
+				operands[i] = quote(operand)
+			}
+		}
+		write(operation.Opcode, operands...)
+	}
+	return builder.String()
+}
+
+// ReadEpd reads a file of EPD records, one a line. Blank lines and lines starting with # are skipped.
+func ReadEpd(reader io.Reader) ([]Epd, error) {
+	records := []Epd{}
+	scanner := bufio.NewScanner(reader)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		epd, err := ParseEpd(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		records = append(records, epd)
+	}
+	return records, scanner.Err()
+}
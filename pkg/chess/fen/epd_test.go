@@ -0,0 +1,84 @@
+package fen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEpd(t *testing.T) {
+	epd, err := ParseEpd(`2rr3k/pp3pp1/1nnqbN1p/3pN3/2pP4/2P3Q1/PPB4P/R4RK1 w - - bm Qg6; id "WAC.001"; c0 "mate  in 3"; acd 12; ce 32000; pv Qg6 fxg6 Nxg6+;`)
+	require.NoError(t, err)
+
+	assert.Equal(t, "2rr3k/pp3pp1/1nnqbN1p/3pN3/2pP4/2P3Q1/PPB4P/R4RK1 w - - 0 1", ToFenString(&epd.Position))
+	assert.Equal(t, "WAC.001", epd.ID)
+	assert.Equal(t, []string{"Qg6"}, epd.BestMoves)
+	assert.Nil(t, epd.AvoidMoves)
+	assert.Equal(t, "mate  in 3", epd.Comments[0])
+	require.NotNil(t, epd.Depth)
+	assert.Equal(t, 12, *epd.Depth)
+	require.NotNil(t, epd.Eval)
+	assert.Equal(t, 32000, *epd.Eval)
+	assert.Equal(t, []string{"Qg6", "fxg6", "Nxg6+"}, epd.PV)
+	assert.Empty(t, epd.Operations)
+}
+
+func TestParseEpd_MoveCountersAndOtherOpcodes(t *testing.T) {
+	epd, err := ParseEpd(`4k3/8/8/8/8/8/8/4K2R w K - am O-O Rh8+; hmvc 7; fmvn 40; noop; sv "a;b" c;`)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"O-O", "Rh8+"}, epd.AvoidMoves)
+	assert.Equal(t, 7, epd.Position.HalfmoveClock)
+	assert.Equal(t, 40, epd.Position.FullmoveNumber)
+	assert.Equal(t, []EpdOperation{{"noop", []string{}}, {"sv", []string{"a;b", "c"}}}, epd.Operations)
+}
+
+func TestParseEpd_Errors(t *testing.T) {
+	tests := map[string]string{
+		"too few fields":     "4k3/8/8/8/8/8/8/4K3 w -",
+		"bad position":       "4k3/8/8/8/8/8/8/4K3 x - - bm Kd1;",
+		"missing semicolon":  "4k3/8/8/8/8/8/8/4K3 w - - bm Kd1",
+		"unterminated":       `4k3/8/8/8/8/8/8/4K3 w - - id "WAC;`,
+		"empty operation":    "4k3/8/8/8/8/8/8/4K3 w - - ;",
+		"id with two values": `4k3/8/8/8/8/8/8/4K3 w - - id a b;`,
+		"acd not a number":   "4k3/8/8/8/8/8/8/4K3 w - - acd deep;",
+	}
+	for name, epdString := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseEpd(epdString)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestToEpdString_RoundTrip(t *testing.T) {
+	tests := []string{
+		"4k3/8/8/8/8/8/8/4K3 w - -",
+		`2rr3k/pp3pp1/1nnqbN1p/3pN3/2pP4/2P3Q1/PPB4P/R4RK1 w - - bm Qg6; id "WAC.001";`,
+		`1kr5/3n4/q3p2p/p2n2p1/PppB1P2/5BP1/1P2Q2P/3R2K1 w - - bm f5; am Bxd5 Qe1; pv f5 exf5; acd 10; ce -35; id "STS(v1.0) Undermine.001"; c0 "f5=10, Be5+=2, Bf2=3, Bg4=2"; c9 "note";`,
+		"4k3/8/8/8/8/8/8/4K2R w K - hmvc 7; fmvn 40; noop; sv \"a;b\" c;",
+	}
+	for _, epdString := range tests {
+		epd, err := ParseEpd(epdString)
+		require.NoError(t, err)
+		assert.Equal(t, epdString, ToEpdString(epd))
+	}
+}
+
+func TestReadEpd(t *testing.T) {
+	suite := `# a small suite
+
+4k3/8/8/8/8/8/8/4K2R w K - bm Rh8+; id "one";
+4k3/8/8/8/8/8/8/R3K3 w Q - bm Ra8+; id "two";
+`
+	records, err := ReadEpd(strings.NewReader(suite))
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, "one", records[0].ID)
+	assert.Equal(t, "two", records[1].ID)
+
+	_, err = ReadEpd(strings.NewReader("4k3/8/8/8/8/8/8/4K2R w K - bm Rh8+; id \"one\";\nbad\n"))
+	assert.ErrorContains(t, err, "line 2")
+}
@@ -106,21 +106,40 @@ func (p *FenParser) ParsePlayerToMove() (game.ColorType, error) {
 	return piece, nil
 }
 
-func (p *FenParser) ParseCastlingRights() (whiteCastlingRights game.CastlingRights, blackCastlingRights game.CastlingRights, err error) {
+// ParseCastlingRights reads the castling field in standard FEN, X-FEN or Shredder-FEN. K and Q castle with
+// the outermost rook on that side of the king, a file letter with the rook on that file, which is needed in
+// Chess960 when another rook stands further out.
+func (p *FenParser) ParseCastlingRights(board *game.ChessBoard) (whiteCastlingRights game.CastlingRights, blackCastlingRights game.CastlingRights, err error) {
 	fenCastlingRights, _, err := p.reader.ReadRune()
 	if fenCastlingRights != '-' {
 		for fenCastlingRights != ' ' && err == nil {
-			switch fenCastlingRights {
+			color := game.WhitePiece
+			if unicode.IsLower(fenCastlingRights) {
+				color = game.BlackPiece
+			}
+			rights := &whiteCastlingRights
+			if color == game.BlackPiece {
+				rights = &blackCastlingRights
+			}
+
+			switch unicode.ToUpper(fenCastlingRights) {
 			case 'K':
-				whiteCastlingRights.KingSide = true
+				rights.KingSide = true
+				rights.KingSideRook = outermostRook(board, color, true)
 			case 'Q':
-				whiteCastlingRights.QueenSide = true
-			case 'k':
-				blackCastlingRights.KingSide = true
-			case 'q':
-				blackCastlingRights.QueenSide = true
+				rights.QueenSide = true
+				rights.QueenSideRook = outermostRook(board, color, false)
+			case 'A', 'B', 'C', 'D', 'E', 'F', 'G', 'H':
+				file, _ := ParseFile(fenCastlingRights)
+				if file > castlingKingFile(board, color) {
+					rights.KingSide = true
+					rights.KingSideRook = file
+				} else {
+					rights.QueenSide = true
+					rights.QueenSideRook = file
+				}
 			default:
-				return game.CastlingRights{}, game.CastlingRights{}, fmt.Errorf("invalid FEN castling rights, expected 'K', 'Q', 'k', or 'q', saw: %c", fenCastlingRights)
+				return game.CastlingRights{}, game.CastlingRights{}, fmt.Errorf("invalid FEN castling rights, expected 'K', 'Q', 'k', 'q' or a file, saw: %c", fenCastlingRights)
 			}
 
 			fenCastlingRights, _, err = p.reader.ReadRune()
@@ -133,7 +152,57 @@ func (p *FenParser) ParseCastlingRights() (whiteCastlingRights game.CastlingRigh
 		}
 	}
 
-	return whiteCastlingRights, blackCastlingRights, nil
+	return standardRookFiles(whiteCastlingRights), standardRookFiles(blackCastlingRights), nil
+}
+
+// castlingKingFile returns the file of a color's king on its back rank, the e file when it is not there.
+func castlingKingFile(board *game.ChessBoard, color game.ColorType) game.FileType {
+	rank := castlingRank(color)
+	for file := game.FileA; file <= game.FileH; file++ {
+		if board.GetSquare(game.ChessLocation{File: file, Rank: rank}).Piece == (game.ChessPiece{Piece: game.King, Color: color}) {
+			return file
+		}
+	}
+	return game.FileE
+}
+
+func castlingRank(color game.ColorType) game.RankType {
+	if color == game.BlackPiece {
+		return game.Rank8
+	}
+	return game.Rank1
+}
+
+// outermostRook returns the file of the rook furthest from the king on one side of the back rank, or NoFile
+// when there is none.
+func outermostRook(board *game.ChessBoard, color game.ColorType, kingSide bool) game.FileType {
+	kingFile := castlingKingFile(board, color)
+	rook := game.ChessPiece{Piece: game.Rook, Color: color}
+	if kingSide {
+		for file := game.FileH; file > kingFile; file-- {
+			if board.GetSquare(game.ChessLocation{File: file, Rank: castlingRank(color)}).Piece == rook {
+				return file
+			}
+		}
+	} else {
+		for file := game.FileA; file < kingFile; file++ {
+			if board.GetSquare(game.ChessLocation{File: file, Rank: castlingRank(color)}).Piece == rook {
+				return file
+			}
+		}
+	}
+	return game.NoFile
+}
+
+// standardRookFiles clears rook files on the h and a files, which CastlingRights holds as NoFile.
+func standardRookFiles(rights game.CastlingRights) game.CastlingRights {
+	if rights.KingSideRook == game.FileH {
+		rights.KingSideRook = game.NoFile
+	}
+	if rights.QueenSideRook == game.FileA {
+		rights.QueenSideRook = game.NoFile
+	}
+	return rights
 }
 
 func ParseFile(r rune) (game.FileType, error) {
@@ -249,7 +318,7 @@ func ParseFen(fenString string) (game.ChessPosition, error) {
 		return game.ChessPosition{}, err
 	}
 
-	whiteCastlingRights, blackCastlingRights, err := parser.ParseCastlingRights()
+	whiteCastlingRights, blackCastlingRights, err := parser.ParseCastlingRights(chessBoard)
 	if err != nil {
 		return game.ChessPosition{}, err
 	}
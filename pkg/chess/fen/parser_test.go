@@ -1,6 +1,7 @@
 package fen
 
 import (
+	"github.com/jerhon/chess/pkg/chess/game"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -30,3 +31,45 @@ func TestFenParser_ParseBoard(t *testing.T) {
 		})
 	}
 }
+
+func TestParseFen_Chess960CastlingRights(t *testing.T) {
+	tests := []struct {
+		name  string
+		fen   string
+		white game.CastlingRights
+		black game.CastlingRights
+	}{
+		{
+			name:  "standard letters keep the h and a files as NoFile",
+			fen:   "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1",
+			white: game.CastlingRights{KingSide: true, QueenSide: true},
+			black: game.CastlingRights{KingSide: true, QueenSide: true},
+		},
+		{
+			name:  "shredder files",
+			fen:   "bqnb1rkr/8/8/8/8/8/8/BQ1BNRKR w HFhf - 2 9",
+			white: game.CastlingRights{KingSide: true, QueenSide: true, QueenSideRook: game.FileF},
+			black: game.CastlingRights{KingSide: true, QueenSide: true, QueenSideRook: game.FileF},
+		},
+		{
+			name:  "x-fen letters take the outermost rook",
+			fen:   "rr4kr/8/8/8/8/8/8/1RK1R2R w KQkq - 0 1",
+			white: game.CastlingRights{KingSide: true, QueenSide: true, QueenSideRook: game.FileB},
+			black: game.CastlingRights{KingSide: true, QueenSide: true},
+		},
+		{
+			name:  "x-fen file for an inner rook",
+			fen:   "1r2k3/8/8/8/8/8/8/1RK1R2R w Eb - 0 1",
+			white: game.CastlingRights{KingSide: true, KingSideRook: game.FileE},
+			black: game.CastlingRights{QueenSide: true, QueenSideRook: game.FileB},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			position, err := ParseFen(tt.fen)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.white, position.CastlingRights[game.WhitePiece])
+			assert.Equal(t, tt.black, position.CastlingRights[game.BlackPiece])
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/jerhon/chess/pkg/chess/game"
 	"strings"
+	"unicode"
 )
 
 type FenSerializer struct {
@@ -92,31 +93,53 @@ func (s *FenSerializer) WritePlayerToMove(playerColor game.ColorType) {
 	}
 }
 
-func (s *FenSerializer) WriteCastlingRights(castlingRights game.CastlingRights, playerColor game.ColorType) bool {
+// WriteCastlingRights writes the castling rights of one player in X-FEN: K and Q unless another rook stands
+// further out than the castling rook on that side of the king, when the rook's file is written instead. For
+// standard chess this is the same as FEN. It returns false if the player has no castling rights.
+func (s *FenSerializer) WriteCastlingRights(board *game.ChessBoard, castlingRights game.CastlingRights, playerColor game.ColorType) bool {
 	result := false
-	if playerColor == game.WhitePiece {
-		if castlingRights.KingSide {
-			s.builder.WriteString("K")
-			result = true
-		}
-		if castlingRights.QueenSide {
-			s.builder.WriteString("Q")
-			result = true
-		}
-	} else {
-		if castlingRights.KingSide {
-			s.builder.WriteString("k")
-			result = true
+	if castlingRights.KingSide {
+		rookFile := castlingRights.KingSideRookFile()
+		if outermost := outermostRook(board, playerColor, true); outermost == game.NoFile || outermost == rookFile {
+			s.writeCastlingRune('K', playerColor)
+		} else {
+			s.writeCastlingRune(unicode.ToUpper(rune(rookFile)), playerColor)
 		}
-		if castlingRights.QueenSide {
-			s.builder.WriteString("q")
-			result = true
+		result = true
+	}
+	if castlingRights.QueenSide {
+		rookFile := castlingRights.QueenSideRookFile()
+		if outermost := outermostRook(board, playerColor, false); outermost == game.NoFile || outermost == rookFile {
+			s.writeCastlingRune('Q', playerColor)
+		} else {
+			s.writeCastlingRune(unicode.ToUpper(rune(rookFile)), playerColor)
 		}
+		result = true
 	}
 
 	return result
 }
 
+// WriteShredderCastlingRights writes the castling rights of one player in Shredder-FEN, as the files of the
+// castling rooks. It returns false if the player has no castling rights.
+func (s *FenSerializer) WriteShredderCastlingRights(castlingRights game.CastlingRights, playerColor game.ColorType) bool {
+	if castlingRights.KingSide {
+		s.writeCastlingRune(unicode.ToUpper(rune(castlingRights.KingSideRookFile())), playerColor)
+	}
+	if castlingRights.QueenSide {
+		s.writeCastlingRune(unicode.ToUpper(rune(castlingRights.QueenSideRookFile())), playerColor)
+	}
+	return castlingRights.KingSide || castlingRights.QueenSide
+}
+
+// writeCastlingRune writes an upper case castling character, in lower case for black.
+func (s *FenSerializer) writeCastlingRune(r rune, playerColor game.ColorType) {
+	if playerColor == game.BlackPiece {
+		r = unicode.ToLower(r)
+	}
+	s.builder.WriteRune(r)
+}
+
 func (s *FenSerializer) WriteEmpty() {
 	s.builder.WriteRune('-')
 }
@@ -142,7 +165,22 @@ func (s *FenSerializer) WriteSpacer() {
 	s.builder.WriteRune(' ')
 }
 
+// ToFenString writes a position in FEN, using X-FEN for the castling rights of Chess960 positions that
+// plain FEN cannot describe.
 func ToFenString(s *game.ChessPosition) string {
+	return toFenString(s, func(fen *FenSerializer, color game.ColorType) bool {
+		return fen.WriteCastlingRights(s.Board, s.CastlingRights[color], color)
+	})
+}
+
+// ToShredderFenString writes a position in Shredder-FEN, which names the file of each castling rook.
+func ToShredderFenString(s *game.ChessPosition) string {
+	return toFenString(s, func(fen *FenSerializer, color game.ColorType) bool {
+		return fen.WriteShredderCastlingRights(s.CastlingRights[color], color)
+	})
+}
+
+func toFenString(s *game.ChessPosition, writeCastlingRights func(fen *FenSerializer, color game.ColorType) bool) string {
 	fen := NewFenSerializer()
 
 	fen.WriteBoard(s.Board)
@@ -150,8 +188,8 @@ func ToFenString(s *game.ChessPosition) string {
 	fen.WritePlayerToMove(s.PlayerToMove)
 	fen.WriteSpacer()
 
-	whiteCastlingRightsWritten := fen.WriteCastlingRights(s.CastlingRights[game.WhitePiece], game.WhitePiece)
-	blackCastlingRightsWritten := fen.WriteCastlingRights(s.CastlingRights[game.BlackPiece], game.BlackPiece)
+	whiteCastlingRightsWritten := writeCastlingRights(fen, game.WhitePiece)
+	blackCastlingRightsWritten := writeCastlingRights(fen, game.BlackPiece)
 
 	if !blackCastlingRightsWritten && !whiteCastlingRightsWritten {
 		fen.WriteEmpty()
@@ -44,3 +44,43 @@ func TestToFenString(t *testing.T) {
 		})
 	}
 }
+
+func TestToFenString_Chess960(t *testing.T) {
+	tests := []struct {
+		name     string
+		fen      string
+		xFen     string
+		shredder string
+	}{
+		{
+			name:     "standard position",
+			fen:      "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+			xFen:     "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+			shredder: "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w HAha - 0 1",
+		},
+		{
+			name:     "outermost rooks",
+			fen:      "bbqnnrkr/pppppppp/8/8/8/8/PPPPPPPP/BBQNNRKR w HFhf - 0 1",
+			xFen:     "bbqnnrkr/pppppppp/8/8/8/8/PPPPPPPP/BBQNNRKR w KQkq - 0 1",
+			shredder: "bbqnnrkr/pppppppp/8/8/8/8/PPPPPPPP/BBQNNRKR w HFhf - 0 1",
+		},
+		{
+			name:     "inner rook castles",
+			fen:      "1r2k1r1/8/8/8/8/8/8/RR2K2R w Bq - 0 1",
+			xFen:     "1r2k1r1/8/8/8/8/8/8/RR2K2R w Bq - 0 1",
+			shredder: "1r2k1r1/8/8/8/8/8/8/RR2K2R w Bb - 0 1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			position, err := ParseFen(tt.fen)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.xFen, ToFenString(&position))
+			assert.Equal(t, tt.shredder, ToShredderFenString(&position))
+
+			fromXFen, err := ParseFen(tt.xFen)
+			assert.NoError(t, err)
+			assert.Equal(t, position.CastlingRights, fromXFen.CastlingRights)
+		})
+	}
+}
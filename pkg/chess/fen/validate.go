@@ -0,0 +1,241 @@
+package fen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jerhon/chess/pkg/chess/game"
+)
+
+// ViolationCode identifies why a position can't occur in a game.
+type ViolationCode int
+
+const (
+	// MissingKing means a side has no king.
+	MissingKing ViolationCode = iota + 1
+	// TooManyKings means a side has more than one king.
+	TooManyKings
+	// TooManyPawns means a side has more than eight pawns.
+	TooManyPawns
+	// TooManyPieces means a side has more than sixteen pieces.
+	TooManyPieces
+	// PawnOnBackRank means a pawn stands on the first or eighth rank.
+	PawnOnBackRank
+	// OpponentInCheck means the side that just moved left its king in check.
+	OpponentInCheck
+	// InvalidCastlingRights means a castling right has no king or rook on its home square.
+	InvalidCastlingRights
+	// InvalidEnPassantSquare means the en passant square is not behind a pawn that has just moved two squares.
+	InvalidEnPassantSquare
+)
+
+// String returns a short name for the code.
+func (c ViolationCode) String() string {
+	switch c {
+	case MissingKing:
+		return "missing king"
+	case TooManyKings:
+		return "too many kings"
+	case TooManyPawns:
+		return "too many pawns"
+	case TooManyPieces:
+		return "too many pieces"
+	case PawnOnBackRank:
+		return "pawn on back rank"
+	case OpponentInCheck:
+		return "opponent in check"
+	case InvalidCastlingRights:
+		return "invalid castling rights"
+	case InvalidEnPassantSquare:
+		return "invalid en passant square"
+	default:
+		return "unknown"
+	}
+}
+
+// Violation is a reason a position can't occur in a game.
+type Violation struct {
+	Code    ViolationCode
+	Message string
+}
+
+func (v Violation) String() string {
+	return v.Message
+}
+
+// ValidationError is returned by ParseFenStrict and ParseFenLenient for a position with violations.
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	messages := []string{}
+	for _, violation := range e.Violations {
+		messages = append(messages, violation.Message)
+	}
+	return "invalid position: " + strings.Join(messages, "; ")
+}
+
+// Has returns true if one of the violations has the code.
+func (e *ValidationError) Has(code ViolationCode) bool {
+	for _, violation := range e.Violations {
+		if violation.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks that a position could occur in a game of chess: each side has one king and at most eight
+// pawns and sixteen pieces, no pawn is on the first or eighth rank, the side that just moved is not in check,
+// each castling right has its king and rook on the back rank and the en passant square is behind a pawn that
+// has just moved two squares. It returns nil for a valid position.
+func Validate(position *game.ChessPosition) []Violation {
+	var violations []Violation
+	add := func(code ViolationCode, format string, args ...any) {
+		violations = append(violations, Violation{Code: code, Message: fmt.Sprintf(format, args...)})
+	}
+
+	board := position.Board
+	for _, color := range game.AllColors {
+		switch kings := board.PieceBitboard(game.ChessPiece{Piece: game.King, Color: color}).Count(); {
+		case kings == 0:
+			add(MissingKing, "%s has no king", colorName(color))
+		case kings > 1:
+			add(TooManyKings, "%s has %d kings", colorName(color), kings)
+		}
+		if pawns := board.PieceBitboard(game.ChessPiece{Piece: game.Pawn, Color: color}).Count(); pawns > 8 {
+			add(TooManyPawns, "%s has %d pawns", colorName(color), pawns)
+		}
+		if pieces := board.ColorBitboard(color).Count(); pieces > 16 {
+			add(TooManyPieces, "%s has %d pieces", colorName(color), pieces)
+		}
+	}
+
+	for square := range board.IterateSquares() {
+		if square.Piece.Piece == game.Pawn && (square.Location.Rank == game.Rank1 || square.Location.Rank == game.Rank8) {
+			add(PawnOnBackRank, "pawn on %s", square.Location)
+		}
+	}
+
+	opponent := position.PlayerToMove.OppositeColor()
+	if kings := board.PieceBitboard(game.ChessPiece{Piece: game.King, Color: opponent}); kings.Count() == 1 {
+		if game.IsSquareAttacked(board, game.LocationFromIndex(kings.First()), position.PlayerToMove) {
+			add(OpponentInCheck, "%s is in check but it is %s's move", colorName(opponent), colorName(position.PlayerToMove))
+		}
+	}
+
+	for _, color := range game.AllColors {
+		rights := position.CastlingRights[color]
+		if validRights := validCastlingRights(board, color, rights); validRights != rights {
+			dropped := rights
+			dropped.KingSide = rights.KingSide && !validRights.KingSide
+			dropped.QueenSide = rights.QueenSide && !validRights.QueenSide
+			add(InvalidCastlingRights, "castling %s needs a king and rook on their home squares", castlingText(board, color, dropped))
+		}
+	}
+
+	if position.EnPassantSquare != (game.ChessLocation{}) && !validEnPassantSquare(position) {
+		add(InvalidEnPassantSquare, "en passant square %s is not behind a pawn that has just moved two squares", position.EnPassantSquare)
+	}
+
+	return violations
+}
+
+// ParseFenStrict parses a FEN string and validates the position, returning a *ValidationError when it can't
+// occur in a game.
+func ParseFenStrict(fenString string) (game.ChessPosition, error) {
+	position, err := ParseFen(fenString)
+	if err != nil {
+		return game.ChessPosition{}, err
+	}
+	if violations := Validate(&position); len(violations) > 0 {
+		return game.ChessPosition{}, &ValidationError{Violations: violations}
+	}
+	return position, nil
+}
+
+// ParseFenLenient parses a FEN string from a source that may be careless, such as third party data. Castling
+// rights without their king or rook and an impossible en passant square are dropped and returned as the
+// repaired violations. Other violations can't be repaired and are returned as a *ValidationError.
+func ParseFenLenient(fenString string) (game.ChessPosition, []Violation, error) {
+	position, err := ParseFen(fenString)
+	if err != nil {
+		return game.ChessPosition{}, nil, err
+	}
+
+	var repaired, remaining []Violation
+	for _, violation := range Validate(&position) {
+		switch violation.Code {
+		case InvalidCastlingRights:
+			for color, rights := range position.CastlingRights {
+				position.CastlingRights[color] = validCastlingRights(position.Board, color, rights)
+			}
+			repaired = append(repaired, violation)
+		case InvalidEnPassantSquare:
+			position.EnPassantSquare = game.ChessLocation{}
+			repaired = append(repaired, violation)
+		default:
+			remaining = append(remaining, violation)
+		}
+	}
+	if len(remaining) > 0 {
+		return game.ChessPosition{}, repaired, &ValidationError{Violations: remaining}
+	}
+	return position, repaired, nil
+}
+
+// validCastlingRights drops the castling rights of a color whose king or rook is not on the back rank, or
+// whose rook is on the wrong side of the king.
+func validCastlingRights(board *game.ChessBoard, color game.ColorType, rights game.CastlingRights) game.CastlingRights {
+	rank := castlingRank(color)
+	kingFile := game.NoFile
+	if kings := board.PieceBitboard(game.ChessPiece{Piece: game.King, Color: color}); kings.Count() == 1 {
+		if king := game.LocationFromIndex(kings.First()); king.Rank == rank {
+			kingFile = king.File
+		}
+	}
+
+	rook := game.ChessPiece{Piece: game.Rook, Color: color}
+	hasRook := func(file game.FileType) bool {
+		return board.GetSquare(game.ChessLocation{File: file, Rank: rank}).Piece == rook
+	}
+	if rights.KingSide && (kingFile == game.NoFile || rights.KingSideRookFile() <= kingFile || !hasRook(rights.KingSideRookFile())) {
+		rights.KingSide, rights.KingSideRook = false, game.NoFile
+	}
+	if rights.QueenSide && (kingFile == game.NoFile || rights.QueenSideRookFile() >= kingFile || !hasRook(rights.QueenSideRookFile())) {
+		rights.QueenSide, rights.QueenSideRook = false, game.NoFile
+	}
+	return rights
+}
+
+// validEnPassantSquare returns true if the en passant square is empty on the sixth rank from the side to
+// move, with the square the pawn came from empty and the pawn of the side that just moved in front of it.
+func validEnPassantSquare(position *game.ChessPosition) bool {
+	square := position.EnPassantSquare
+	passedRank, fromRank, pawnRank := game.Rank6, game.Rank7, game.Rank5
+	if position.PlayerToMove == game.BlackPiece {
+		passedRank, fromRank, pawnRank = game.Rank3, game.Rank2, game.Rank4
+	}
+
+	board := position.Board
+	pawn := game.ChessPiece{Piece: game.Pawn, Color: position.PlayerToMove.OppositeColor()}
+	return square.Rank == passedRank &&
+		!board.HasPiece(square) &&
+		!board.HasPiece(game.ChessLocation{File: square.File, Rank: fromRank}) &&
+		board.GetSquare(game.ChessLocation{File: square.File, Rank: pawnRank}).Piece == pawn
+}
+
+// castlingText writes the castling rights of one color as they appear in a FEN.
+func castlingText(board *game.ChessBoard, color game.ColorType, rights game.CastlingRights) string {
+	serializer := NewFenSerializer()
+	serializer.WriteCastlingRights(board, rights, color)
+	return serializer.String()
+}
+
+func colorName(color game.ColorType) string {
+	if color == game.BlackPiece {
+		return "black"
+	}
+	return "white"
+}
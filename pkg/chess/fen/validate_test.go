@@ -0,0 +1,88 @@
+package fen
+
+import (
+	"testing"
+
+	"github.com/jerhon/chess/pkg/chess/game"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func violationCodes(violations []Violation) []ViolationCode {
+	codes := []ViolationCode{}
+	for _, violation := range violations {
+		codes = append(codes, violation.Code)
+	}
+	return codes
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		fen      string
+		expected []ViolationCode
+	}{
+		{"start position", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", []ViolationCode{}},
+		{"en passant after a double push", "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1", []ViolationCode{}},
+		{"chess960 castling", "bqnbrkrn/pppppppp/8/8/8/8/PPPPPPPP/BQNBRKRN w GEge - 0 1", []ViolationCode{}},
+		{"no kings", "8/8/8/8/8/8/8/8 w - - 0 1", []ViolationCode{MissingKing, MissingKing}},
+		{"two white kings", "4k3/8/8/8/8/8/8/3KK3 w - - 0 1", []ViolationCode{TooManyKings}},
+		{"nine pawns", "4k3/8/8/8/8/P7/PPPPPPPP/4K3 w - - 0 1", []ViolationCode{TooManyPawns}},
+		{"seventeen pieces", "4k3/8/8/8/7N/PPPPPPPP/RNBQKBNR/8 w - - 0 1", []ViolationCode{TooManyPieces}},
+		{"pawns on the back ranks", "3pk3/8/8/8/8/8/8/3PK3 w - - 0 1", []ViolationCode{PawnOnBackRank, PawnOnBackRank}},
+		{"side not to move in check", "4k3/8/8/8/8/8/8/4K2R b - - 0 1", []ViolationCode{}},
+		{"side that moved in check", "4k3/8/8/8/8/8/8/4R1K1 w - - 0 1", []ViolationCode{OpponentInCheck}},
+		{"castling without a rook", "4k3/8/8/8/8/8/8/4K3 w K - 0 1", []ViolationCode{InvalidCastlingRights}},
+		{"castling after the king moved", "r3k2r/8/8/8/8/8/4K3/R6R w KQkq - 0 1", []ViolationCode{InvalidCastlingRights}},
+		{"en passant without a pawn", "4k3/8/8/8/8/8/8/4K3 b - e3 0 1", []ViolationCode{InvalidEnPassantSquare}},
+		{"en passant on the wrong rank", "4k3/8/8/8/4P3/8/8/4K3 w - e3 0 1", []ViolationCode{InvalidEnPassantSquare}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			position, err := ParseFen(test.fen)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, violationCodes(Validate(&position)))
+		})
+	}
+}
+
+func TestValidate_Messages(t *testing.T) {
+	position, err := ParseFen("r3k3/8/8/8/8/8/4K3/R6R w KQq - 0 1")
+	require.NoError(t, err)
+
+	violations := Validate(&position)
+	require.Len(t, violations, 1)
+	assert.Equal(t, "castling KQ needs a king and rook on their home squares", violations[0].Message)
+	assert.Equal(t, "invalid castling rights", violations[0].Code.String())
+}
+
+func TestParseFenStrict(t *testing.T) {
+	position, err := ParseFenStrict("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1")
+	require.NoError(t, err)
+	assert.Equal(t, game.NewStandardStartingPosition().Hash(), position.Hash())
+
+	_, err = ParseFenStrict("8/8/8/8/8/8/8/4K3 w K - 0 1")
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.True(t, validationErr.Has(MissingKing))
+	assert.True(t, validationErr.Has(InvalidCastlingRights))
+	assert.False(t, validationErr.Has(PawnOnBackRank))
+	assert.EqualError(t, err, "invalid position: black has no king; castling K needs a king and rook on their home squares")
+
+	_, err = ParseFenStrict("4k3/8/8/8/8/8/8/4K3 x - - 0 1")
+	assert.ErrorContains(t, err, "player to move")
+}
+
+func TestParseFenLenient(t *testing.T) {
+	position, repaired, err := ParseFenLenient("r3k3/8/8/8/8/8/8/4K2R w KQkq e6 0 1")
+	require.NoError(t, err)
+	assert.Equal(t, []ViolationCode{InvalidCastlingRights, InvalidCastlingRights, InvalidEnPassantSquare}, violationCodes(repaired))
+	assert.Equal(t, "r3k3/8/8/8/8/8/8/4K2R w Kq - 0 1", ToFenString(&position))
+	assert.Empty(t, Validate(&position))
+
+	_, repaired, err = ParseFenLenient("4k3/8/8/8/8/8/8/4R1K1 w K - 0 1")
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, []ViolationCode{OpponentInCheck}, violationCodes(validationErr.Violations))
+	assert.Equal(t, []ViolationCode{InvalidCastlingRights}, violationCodes(repaired))
+}
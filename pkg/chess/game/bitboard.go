@@ -0,0 +1,233 @@
+package game
+
+import "math/bits"
+
+// Bitboard is a set of squares packed into a 64 bit integer.
+// Bit 0 is a1, bit 7 is h1, bit 56 is a8 and bit 63 is h8.
+type Bitboard uint64
+
+const (
+	fileABitboard Bitboard = 0x0101010101010101
+	fileHBitboard Bitboard = fileABitboard << 7
+	rank1Bitboard Bitboard = 0xFF
+	rank8Bitboard Bitboard = rank1Bitboard << 56
+)
+
+// Has returns true if the square index is a member of the bitboard.
+func (b Bitboard) Has(index int) bool {
+	return b&(1<<uint(index)) != 0
+}
+
+// Count returns the number of squares in the bitboard.
+func (b Bitboard) Count() int {
+	return bits.OnesCount64(uint64(b))
+}
+
+// First returns the lowest square index in the bitboard, or 64 if it is empty.
+func (b Bitboard) First() int {
+	return bits.TrailingZeros64(uint64(b))
+}
+
+// Squares iterates the square indexes contained in the bitboard from a1 to h8.
+func (b Bitboard) Squares(yield func(int) bool) {
+	for b != 0 {
+		index := b.First()
+		b &= b - 1
+		if !yield(index) {
+			return
+		}
+	}
+}
+
+// squareBit returns a bitboard containing only the given square index.
+func squareBit(index int) Bitboard {
+	return Bitboard(1) << uint(index)
+}
+
+// ToIndex returns the 0-63 square index of a location, a1 being 0 and h8 being 63.
+func (location ChessLocation) ToIndex() int {
+	return location.Rank.ToIndex()*8 + location.File.ToIndex()
+}
+
+// LocationFromIndex converts a 0-63 square index back into a ChessLocation.
+func LocationFromIndex(index int) ChessLocation {
+	return ChessLocation{File: FileA + FileType(index%8), Rank: Rank1 + RankType(index/8)}
+}
+
+// Precomputed attack tables for the leaping pieces.
+var (
+	knightAttackTable [64]Bitboard
+	kingAttackTable   [64]Bitboard
+	pawnAttackTable   [2][64]Bitboard
+)
+
+// betweenTable holds the squares strictly between two squares on a shared line, lineTable the
+// full line through both squares. Both are empty when the squares do not share a rank, file or diagonal.
+var (
+	betweenTable [64][64]Bitboard
+	lineTable    [64][64]Bitboard
+)
+
+var rookDirections = [4][2]int{{0, 1}, {0, -1}, {1, 0}, {-1, 0}}
+var bishopDirections = [4][2]int{{1, 1}, {1, -1}, {-1, 1}, {-1, -1}}
+
+func init() {
+	initLeaperAttacks()
+	initMagics(&rookMagics, rookDirections, &rookMagicNumbers)
+	initMagics(&bishopMagics, bishopDirections, &bishopMagicNumbers)
+	initLines()
+}
+
+// colorIndex maps a color to the index used by the bitboard tables.
+func colorIndex(color ColorType) int {
+	if color == BlackPiece {
+		return 1
+	}
+	return 0
+}
+
+func onBoard(file int, rank int) bool {
+	return file >= 0 && file < 8 && rank >= 0 && rank < 8
+}
+
+func initLeaperAttacks() {
+	knightOffsets := [8][2]int{{1, 2}, {2, 1}, {2, -1}, {1, -2}, {-1, -2}, {-2, -1}, {-2, 1}, {-1, 2}}
+	for index := 0; index < 64; index++ {
+		file, rank := index%8, index/8
+		for _, offset := range knightOffsets {
+			if onBoard(file+offset[0], rank+offset[1]) {
+				knightAttackTable[index] |= squareBit((rank+offset[1])*8 + file + offset[0])
+			}
+		}
+		for fileOffset := -1; fileOffset <= 1; fileOffset++ {
+			for rankOffset := -1; rankOffset <= 1; rankOffset++ {
+				if (fileOffset != 0 || rankOffset != 0) && onBoard(file+fileOffset, rank+rankOffset) {
+					kingAttackTable[index] |= squareBit((rank+rankOffset)*8 + file + fileOffset)
+				}
+			}
+		}
+		for _, fileOffset := range []int{-1, 1} {
+			if onBoard(file+fileOffset, rank+1) {
+				pawnAttackTable[0][index] |= squareBit((rank+1)*8 + file + fileOffset)
+			}
+			if onBoard(file+fileOffset, rank-1) {
+				pawnAttackTable[1][index] |= squareBit((rank-1)*8 + file + fileOffset)
+			}
+		}
+	}
+}
+
+// slidingAttacks walks each direction from a square until it leaves the board or hits an occupied square.
+// It is only used to build the magic tables.
+func slidingAttacks(index int, occupied Bitboard, directions [4][2]int) Bitboard {
+	attacks := Bitboard(0)
+	for _, direction := range directions {
+		file, rank := index%8+direction[0], index/8+direction[1]
+		for onBoard(file, rank) {
+			attacks |= squareBit(rank*8 + file)
+			if occupied.Has(rank*8 + file) {
+				break
+			}
+			file, rank = file+direction[0], rank+direction[1]
+		}
+	}
+	return attacks
+}
+
+func initLines() {
+	for from := 0; from < 64; from++ {
+		for to := 0; to < 64; to++ {
+			if from == to {
+				continue
+			}
+			for _, directions := range [][4][2]int{rookDirections, bishopDirections} {
+				if slidingAttacks(from, 0, directions).Has(to) {
+					betweenTable[from][to] = slidingAttacks(from, squareBit(to), directions) & slidingAttacks(to, squareBit(from), directions)
+					lineTable[from][to] = (slidingAttacks(from, 0, directions) & slidingAttacks(to, 0, directions)) | squareBit(from) | squareBit(to)
+				}
+			}
+		}
+	}
+}
+
+// magicEntry holds the magic bitboard lookup data for one square.
+type magicEntry struct {
+	mask    Bitboard
+	magic   uint64
+	shift   uint
+	attacks []Bitboard
+}
+
+func (m *magicEntry) index(occupied Bitboard) uint64 {
+	return (uint64(occupied&m.mask) * m.magic) >> m.shift
+}
+
+var (
+	rookMagics   [64]magicEntry
+	bishopMagics [64]magicEntry
+)
+
+// initMagics builds the attack lookup for every square from the fixed magic multipliers in magics.go.
+func initMagics(table *[64]magicEntry, directions [4][2]int, magics *[64]uint64) {
+	for index := 0; index < 64; index++ {
+		file, rank := index%8, index/8
+		// Edge squares never block a ray so they are excluded from the relevant occupancy.
+		edges := ((rank1Bitboard | rank8Bitboard) &^ (rank1Bitboard << (8 * uint(rank)))) |
+			((fileABitboard | fileHBitboard) &^ (fileABitboard << uint(file)))
+		mask := slidingAttacks(index, 0, directions) &^ edges
+		bitCount := mask.Count()
+
+		entry := magicEntry{mask: mask, magic: magics[index], shift: uint(64 - bitCount), attacks: make([]Bitboard, 1<<bitCount)}
+
+		// walk every subset of the mask and store its attacks in the slot the magic maps it to
+		subset := Bitboard(0)
+		for {
+			entry.attacks[entry.index(subset)] = slidingAttacks(index, subset, directions)
+			subset = (subset - mask) & mask
+			if subset == 0 {
+				break
+			}
+		}
+		table[index] = entry
+	}
+}
+
+func rookAttacks(index int, occupied Bitboard) Bitboard {
+	entry := &rookMagics[index]
+	return entry.attacks[entry.index(occupied)]
+}
+
+func bishopAttacks(index int, occupied Bitboard) Bitboard {
+	entry := &bishopMagics[index]
+	return entry.attacks[entry.index(occupied)]
+}
+
+func queenAttacks(index int, occupied Bitboard) Bitboard {
+	return rookAttacks(index, occupied) | bishopAttacks(index, occupied)
+}
+
+// PieceAttacks returns the squares a piece on the given square index attacks, with sliding pieces blocked by
+// the occupied squares. A pawn attacks the two squares diagonally in front of it.
+func PieceAttacks(piece ChessPiece, index int, occupied Bitboard) Bitboard {
+	return pieceAttacks(piece, index, occupied)
+}
+
+// pieceAttacks returns the squares a piece on the given index attacks with the given occupancy.
+func pieceAttacks(piece ChessPiece, index int, occupied Bitboard) Bitboard {
+	switch piece.Piece {
+	case Pawn:
+		return pawnAttackTable[colorIndex(piece.Color)][index]
+	case Knight:
+		return knightAttackTable[index]
+	case Bishop:
+		return bishopAttacks(index, occupied)
+	case Rook:
+		return rookAttacks(index, occupied)
+	case Queen:
+		return queenAttacks(index, occupied)
+	case King:
+		return kingAttackTable[index]
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,46 @@
+package game
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocationFromIndex_RoundTrip(t *testing.T) {
+	for index := 0; index < 64; index++ {
+		location := LocationFromIndex(index)
+		assert.True(t, location.IsOnBoard())
+		assert.Equal(t, index, location.ToIndex())
+	}
+	assert.Equal(t, ChessLocation{FileA, Rank1}, LocationFromIndex(0))
+	assert.Equal(t, ChessLocation{FileH, Rank8}, LocationFromIndex(63))
+}
+
+func TestMagicAttacks_MatchSlidingAttacks(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	for index := 0; index < 64; index++ {
+		for i := 0; i < 200; i++ {
+			occupied := Bitboard(random.Uint64() & random.Uint64())
+			assert.Equal(t, slidingAttacks(index, occupied, rookDirections), rookAttacks(index, occupied))
+			assert.Equal(t, slidingAttacks(index, occupied, bishopDirections), bishopAttacks(index, occupied))
+		}
+	}
+}
+
+func TestChessBoard_Bitboards(t *testing.T) {
+	board := NewStandardChessBoard()
+	assert.Equal(t, 32, board.Occupied().Count())
+	assert.Equal(t, 16, board.ColorBitboard(WhitePiece).Count())
+	assert.Equal(t, Bitboard(0xFF00), board.PieceBitboard(ChessPiece{Pawn, WhitePiece}))
+
+	board.SetSquare(l(FileE, Rank2), ChessPiece{NoPiece, NoColor})
+	board.SetSquare(l(FileE, Rank4), p(Pawn, WhitePiece))
+	assert.False(t, board.PieceBitboard(ChessPiece{Pawn, WhitePiece}).Has(l(FileE, Rank2).ToIndex()))
+	assert.True(t, board.PieceBitboard(ChessPiece{Pawn, WhitePiece}).Has(l(FileE, Rank4).ToIndex()))
+
+	// replacing a piece removes the old piece from its bitboard
+	board.SetSquare(l(FileE, Rank4), p(Knight, BlackPiece))
+	assert.False(t, board.ColorBitboard(WhitePiece).Has(l(FileE, Rank4).ToIndex()))
+	assert.True(t, board.PieceBitboard(ChessPiece{Knight, BlackPiece}).Has(l(FileE, Rank4).ToIndex()))
+}
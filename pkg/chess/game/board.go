@@ -5,28 +5,67 @@ import (
 	"strings"
 )
 
+// ChessBoard stores the pieces on the board as bitboards, one per color and piece type,
+// alongside a square lookup so single squares can be read without scanning the bitboards.
 type ChessBoard struct {
-	squares map[string]ChessSquare
+	colors  [2]Bitboard
+	pieces  [6]Bitboard
+	squares [64]ChessPiece
 }
 
-func (b ChessBoard) GetSquare(location ChessLocation) ChessSquare {
-	value, exists := b.squares[location.String()]
-	if exists {
-		return value
-	} else {
+// pieceIndex maps a piece type to the index used by the piece bitboards.
+func pieceIndex(piece PieceType) int {
+	switch piece {
+	case Pawn:
+		return 0
+	case Knight:
+		return 1
+	case Bishop:
+		return 2
+	case Rook:
+		return 3
+	case Queen:
+		return 4
+	case King:
+		return 5
+	default:
+		return -1
+	}
+}
+
+func (b *ChessBoard) GetSquare(location ChessLocation) ChessSquare {
+	if !location.IsOnBoard() {
 		return ChessSquare{location, ChessPiece{NoPiece, NoColor}}
 	}
+	return ChessSquare{location, b.squares[location.ToIndex()]}
 }
 
-func (b ChessBoard) ClearSquare(location ChessLocation) {
-	delete(b.squares, location.String())
+func (b *ChessBoard) ClearSquare(location ChessLocation) {
+	if !location.IsOnBoard() {
+		return
+	}
+	index := location.ToIndex()
+	existing := b.squares[index]
+	if existing.Piece == NoPiece {
+		return
+	}
+	b.colors[colorIndex(existing.Color)] &^= squareBit(index)
+	b.pieces[pieceIndex(existing.Piece)] &^= squareBit(index)
+	b.squares[index] = ChessPiece{}
 }
 
-func (b ChessBoard) SetSquare(location ChessLocation, piece ChessPiece) {
-	b.squares[location.String()] = ChessSquare{location, piece}
+func (b *ChessBoard) SetSquare(location ChessLocation, piece ChessPiece) {
+	b.ClearSquare(location)
+	if !location.IsOnBoard() || !piece.Piece.IsPiece() {
+		return
+	}
+	index := location.ToIndex()
+	b.colors[colorIndex(piece.Color)] |= squareBit(index)
+	b.pieces[pieceIndex(piece.Piece)] |= squareBit(index)
+	b.squares[index] = piece
 }
 
-func (b ChessBoard) IterateSquares() iter.Seq[ChessSquare] {
+func (b *ChessBoard) IterateSquares() iter.Seq[ChessSquare] {
 	return func(yield func(square ChessSquare) bool) {
 		for rank := Rank8; rank >= Rank1; rank-- {
 			for file := FileA; file <= FileH; file++ {
@@ -38,8 +77,30 @@ func (b ChessBoard) IterateSquares() iter.Seq[ChessSquare] {
 	}
 }
 
+// Occupied returns the bitboard of every square holding a piece.
+func (b *ChessBoard) Occupied() Bitboard {
+	return b.colors[0] | b.colors[1]
+}
+
+// ColorBitboard returns the bitboard of the squares holding pieces of the given color.
+func (b *ChessBoard) ColorBitboard(color ColorType) Bitboard {
+	if color != WhitePiece && color != BlackPiece {
+		return 0
+	}
+	return b.colors[colorIndex(color)]
+}
+
+// PieceBitboard returns the bitboard of the squares holding the given piece and color.
+func (b *ChessBoard) PieceBitboard(piece ChessPiece) Bitboard {
+	index := pieceIndex(piece.Piece)
+	if index < 0 {
+		return 0
+	}
+	return b.pieces[index] & b.ColorBitboard(piece.Color)
+}
+
 // / String creates the string representation of a chess game
-func (b ChessBoard) String() string {
+func (b *ChessBoard) String() string {
 	sb := strings.Builder{}
 
 	for square := range b.IterateSquares() {
@@ -65,24 +126,22 @@ func (b ChessBoard) String() string {
 }
 
 func NewChessBoard() *ChessBoard {
-	board := &ChessBoard{squares: make(map[string]ChessSquare)}
+	board := &ChessBoard{}
 	return board
 }
 
-func (board ChessBoard) HasPiece(location ChessLocation) bool {
-	return board.squares[location.String()].Piece.Piece != NoPiece
+func (board *ChessBoard) HasPiece(location ChessLocation) bool {
+	return board.GetSquare(location).Piece.Piece != NoPiece
 }
 
-func (board ChessBoard) GetPiece(location ChessLocation) (ChessPiece, bool) {
-	return board.squares[location.String()].Piece, board.squares[location.String()].Piece.Piece != NoPiece
+func (board *ChessBoard) GetPiece(location ChessLocation) (ChessPiece, bool) {
+	piece := board.GetSquare(location).Piece
+	return piece, piece.Piece != NoPiece
 }
 
-func (board ChessBoard) Clone() *ChessBoard {
-	newBoard := NewChessBoard()
-	for location, square := range board.squares {
-		newBoard.squares[location] = square
-	}
-	return newBoard
+func (board *ChessBoard) Clone() *ChessBoard {
+	newBoard := *board
+	return &newBoard
 }
 
 // NewStandardChessBoard returns a board in a standard setup
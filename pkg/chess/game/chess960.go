@@ -0,0 +1,88 @@
+package game
+
+import "fmt"
+
+// StandardChess960Number is the Chess960 starting position that is the standard starting position.
+const StandardChess960Number = 518
+
+// chess960Knights lists the files, among the five left once the bishops and queen are placed, that the
+// knights take for each knight number of the Scharnagl numbering.
+var chess960Knights = [10][2]int{{0, 1}, {0, 2}, {0, 3}, {0, 4}, {1, 2}, {1, 3}, {1, 4}, {2, 3}, {2, 4}, {3, 4}}
+
+// Chess960BackRank returns the pieces of the back rank from the a file to the h file for a Chess960
+// starting position numbered 0 to 959 by Scharnagl's scheme, in which 518 is the standard position.
+func Chess960BackRank(number int) ([8]PieceType, error) {
+	backRank := [8]PieceType{}
+	if number < 0 || number > 959 {
+		return backRank, fmt.Errorf("chess960 position %d is out of range, expected 0 to 959", number)
+	}
+
+	// the light squared bishop takes the b, d, f or h file and the dark squared bishop the a, c, e or g file
+	backRank[number%4*2+1] = Bishop
+	number /= 4
+	backRank[number%4*2] = Bishop
+	number /= 4
+
+	// the queen, knights and then rook, king and rook fill the empty files from left to right
+	empty := func() []int {
+		files := []int{}
+		for file, piece := range backRank {
+			if piece == NoPiece {
+				files = append(files, file)
+			}
+		}
+		return files
+	}
+	backRank[empty()[number%6]] = Queen
+	number /= 6
+
+	files := empty()
+	for _, knight := range chess960Knights[number] {
+		backRank[files[knight]] = Knight
+	}
+	for i, file := range empty() {
+		backRank[file] = []PieceType{Rook, King, Rook}[i]
+	}
+	return backRank, nil
+}
+
+// NewChess960StartingPosition returns the Chess960 starting position with the given Scharnagl number, both
+// sides able to castle with either rook.
+func NewChess960StartingPosition(number int) (*ChessPosition, error) {
+	backRank, err := Chess960BackRank(number)
+	if err != nil {
+		return nil, err
+	}
+
+	board := NewChessBoard()
+	rights := CastlingRights{KingSide: true, QueenSide: true}
+	rookSeen := false
+	for i, piece := range backRank {
+		file := FileA + FileType(i)
+		board.SetSquare(ChessLocation{File: file, Rank: Rank1}, ChessPiece{piece, WhitePiece})
+		board.SetSquare(ChessLocation{File: file, Rank: Rank2}, ChessPiece{Pawn, WhitePiece})
+		board.SetSquare(ChessLocation{File: file, Rank: Rank7}, ChessPiece{Pawn, BlackPiece})
+		board.SetSquare(ChessLocation{File: file, Rank: Rank8}, ChessPiece{piece, BlackPiece})
+
+		if piece == Rook && !rookSeen {
+			rights.QueenSideRook = file
+			rookSeen = true
+		} else if piece == Rook {
+			rights.KingSideRook = file
+		}
+	}
+	// the rooks of standard chess are kept as NoFile
+	if rights.QueenSideRook == FileA {
+		rights.QueenSideRook = NoFile
+	}
+	if rights.KingSideRook == FileH {
+		rights.KingSideRook = NoFile
+	}
+
+	return &ChessPosition{
+		Board:          board,
+		PlayerToMove:   WhitePiece,
+		CastlingRights: map[ColorType]CastlingRights{WhitePiece: rights, BlackPiece: rights},
+		FullmoveNumber: 1,
+	}, nil
+}
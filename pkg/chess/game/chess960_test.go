@@ -0,0 +1,79 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func backRankString(backRank [8]PieceType) string {
+	text := ""
+	for _, piece := range backRank {
+		text += string(piece)
+	}
+	return text
+}
+
+func TestChess960BackRank(t *testing.T) {
+	tests := map[int]string{
+		0:   "BBQNNRKR",
+		1:   "BQNBNRKR",
+		518: "RNBQKBNR",
+		959: "RKRNNQBB",
+	}
+	for number, expected := range tests {
+		backRank, err := Chess960BackRank(number)
+		require.NoError(t, err)
+		assert.Equal(t, expected, backRankString(backRank), "position %d", number)
+	}
+}
+
+func TestChess960BackRank_EveryPositionIsDistinctAndValid(t *testing.T) {
+	seen := map[string]bool{}
+	for number := 0; number < 960; number++ {
+		backRank, err := Chess960BackRank(number)
+		require.NoError(t, err)
+
+		text := backRankString(backRank)
+		assert.False(t, seen[text], "position %d repeats %s", number, text)
+		seen[text] = true
+
+		bishops, rooks, king := []int{}, []int{}, -1
+		for file, piece := range backRank {
+			switch piece {
+			case Bishop:
+				bishops = append(bishops, file)
+			case Rook:
+				rooks = append(rooks, file)
+			case King:
+				king = file
+			}
+		}
+		require.Len(t, bishops, 2)
+		require.Len(t, rooks, 2)
+		assert.NotEqual(t, bishops[0]%2, bishops[1]%2, "bishops of position %d share a color", number)
+		assert.True(t, rooks[0] < king && king < rooks[1], "king of position %d is not between the rooks", number)
+	}
+}
+
+func TestChess960BackRank_OutOfRange(t *testing.T) {
+	_, err := Chess960BackRank(960)
+	assert.Error(t, err)
+	_, err = Chess960BackRank(-1)
+	assert.Error(t, err)
+}
+
+func TestNewChess960StartingPosition(t *testing.T) {
+	standard, err := NewChess960StartingPosition(StandardChess960Number)
+	require.NoError(t, err)
+	assert.Equal(t, NewStandardStartingPosition().Hash(), standard.Hash())
+	assert.Equal(t, NewStandardStartingPosition().CastlingRights, standard.CastlingRights)
+
+	position, err := NewChess960StartingPosition(0)
+	require.NoError(t, err)
+	rights := CastlingRights{KingSide: true, QueenSide: true, QueenSideRook: FileF}
+	assert.Equal(t, map[ColorType]CastlingRights{WhitePiece: rights, BlackPiece: rights}, position.CastlingRights)
+	assert.Equal(t, ChessPiece{Bishop, BlackPiece}, position.Board.GetSquare(ChessLocation{FileA, Rank8}).Piece)
+	assert.Equal(t, ChessPiece{King, WhitePiece}, position.Board.GetSquare(ChessLocation{FileG, Rank1}).Piece)
+}
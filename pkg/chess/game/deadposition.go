@@ -0,0 +1,157 @@
+package game
+
+// lightSquares holds the light squares of the board, a1 being dark.
+const lightSquares Bitboard = 0x55AA55AA55AA55AA
+
+// DeadPositionAnalysis tells which sides could still checkmate with any series of legal moves, the opponent
+// helping. A position where neither side can is dead and the game is drawn.
+type DeadPositionAnalysis struct {
+	// WhiteCanMate and BlackCanMate are false when the side can never checkmate its opponent
+	WhiteCanMate bool
+	BlackCanMate bool
+	// Locked is true when only kings and blocked pawns are left and neither king can reach a pawn it can take
+	Locked bool
+}
+
+// CanMate returns true when color could still checkmate its opponent. A player without mating potential
+// whose opponent runs out of time draws rather than wins.
+func (analysis DeadPositionAnalysis) CanMate(color ColorType) bool {
+	switch color {
+	case WhitePiece:
+		return analysis.WhiteCanMate
+	case BlackPiece:
+		return analysis.BlackCanMate
+	default:
+		return false
+	}
+}
+
+// IsDead returns true when neither side can checkmate.
+func (analysis DeadPositionAnalysis) IsDead() bool {
+	return !analysis.WhiteCanMate && !analysis.BlackCanMate
+}
+
+// AnalyzeDeadPosition returns which sides of a position have mating potential. Besides the material of each
+// side it recognises locked pawn structures, where every pawn is blocked and the kings are walled off from the
+// pawns they could take. An en passant capture the player to move can make opens a locked structure.
+func AnalyzeDeadPosition(position *ChessPosition) DeadPositionAnalysis {
+	board := position.Board
+	analysis := DeadPositionAnalysis{
+		WhiteCanMate: hasMatingMaterial(board, WhitePiece),
+		BlackCanMate: hasMatingMaterial(board, BlackPiece),
+	}
+	if !analysis.IsDead() && isLocked(board) && !canCaptureEnPassant(position) {
+		return DeadPositionAnalysis{Locked: true}
+	}
+	return analysis
+}
+
+// hasMatingMaterial returns true when color has the material to checkmate, given the pieces the opponent has
+// left to block its own king.
+func hasMatingMaterial(board *ChessBoard, color ColorType) bool {
+	own := func(piece PieceType) Bitboard { return board.PieceBitboard(ChessPiece{Piece: piece, Color: color}) }
+	if own(Pawn)|own(Rook)|own(Queen) != 0 {
+		return true
+	}
+
+	knights, bishops := own(Knight), own(Bishop)
+	switch {
+	case knights == 0 && bishops == 0:
+		return false
+	case knights.Count() >= 2 || (knights != 0 && bishops != 0):
+		// two knights or a knight and a bishop mate with the king in a corner
+		return true
+	case bishops&lightSquares != 0 && bishops&^lightSquares != 0:
+		return true
+	}
+
+	// A lone knight, or bishops all on one color, mate only when an opposing piece takes a flight square
+	// from the king. Opposing bishops on the squares of the own bishops never block a square those attack.
+	opponent := board.ColorBitboard(color.OppositeColor())
+	opponent &^= board.PieceBitboard(ChessPiece{Piece: King, Color: color.OppositeColor()})
+	if bishops != 0 {
+		opposingBishops := board.PieceBitboard(ChessPiece{Piece: Bishop, Color: color.OppositeColor()})
+		sameColor := lightSquares
+		if bishops&lightSquares == 0 {
+			sameColor = ^lightSquares
+		}
+		opponent &^= opposingBishops & sameColor
+	}
+	return opponent != 0
+}
+
+// isLocked returns true when only kings and pawns are left, no pawn can ever move or capture and no king can
+// take a pawn, so the kings walk around forever. The opponent may help, so a king only stays out of the
+// squares the opposing pawns attack.
+func isLocked(board *ChessBoard) bool {
+	whitePawns := board.PieceBitboard(ChessPiece{Piece: Pawn, Color: WhitePiece})
+	blackPawns := board.PieceBitboard(ChessPiece{Piece: Pawn, Color: BlackPiece})
+	pawns := whitePawns | blackPawns
+	kings := board.PieceBitboard(ChessPiece{Piece: King, Color: WhitePiece}) |
+		board.PieceBitboard(ChessPiece{Piece: King, Color: BlackPiece})
+	if pawns == 0 || board.Occupied() != pawns|kings {
+		return false
+	}
+
+	// every pawn stands right behind another pawn, so none can push
+	if (whitePawns<<8)&^pawns != 0 || (blackPawns>>8)&^pawns != 0 {
+		return false
+	}
+	whiteAttacks, blackAttacks := pawnAttacks(whitePawns, WhitePiece), pawnAttacks(blackPawns, BlackPiece)
+	if whiteAttacks&blackPawns != 0 || blackAttacks&whitePawns != 0 {
+		return false
+	}
+
+	for _, color := range []ColorType{WhitePiece, BlackPiece} {
+		opposingPawns, opposingAttacks := blackPawns, blackAttacks
+		if color == BlackPiece {
+			opposingPawns, opposingAttacks = whitePawns, whiteAttacks
+		}
+		king := board.PieceBitboard(ChessPiece{Piece: King, Color: color})
+		region := kingRegion(king, ^(pawns | opposingAttacks))
+		// an opposing pawn no other opposing pawn defends can be taken
+		if kingSpread(region)&opposingPawns&^opposingAttacks != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// canCaptureEnPassant returns true when the player to move has a legal en passant capture.
+func canCaptureEnPassant(position *ChessPosition) bool {
+	kingIndex := -1
+	if kings := position.Board.PieceBitboard(ChessPiece{King, position.PlayerToMove}); kings != 0 {
+		kingIndex = kings.First()
+	}
+	return len((&ChessMovement{Position: position}).enPassantMoves(kingIndex)) > 0
+}
+
+// pawnAttacks returns the squares the pawns of a color attack.
+func pawnAttacks(pawns Bitboard, color ColorType) Bitboard {
+	attacks := Bitboard(0)
+	for index := range pawns.Squares {
+		attacks |= pawnAttackTable[colorIndex(color)][index]
+	}
+	return attacks
+}
+
+// kingRegion returns the squares a king can walk to from its squares through the passable squares.
+func kingRegion(king Bitboard, passable Bitboard) Bitboard {
+	region := king
+	for {
+		next := region | kingSpread(region)&passable
+		if next == region {
+			return region
+		}
+		region = next
+	}
+}
+
+// kingSpread returns the squares a king attacks from any of the squares.
+func kingSpread(squares Bitboard) Bitboard {
+	spread := Bitboard(0)
+	for index := range squares.Squares {
+		spread |= kingAttackTable[index]
+	}
+	return spread
+}
@@ -0,0 +1,162 @@
+package game
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// lockedWall is a pawn wall across the board that neither king can pass, white's king below it and black's
+// above it.
+const lockedWall = "Ke1 ke8 Pa3 Pb4 Pc3 Pd4 Pe3 Pf4 Pg3 Ph4 pa4 pb5 pc4 pd5 pe4 pf5 pg4 ph5"
+
+// enPassantWall is a locked wall where black's d-pawn stands beside white's e-pawn, as after d7-d5.
+const enPassantWall = "Ke1 ke8 Pa3 Pb4 Pc3 Pd4 Pe5 Pf4 Pg3 Ph4 pa4 pb5 pc4 pd5 pe6 pf5 pg4 ph5"
+
+// positionOf returns the position of board with white to move and the en passant square.
+func positionOf(board string, enPassant ChessLocation) *ChessPosition {
+	return &ChessPosition{
+		Board:           parseBoard(board),
+		PlayerToMove:    WhitePiece,
+		CastlingRights:  map[ColorType]CastlingRights{WhitePiece: {}, BlackPiece: {}},
+		EnPassantSquare: enPassant,
+	}
+}
+
+func TestAnalyzeDeadPosition(t *testing.T) {
+	tests := []struct {
+		name         string
+		board        string
+		whiteCanMate bool
+		blackCanMate bool
+		locked       bool
+	}{
+		// material
+		{"kings only", "Ke1 ke8", false, false, false},
+		{"lone bishop", "Ke1 Bc1 ke8", false, false, false},
+		{"lone knight", "Ke1 Ng1 ke8", false, false, false},
+		{"two knights", "Ke1 Nb1 Ng1 ke8", true, false, false},
+		{"bishop and knight", "Ke1 Bc1 Ng1 ke8", true, false, false},
+		{"bishops on both colors", "Ke1 Bc1 Bf1 ke8", true, false, false},
+		{"three bishops on dark squares", "Ke1 Bc1 Ba3 Be3 ke8", false, false, false},
+		{"bishops on the same color", "Ke1 Bc1 ke8 bf8", false, false, false},
+		{"many bishops on the same color", "Ke1 Bc1 Ba3 ke8 bf8 bh6", false, false, false},
+		{"bishops on opposite colors", "Ke1 Bc1 ke8 bc8", true, true, false},
+		{"knight against knight", "Ke1 Ng1 ke8 nb8", true, true, false},
+		{"knight against bishop", "Ke1 Ng1 ke8 bc8", true, true, false},
+		{"knight against pawn", "Ke1 Ng1 ke8 pa7", true, true, false},
+		{"bishop against rook", "Ke1 Bc1 ke8 ra8", true, true, false},
+		{"knight against queen", "Ke1 Qd1 ke8 nb8", true, true, false},
+		{"rook", "Ke1 Ra1 ke8", true, false, false},
+		{"queen", "Ke1 ke8 qd8", false, true, false},
+		{"pawn", "Ke1 Pe2 ke8", true, false, false},
+
+		// locked structures
+		{"locked wall", lockedWall, false, false, true},
+		{"locked wall with a doubled pawn", lockedWall + " Pa2", false, false, true},
+		{"locked wall with a knight", lockedWall + " Nb1", true, true, false},
+		{"wall with a pawn free to push", "Ke1 ke8 Pa3 Pb4 Pc3 Pd4 Pe3 Pf4 Pg3 Ph4 pa4 pc4 pd5 pe4 pf5 pg4 ph5", true, true, false},
+		{"pawn can capture", "Ke1 ke8 Pd4 Pe4 pd5 pe5", true, true, false},
+		{"king reaches an undefended pawn", "Ke1 ke8 Pd4 pd5", true, true, false},
+		{"king walks around a chain", "Kh1 kh8 Pc3 Pd4 pc4 pd5", true, true, false},
+		{"king walks around the end of the wall", "Ke1 ke8 Pc3 Pd4 Pe3 Pf4 Pg3 Ph4 pc4 pd5 pe4 pf5 pg4 ph5", true, true, false},
+		{"king walks around the wall to the far side", "Kh1 ka8 Pc3 Pd4 Pe3 Pf4 Pg3 Ph4 pc4 pd5 pe4 pf5 pg4 ph5", true, true, false},
+		{"gap at the end of the wall guarded by pawns", "Kh1 ka8 Pa3 Pb4 Pc3 Pd4 Pe3 Pf4 Pg3 pa4 pb5 pc4 pd5 pe4 pf5 pg4", false, false, true},
+		{"gap in the wall guarded by pawns", "Ke1 ke8 Pb4 Pc3 Pd4 Pe3 Pf4 Pg3 Ph4 pb5 pc4 pd5 pe4 pf5 pg4 ph5", false, false, true},
+		{"pawns side by side", enPassantWall, false, false, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			analysis := AnalyzeDeadPosition(positionOf(test.board, ChessLocation{}))
+			assert.Equal(t, test.whiteCanMate, analysis.WhiteCanMate, "white can mate")
+			assert.Equal(t, test.blackCanMate, analysis.BlackCanMate, "black can mate")
+			assert.Equal(t, test.locked, analysis.Locked, "locked")
+			assert.Equal(t, !test.whiteCanMate && !test.blackCanMate, analysis.IsDead())
+		})
+	}
+}
+
+func TestDeadPositionAnalysis_CanMate(t *testing.T) {
+	// a flag fall against the lone knight is a draw, against the rook a win
+	analysis := AnalyzeDeadPosition(positionOf("Ke1 Ra1 ke8 nb8", ChessLocation{}))
+	assert.True(t, analysis.CanMate(WhitePiece))
+	assert.True(t, analysis.CanMate(BlackPiece))
+
+	analysis = AnalyzeDeadPosition(positionOf("Ke1 Ra1 ke8 bc8 bf8", ChessLocation{}))
+	assert.True(t, analysis.CanMate(BlackPiece))
+	analysis = AnalyzeDeadPosition(positionOf("Ke1 Ng1 ke8 bc8 be6", ChessLocation{}))
+	assert.True(t, analysis.CanMate(BlackPiece))
+
+	analysis = AnalyzeDeadPosition(positionOf("Ke1 Ra1 ke8 bc8", ChessLocation{}))
+	assert.True(t, analysis.CanMate(BlackPiece))
+	analysis = AnalyzeDeadPosition(positionOf("Ke1 Ra1 ke8", ChessLocation{}))
+	assert.False(t, analysis.CanMate(BlackPiece))
+	assert.False(t, analysis.CanMate(NoColor))
+}
+
+func TestAnalyzeDeadPosition_EnPassant(t *testing.T) {
+	d6 := ChessLocation{File: FileD, Rank: Rank6}
+	tests := []struct {
+		name      string
+		board     string
+		enPassant ChessLocation
+		canMate   bool
+	}{
+		{"en passant capture", enPassantWall, d6, true},
+		{"no en passant square", enPassantWall, ChessLocation{}, false},
+		{"no pawn beside the pushed pawn", lockedWall, d6, false},
+		{"en passant capture leaves the king in check", strings.Replace(enPassantWall, "Ke1", "Kd3", 1), d6, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			analysis := AnalyzeDeadPosition(positionOf(test.board, test.enPassant))
+			assert.Equal(t, test.canMate, analysis.CanMate(WhitePiece), "white can mate")
+			assert.Equal(t, test.canMate, analysis.CanMate(BlackPiece), "black can mate")
+			assert.Equal(t, !test.canMate, analysis.Locked, "locked")
+		})
+	}
+}
+
+func TestCalculate_LockedPositionIsDrawn(t *testing.T) {
+	movement := NewChessMovement(positionOf(lockedWall, ChessLocation{}))
+	movement.Calculate()
+	assert.NotEmpty(t, movement.Moves)
+	assert.Equal(t, DrawDeadPosition, movement.Result)
+
+	movement = NewChessMovement(positionOf("Ke1 ke8", ChessLocation{}))
+	movement.Calculate()
+	assert.Equal(t, DrawInsufficientMaterial, movement.Result)
+}
+
+func TestCalculate_EnPassantOpensALockedPosition(t *testing.T) {
+	tests := []struct {
+		name      string
+		board     string
+		enPassant ChessLocation
+		result    GameResult
+	}{
+		{"en passant capture", enPassantWall, ChessLocation{File: FileD, Rank: Rank6}, InProgress},
+		{"no en passant square", enPassantWall, ChessLocation{}, DrawDeadPosition},
+		{"no pawn beside the pushed pawn", lockedWall, ChessLocation{File: FileD, Rank: Rank6}, DrawDeadPosition},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			movement := NewChessMovement(positionOf(test.board, test.enPassant))
+			movement.Calculate()
+			assert.Equal(t, test.result, movement.Result)
+		})
+	}
+
+	// taking en passant breaks the wall open
+	position := positionOf(enPassantWall, ChessLocation{File: FileD, Rank: Rank6})
+	movement := NewChessMovement(position)
+	movement.Calculate()
+	for _, move := range movement.Moves {
+		if move.To == position.EnPassantSquare {
+			assert.False(t, AnalyzeDeadPosition(position.ApplyMove(move, NoPiece)).IsDead())
+			return
+		}
+	}
+	t.Fatal("no en passant capture")
+}
@@ -0,0 +1,43 @@
+package game
+
+// Magic multipliers for the sliding piece lookup tables, indexed by square (a1 = 0, h8 = 63).
+// Each one maps every relevant occupancy of its square onto a collision free slot, see
+// https://www.chessprogramming.org/Magic_Bitboards
+
+var rookMagicNumbers = [64]uint64{
+	0x1080004008801020, 0x0840092002C03000, 0x1900200010400900, 0x0880100008000480,
+	0x4200100420080200, 0x8100020100080400, 0x0200040110886200, 0x0200008040220411,
+	0x0404800084400220, 0x0000401000402000, 0x0086001081220440, 0x0408800800100280,
+	0x000A001201040820, 0x8848800200840080, 0x4001000100040200, 0x0442000102105084,
+	0x9080010020804100, 0x0040404000201009, 0x0000808010002009, 0x2200090021D00100,
+	0x0008008008040080, 0x0004004002010040, 0x0011040008015042, 0x00000A0001768104,
+	0x0000800080204009, 0x2010004140002001, 0x9800200280100080, 0x1000100080080080,
+	0x0442000A00049020, 0x2100040080020080, 0x0800120400900148, 0x0010040A00128541,
+	0x2800804000800030, 0x1010002000400041, 0x4000200011004100, 0x0610008410800800,
+	0x0400802402800800, 0xC100020080800400, 0x0002000802000401, 0x0182085882000401,
+	0x0220204000808000, 0x2860100040024022, 0x0001002004110040, 0x99101042000A0020,
+	0x0004080004008080, 0x0010040002008080, 0x2012004881020004, 0x8300842444820011,
+	0x0088403882010200, 0x0820400080210100, 0x0110910040A00300, 0x0801100280080480,
+	0x0242009008200600, 0x1002000489500200, 0x0040800200010080, 0x0091800041000080,
+	0x0000209300488001, 0x04C1002414824001, 0x020020000B001041, 0x7000100004200901,
+	0x8002002004100802, 0x30010002084C0007, 0x0888221800813004, 0x4000002840840112,
+}
+
+var bishopMagicNumbers = [64]uint64{
+	0x2048017020910100, 0x0044410424008008, 0x040828A400900000, 0x8002209200022000,
+	0x0002021000540002, 0x0021018840000000, 0x00009E8420204002, 0x00A0920110084480,
+	0x4003062018010110, 0x0221046812004E09, 0x01E11002958912A0, 0x0000044410804000,
+	0x0000821210000080, 0x080201102210A800, 0x0080040411045004, 0x00704A1842021000,
+	0x1005061070322800, 0x0018001010410444, 0x0010000800401420, 0x2204002844000800,
+	0x2052020412022280, 0x000A020101008208, 0x0040400201042000, 0x03E1082040480410,
+	0x1004200004208414, 0x08700400984808C8, 0x0088080004004410, 0x008C0240140100A2,
+	0x0008840001822000, 0x0050088001080100, 0x98140840040A2200, 0x3002020900210110,
+	0x1004040640206000, 0x1090909000840400, 0x9002444810100020, 0x4000020080080080,
+	0x0028020400011010, 0x0290808300020100, 0x8010020882004410, 0x0604010040082C20,
+	0x20040104C0801008, 0x6004208424001050, 0x1002840041000800, 0x0200042018000102,
+	0xA8002000A0821C00, 0x0040080802201910, 0x0222620444000100, 0x0002080041020088,
+	0x1500820110401050, 0x0000492090100080, 0x0900410041100000, 0x0302000420880000,
+	0x0010501202020020, 0x0008200490049040, 0x0462080214A40120, 0x2421310102008100,
+	0x2400420080884060, 0x0800804406184208, 0x0B0080124A084400, 0x082E082300840412,
+	0x6051049040082200, 0xC610211002102101, 0x0000048808010433, 0x0010200804405440,
+}
@@ -5,12 +5,12 @@ import (
 	"strings"
 )
 
-// ChessMovement calculates the moves
+// ChessMovement calculates the legal moves for the player to move in a position.
+// Moves are generated from the board bitboards; legality is decided with a check mask and
+// pin lines rather than by playing each move and recalculating the opponent's replies.
 type ChessMovement struct {
 	Position *ChessPosition
 
-	CandidateMoves map[ColorType][]ChessMove
-
 	// Moves contain the list of valid moves for the current player
 	Moves []ChessMove
 
@@ -29,11 +29,7 @@ type ChessMovement struct {
 
 func NewChessMovement(position *ChessPosition) *ChessMovement {
 	return &ChessMovement{
-		Position: position,
-		CandidateMoves: map[ColorType][]ChessMove{
-			WhitePiece: {},
-			BlackPiece: {},
-		},
+		Position:     position,
 		KingLocation: map[ColorType]ChessLocation{},
 		Check: map[ColorType]bool{
 			WhitePiece: false,
@@ -51,10 +47,9 @@ func (calculator *ChessMovement) Calculate() {
 		return
 	}
 
-	calculator.calculateCandidateMoves()
 	calculator.calculateCheck()
-	calculator.calculateCanCastle()
 	calculator.calculateValidMoves()
+	calculator.calculateCanCastle()
 	calculator.calculateResult()
 	calculator.Calculated = true
 }
@@ -64,120 +59,322 @@ func (calculator *ChessMovement) GetMoves() []ChessMove {
 	return calculator.Moves
 }
 
+// attackersTo returns the pieces of the given color that attack the square index, using occupied to block sliding pieces.
+func attackersTo(board *ChessBoard, index int, occupied Bitboard, color ColorType) Bitboard {
+	pieces := board.ColorBitboard(color)
+	queens := board.pieces[pieceIndex(Queen)]
+	return (pawnAttackTable[colorIndex(color.OppositeColor())][index] & board.pieces[pieceIndex(Pawn)] & pieces) |
+		(knightAttackTable[index] & board.pieces[pieceIndex(Knight)] & pieces) |
+		(kingAttackTable[index] & board.pieces[pieceIndex(King)] & pieces) |
+		(bishopAttacks(index, occupied) & (board.pieces[pieceIndex(Bishop)] | queens) & pieces) |
+		(rookAttacks(index, occupied) & (board.pieces[pieceIndex(Rook)] | queens) & pieces)
+}
+
+// IsSquareAttacked returns true if any piece of the given color attacks the location.
+func IsSquareAttacked(board *ChessBoard, location ChessLocation, color ColorType) bool {
+	return attackersTo(board, location.ToIndex(), board.Occupied(), color) != 0
+}
+
 func (calculator *ChessMovement) calculateCheck() {
+	board := calculator.Position.Board
 
 	for _, color := range AllColors {
-		kingLocation := calculator.KingLocation[color]
-
-		// check each of the opponent moves to see if they attack the king
-		for _, move := range calculator.CandidateMoves[color.OppositeColor()] {
-			if move.To == kingLocation {
-				calculator.Check[color] = true
-				break
-			}
+		kings := board.PieceBitboard(ChessPiece{King, color})
+		if kings == 0 {
+			continue
 		}
-	}
 
+		kingIndex := kings.First()
+		calculator.KingLocation[color] = LocationFromIndex(kingIndex)
+		calculator.Check[color] = attackersTo(board, kingIndex, board.Occupied(), color.OppositeColor()) != 0
+	}
 }
 
+// calculateCanCastle determines whether the player to move may castle on either side: the right must
+// still exist, the king and rook must be on the back rank, the squares either of them crosses must be empty
+// but for the two of them and the king may not start on, pass through or land on an attacked square. The
+// king lands on the g or c file and the rook beside it, so the same rules cover Chess960 where they start
+// on any file. Legal castles are appended to Moves as king moves with IsCastle set.
 func (calculator *ChessMovement) calculateCanCastle() {
-	// Ensure the method is only calculated once
+	board := calculator.Position.Board
+	castlingRights := calculator.Position.CastlingRights[calculator.Position.PlayerToMove]
+
+	if calculator.checkCastle(true) == CastleAllowed {
+		calculator.CanCastle.KingSide = true
+		calculator.Moves = append(calculator.Moves, castleMove(board, calculator.castlingKing(), castlingRights.KingSideRookFile(), FileG))
+	}
 
+	if calculator.checkCastle(false) == CastleAllowed {
+		calculator.CanCastle.QueenSide = true
+		calculator.Moves = append(calculator.Moves, castleMove(board, calculator.castlingKing(), castlingRights.QueenSideRookFile(), FileC))
+	}
+}
+
+// CastleFailure is the reason a castle is illegal.
+type CastleFailure int
+
+const (
+	// CastleAllowed means the castle is legal.
+	CastleAllowed CastleFailure = iota
+	// CastleNoRights means the king or the rook has moved, or the rook is gone.
+	CastleNoRights
+	// CastleInCheck means the king is in check.
+	CastleInCheck
+	// CastlePathBlocked means a piece stands on a square the king or the rook crosses.
+	CastlePathBlocked
+	// CastleThroughCheck means the king crosses or lands on an attacked square.
+	CastleThroughCheck
+)
+
+// String returns a human-readable description of the reason.
+func (f CastleFailure) String() string {
+	switch f {
+	case CastleAllowed:
+		return "castling is allowed"
+	case CastleNoRights:
+		return "the right to castle has been lost"
+	case CastleInCheck:
+		return "the king is in check"
+	case CastlePathBlocked:
+		return "the path between the king and the rook is blocked"
+	case CastleThroughCheck:
+		return "the king would pass through or land on an attacked square"
+	default:
+		return "unknown"
+	}
+}
+
+// CheckCastle returns why the player to move cannot castle toward a side, CastleAllowed when they can.
+func (calculator *ChessMovement) CheckCastle(kingSide bool) CastleFailure {
+	calculator.Calculate()
+	return calculator.checkCastle(kingSide)
+}
+
+// checkCastle checks the castling rights, the king, the rook, the squares that must be empty and the squares
+// the king crosses for a single castle.
+func (calculator *ChessMovement) checkCastle(kingSide bool) CastleFailure {
 	playerColor := calculator.Position.PlayerToMove
 	castlingRights := calculator.Position.CastlingRights[playerColor]
+	board := calculator.Position.Board
 
-	kingLocation := calculator.KingLocation[playerColor]
-	if kingLocation == (ChessLocation{}) {
-		return
+	rookFile, kingTarget, rookTarget := castlingRights.QueenSideRookFile(), FileC, FileD
+	if kingSide {
+		rookFile, kingTarget, rookTarget = castlingRights.KingSideRookFile(), FileG, FileF
+	}
+	if kingSide && !castlingRights.KingSide || !kingSide && !castlingRights.QueenSide {
+		return CastleNoRights
 	}
 
-	// Check King-side castling
-	if castlingRights.KingSide && !calculator.Check[playerColor] {
-		kingSideClear := true
-		for file := kingLocation.File + 1; file <= FileG; file++ {
-			if !calculator.Position.Board.GetSquare(ChessLocation{File: file, Rank: kingLocation.Rank}).IsEmpty() {
-				kingSideClear = false
-				break
-			}
-		}
+	kingLocation := calculator.castlingKing()
+	if kingLocation.Rank != backRank(playerColor) {
+		return CastleNoRights
+	}
 
-		if kingSideClear && !calculator.isPathUnderAttack(kingLocation, FileG, playerColor) {
-			calculator.CanCastle.KingSide = true
-		}
+	// the king side rook stands between the king and the h file, the queen side rook between the a file and the king
+	if (rookFile > kingLocation.File) != kingSide {
+		return CastleNoRights
+	}
+	rookLocation := ChessLocation{File: rookFile, Rank: kingLocation.Rank}
+	if board.GetSquare(rookLocation).Piece != (ChessPiece{Rook, playerColor}) {
+		return CastleNoRights
 	}
 
-	// Check Queen-side castling
-	if castlingRights.QueenSide && !calculator.Check[playerColor] {
-		queenSideClear := true
-		for file := kingLocation.File - 1; file >= FileB; file-- {
-			if !calculator.Position.Board.GetSquare(ChessLocation{File: file, Rank: kingLocation.Rank}).IsEmpty() {
-				queenSideClear = false
-				break
-			}
-		}
+	if calculator.Check[playerColor] {
+		return CastleInCheck
+	}
+
+	king, rook := kingLocation.ToIndex(), rookLocation.ToIndex()
+	kingTo := ChessLocation{File: kingTarget, Rank: kingLocation.Rank}.ToIndex()
+	rookTo := ChessLocation{File: rookTarget, Rank: kingLocation.Rank}.ToIndex()
+	kingPath := betweenTable[king][kingTo] | squareBit(kingTo)
+	rookPath := betweenTable[rook][rookTo] | squareBit(rookTo)
+	occupied := board.Occupied() &^ squareBit(king) &^ squareBit(rook)
+	if (kingPath|rookPath)&occupied != 0 {
+		return CastlePathBlocked
+	}
 
-		if queenSideClear && !calculator.isPathUnderAttack(kingLocation, FileC, playerColor) {
-			calculator.CanCastle.QueenSide = true
+	// the rook no longer shields the king once it has moved
+	for index := range kingPath.Squares {
+		if attackersTo(board, index, occupied, playerColor.OppositeColor()) != 0 {
+			return CastleThroughCheck
 		}
 	}
+	return CastleAllowed
 }
 
-// Helper method to check if the path for castling is under attack
-func (calculator *ChessMovement) isPathUnderAttack(kingLocation ChessLocation, targetFile FileType, playerColor ColorType) bool {
-	for file := kingLocation.File; file != targetFile; {
-		if file < targetFile {
-			file++
-		} else {
-			file--
-		}
+// castlingKing returns the square of the king of the player to move, a location off the board when it has none.
+func (calculator *ChessMovement) castlingKing() ChessLocation {
+	kings := calculator.Position.Board.PieceBitboard(ChessPiece{King, calculator.Position.PlayerToMove})
+	if kings == 0 {
+		return ChessLocation{}
+	}
+	return LocationFromIndex(kings.First())
+}
 
-		location := ChessLocation{File: file, Rank: kingLocation.Rank}
-		for _, move := range calculator.CandidateMoves[playerColor.OppositeColor()] {
-			if move.To == location {
-				return true
-			}
-		}
+func castleMove(board *ChessBoard, kingLocation ChessLocation, rookFile FileType, kingTarget FileType) ChessMove {
+	return ChessMove{
+		From:         board.GetSquare(kingLocation),
+		To:           ChessLocation{File: kingTarget, Rank: kingLocation.Rank},
+		CanMove:      false,
+		CanCapture:   false,
+		IsCastle:     true,
+		CastlingRook: ChessLocation{File: rookFile, Rank: kingLocation.Rank},
 	}
-	return false
 }
 
-// calculateValidMoves filters candidate moves down to only legal moves: moves that can actually be
-// made (CanMove is true) and that do not leave the current player's king in check.
+// calculateValidMoves generates the legal non-castling moves for the current player.
+//
+// When the king is in check every other piece is restricted to the check mask: the checking piece and
+// the squares between it and the king. A double check leaves only king moves. Pinned pieces are
+// restricted to the line running through the king and the pinning piece.
 func (calculator *ChessMovement) calculateValidMoves() {
+	position := calculator.Position
+	board := position.Board
+	us := position.PlayerToMove
+	them := us.OppositeColor()
 
-	// King has special logic, it cannot step into check
-	candidateMoves := calculator.CandidateMoves[calculator.Position.PlayerToMove]
+	ours := board.ColorBitboard(us)
+	theirs := board.ColorBitboard(them)
+	occupied := ours | theirs
 
-	// TODO: this is pretty expensive, look at optimizing in the future
-	finalMoves := []ChessMove{}
-	for _, move := range candidateMoves {
+	moves := []ChessMove{}
 
-		// Skip moves that cannot actually be made (e.g., pawn diagonal with no capture target)
-		if !move.CanMove {
-			continue
+	kingIndex := -1
+	if kings := board.PieceBitboard(ChessPiece{King, us}); kings != 0 {
+		kingIndex = kings.First()
+	}
+
+	checkMask := ^Bitboard(0)
+	pinned := Bitboard(0)
+	if kingIndex >= 0 {
+		checkers := attackersTo(board, kingIndex, occupied, them)
+		switch checkers.Count() {
+		case 0:
+		case 1:
+			checkMask = checkers | betweenTable[kingIndex][checkers.First()]
+		default:
+			checkMask = 0
+		}
+
+		queens := board.PieceBitboard(ChessPiece{Queen, them})
+		snipers := (rookAttacks(kingIndex, 0) & (board.PieceBitboard(ChessPiece{Rook, them}) | queens)) |
+			(bishopAttacks(kingIndex, 0) & (board.PieceBitboard(ChessPiece{Bishop, them}) | queens))
+		for sniper := range snipers.Squares {
+			blockers := betweenTable[kingIndex][sniper] & occupied
+			if blockers.Count() == 1 && blockers&ours != 0 {
+				pinned |= blockers
+			}
 		}
+	}
 
-		// cannot move into check; for promotion moves use Queen (strongest piece) to check legality
-		promoForCheck := NoPiece
-		if move.IsPromotion {
-			promoForCheck = Queen
+	// restrict returns the destinations a non-king piece on the square index may legally use.
+	restrict := func(index int, targets Bitboard) Bitboard {
+		targets &= checkMask
+		if pinned.Has(index) {
+			targets &= lineTable[kingIndex][index]
 		}
-		candidatePosition := calculator.Position.Move(move.From.Location, move.To, promoForCheck)
+		return targets
+	}
 
-		// Just need to calculate through check
-		checkCalculator := NewChessMovement(candidatePosition)
-		checkCalculator.calculateCandidateMoves()
-		checkCalculator.calculateCheck()
+	for from := range (ours &^ board.pieces[pieceIndex(King)]).Squares {
+		fromSquare := ChessSquare{LocationFromIndex(from), board.squares[from]}
+
+		var targets Bitboard
+		if fromSquare.Piece.Piece == Pawn {
+			targets = pawnAttackTable[colorIndex(us)][from] & theirs
+			forward := from + 8
+			if us == BlackPiece {
+				forward = from - 8
+			}
+			if forward >= 0 && forward < 64 && !occupied.Has(forward) {
+				targets |= squareBit(forward)
+				doublePush := forward + (forward - from)
+				if isPawnOnStartingSquare(fromSquare.Location, us) && !occupied.Has(doublePush) {
+					targets |= squareBit(doublePush)
+				}
+			}
+		} else {
+			targets = pieceAttacks(fromSquare.Piece, from, occupied) &^ ours
+		}
 
-		if !checkCalculator.Check[calculator.Position.PlayerToMove] {
-			finalMoves = append(finalMoves, move)
+		for to := range restrict(from, targets).Squares {
+			toLocation := LocationFromIndex(to)
+			moves = append(moves, ChessMove{
+				From:        fromSquare,
+				To:          toLocation,
+				CanMove:     true,
+				CanCapture:  theirs.Has(to),
+				IsPromotion: fromSquare.Piece.Piece == Pawn && isPromotionRank(toLocation.Rank, us),
+			})
 		}
 	}
 
-	calculator.Moves = finalMoves
+	moves = append(moves, calculator.enPassantMoves(kingIndex)...)
 
+	if kingIndex >= 0 {
+		kingSquare := ChessSquare{LocationFromIndex(kingIndex), board.squares[kingIndex]}
+		// The king is removed from the occupancy so a slider's attack continues through the square it leaves.
+		withoutKing := occupied &^ squareBit(kingIndex)
+		for to := range (kingAttackTable[kingIndex] &^ ours).Squares {
+			if attackersTo(board, to, withoutKing, them) != 0 {
+				continue
+			}
+			moves = append(moves, ChessMove{
+				From:       kingSquare,
+				To:         LocationFromIndex(to),
+				CanMove:    true,
+				CanCapture: theirs.Has(to),
+			})
+		}
+	}
+
+	calculator.Moves = moves
+}
+
+// enPassantMoves returns the legal en passant captures. These are checked by removing both pawns from the
+// occupancy, which also catches the case where the two pawns shield the king along a rank.
+func (calculator *ChessMovement) enPassantMoves(kingIndex int) []ChessMove {
+	position := calculator.Position
+	board := position.Board
+	us := position.PlayerToMove
+	them := us.OppositeColor()
+
+	if !position.EnPassantSquare.IsOnBoard() {
+		return nil
+	}
+
+	target := position.EnPassantSquare.ToIndex()
+	captured := target - 8
+	if us == BlackPiece {
+		captured = target + 8
+	}
+	if captured < 0 || captured >= 64 || board.Occupied().Has(target) ||
+		!board.PieceBitboard(ChessPiece{Pawn, them}).Has(captured) {
+		return nil
+	}
+
+	moves := []ChessMove{}
+	attackers := pawnAttackTable[colorIndex(them)][target] & board.PieceBitboard(ChessPiece{Pawn, us})
+	for from := range attackers.Squares {
+		if kingIndex >= 0 {
+			occupied := (board.Occupied() &^ squareBit(from) &^ squareBit(captured)) | squareBit(target)
+			if attackersTo(board, kingIndex, occupied, them)&^squareBit(captured) != 0 {
+				continue
+			}
+		}
+		moves = append(moves, ChessMove{
+			From:       ChessSquare{LocationFromIndex(from), board.squares[from]},
+			To:         position.EnPassantSquare,
+			CanMove:    true,
+			CanCapture: true,
+		})
+	}
+	return moves
+}
+
+func (calculator *ChessMovement) calculateResult() {
 	// if there are no moves, we're in checkmate or stalemate
-	if len(finalMoves) == 0 {
+	if len(calculator.Moves) == 0 {
 		if calculator.Check[calculator.Position.PlayerToMove] {
 			calculator.IsCheckmate = true
 		} else {
@@ -185,9 +382,6 @@ func (calculator *ChessMovement) calculateValidMoves() {
 		}
 	}
 
-}
-
-func (calculator *ChessMovement) calculateResult() {
 	// Checkmate and stalemate take precedence over draw conditions.
 	if calculator.IsCheckmate {
 		if calculator.Position.PlayerToMove == WhitePiece {
@@ -202,14 +396,16 @@ func (calculator *ChessMovement) calculateResult() {
 		return
 	}
 
-	// Fifty-move rule: 100 half-moves without a pawn move or capture.
-	if calculator.Position.HalfmoveClock >= 100 {
-		calculator.Result = DrawFiftyMove
-		return
-	}
+	// The fifty-move rule and repetition depend on the rules being played and the game's history, so
+	// ChessGame decides them.
 
-	// Insufficient material: neither side can force checkmate.
-	if hasInsufficientMaterial(calculator.Position.Board) {
+	// Dead position: neither side can checkmate, for lack of material or in a locked pawn structure.
+	analysis := AnalyzeDeadPosition(calculator.Position)
+	switch {
+	case analysis.Locked:
+		calculator.Result = DrawDeadPosition
+		return
+	case analysis.IsDead():
 		calculator.Result = DrawInsufficientMaterial
 		return
 	}
@@ -217,34 +413,6 @@ func (calculator *ChessMovement) calculateResult() {
 	calculator.Result = InProgress
 }
 
-func (calculator *ChessMovement) calculateCandidateMoves() {
-
-	if calculator.Calculated {
-		return
-	}
-
-	allMoves := map[ColorType][]ChessMove{
-		WhitePiece: {},
-		BlackPiece: {},
-	}
-
-	for square := range calculator.Position.Board.IterateSquares() {
-		if square.IsEmpty() {
-			continue
-		}
-
-		if square.Piece.Piece == King {
-			calculator.KingLocation[square.Piece.Color] = square.Location
-		}
-
-		moves := CalculateMoves(calculator.Position, square.Location)
-
-		allMoves[square.Piece.Color] = append(allMoves[square.Piece.Color], moves...)
-	}
-
-	calculator.CandidateMoves = allMoves
-}
-
 type ChessMove struct {
 	// From is the square the piece is moving from
 	From ChessSquare
@@ -261,6 +429,9 @@ type ChessMove struct {
 	// IsCastle true if the move is a castle, if true, this will only represent the move of the king
 	IsCastle bool
 
+	// CastlingRook is the square of the rook the king castles with, only set when IsCastle is true
+	CastlingRook ChessLocation
+
 	// IsPromotion true if the move results in a pawn promotion.
 	// The caller supplies the desired promotion piece (via SAN or otherwise) when applying the move.
 	IsPromotion bool
@@ -289,6 +460,30 @@ func (move ChessMove) String() string {
 
 }
 
+// CastlesKingSide returns true if the move castles with the king side rook.
+func (move ChessMove) CastlesKingSide() bool {
+	return move.IsCastle && move.CastlingRook.File > move.From.Location.File
+}
+
+// Chess960UciString returns the move in the UCI notation used once UCI_Chess960 is set, where castling is
+// written as the king capturing its own rook, e.g. e1h1.
+func (move ChessMove) Chess960UciString(promotionPiece PieceType) string {
+	if move.IsCastle {
+		return move.From.Location.String() + move.CastlingRook.String()
+	}
+	return move.UciString(promotionPiece)
+}
+
+// UciString returns the move in UCI long algebraic notation, e.g. e2e4, e1g1 or e7e8q.
+// promotionPiece is appended in lower case when the move is a promotion.
+func (move ChessMove) UciString(promotionPiece PieceType) string {
+	uci := move.From.Location.String() + move.To.String()
+	if move.IsPromotion && promotionPiece != NoPiece {
+		uci += strings.ToLower(string(promotionPiece))
+	}
+	return uci
+}
+
 func CalculateMoves(position *ChessPosition, fromLocation ChessLocation) []ChessMove {
 
 	square := position.Board.GetSquare(fromLocation)
@@ -501,24 +696,13 @@ func calculateKingMoves(position *ChessPosition, fromLocation ChessLocation) []C
 		}
 	}
 
-	if position.CastlingRights[fromPiece.Color].KingSide {
-		moves = append(moves, ChessMove{
-			From:       position.Board.GetSquare(fromLocation),
-			To:         ChessLocation{File: FileG, Rank: fromLocation.Rank},
-			CanCapture: false,
-			CanMove:    false,
-			IsCastle:   true,
-		})
+	rights := position.CastlingRights[fromPiece.Color]
+	if rights.KingSide {
+		moves = append(moves, castleMove(position.Board, fromLocation, rights.KingSideRookFile(), FileG))
 	}
 
-	if position.CastlingRights[fromPiece.Color].QueenSide {
-		moves = append(moves, ChessMove{
-			From:       position.Board.GetSquare(fromLocation),
-			To:         ChessLocation{File: FileC, Rank: fromLocation.Rank},
-			CanMove:    false,
-			CanCapture: false,
-			IsCastle:   true,
-		})
+	if rights.QueenSide {
+		moves = append(moves, castleMove(position.Board, fromLocation, rights.QueenSideRookFile(), FileC))
 	}
 
 	return moves
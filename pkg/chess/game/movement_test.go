@@ -452,3 +452,170 @@ func TestKnightOnCorner_AllMovesOnBoard(t *testing.T) {
 		})
 	}
 }
+
+func TestValidMoves_PinsAndChecks(t *testing.T) {
+	tests := []struct {
+		name             string
+		boardSetup       string
+		playerToMove     ColorType
+		enPassant        string
+		expectedMoves    []string
+		notExpectedMoves []string
+	}{
+		{
+			name:             "pinned rook may only move along the pin",
+			boardSetup:       "Ke1 Re2 re8 ka8",
+			playerToMove:     WhitePiece,
+			expectedMoves:    []string{"Re2e3", "Re2xe8"},
+			notExpectedMoves: []string{"Re2d2", "Re2f2"},
+		},
+		{
+			name:             "pinned knight cannot move",
+			boardSetup:       "Ke1 Nd2 bb4 ka8",
+			playerToMove:     WhitePiece,
+			notExpectedMoves: []string{"Nd2b3", "Nd2f3", "Nd2c4", "Nd2e4"},
+		},
+		{
+			name:             "in check only blocks, captures and king moves are allowed",
+			boardSetup:       "Ke1 Rb2 Nf1 re8 ka8",
+			playerToMove:     WhitePiece,
+			expectedMoves:    []string{"Rb2e2", "Nf1e3", "Ke1d1"},
+			notExpectedMoves: []string{"Rb2b3", "Nf1g3", "Ke1e2"},
+		},
+		{
+			name:             "double check allows only king moves",
+			boardSetup:       "Ke1 Ra2 re8 nd3 ka8",
+			playerToMove:     WhitePiece,
+			expectedMoves:    []string{"Ke1d2", "Ke1f1"},
+			notExpectedMoves: []string{"Ra2e2", "Ra2a3"},
+		},
+		{
+			name:             "king cannot step back along the checking ray",
+			boardSetup:       "Ke2 ra2 ka8",
+			playerToMove:     WhitePiece,
+			notExpectedMoves: []string{"Ke2d2", "Ke2f2"},
+		},
+		{
+			name:          "en passant capture",
+			boardSetup:    "Ke1 Pe5 pd5 ka8",
+			playerToMove:  WhitePiece,
+			enPassant:     "d6",
+			expectedMoves: []string{"Pe5xd6"},
+		},
+		{
+			name:             "en passant not allowed when both pawns shield the king on the rank",
+			boardSetup:       "Ka5 Pb5 pc5 rh5 ka8",
+			playerToMove:     WhitePiece,
+			enPassant:        "c6",
+			notExpectedMoves: []string{"Pb5xc6"},
+		},
+		{
+			name:          "en passant captures the checking pawn",
+			boardSetup:    "Kd4 Pe5 pd5 ka8",
+			playerToMove:  WhitePiece,
+			enPassant:     "d6",
+			expectedMoves: []string{"Pe5xd6"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			board := parseBoard(test.boardSetup)
+			position := &ChessPosition{Board: board, PlayerToMove: test.playerToMove}
+			if test.enPassant != "" {
+				position.EnPassantSquare = ParseChessLocation(test.enPassant)
+			}
+			movement := NewChessMovement(position)
+			movement.Calculate()
+			actualMoves := getMoveStrings(movement.Moves)
+			for _, expected := range test.expectedMoves {
+				assert.Contains(t, actualMoves, expected)
+			}
+			for _, notExpected := range test.notExpectedMoves {
+				assert.NotContains(t, actualMoves, notExpected)
+			}
+		})
+	}
+}
+
+func TestValidMoves_IncludesLegalCastles(t *testing.T) {
+	position := &ChessPosition{
+		Board:        parseBoard("Ke1 Ra1 Rh1 ke8"),
+		PlayerToMove: WhitePiece,
+		CastlingRights: map[ColorType]CastlingRights{
+			WhitePiece: {KingSide: true, QueenSide: true},
+		},
+	}
+	movement := NewChessMovement(position)
+	movement.Calculate()
+
+	castles := []string{}
+	for _, move := range movement.Moves {
+		if move.IsCastle {
+			castles = append(castles, move.To.String())
+		}
+	}
+	assert.ElementsMatch(t, []string{"g1", "c1"}, castles)
+}
+
+func TestValidMoves_Chess960Castles(t *testing.T) {
+	tests := []struct {
+		name    string
+		board   string
+		rights  CastlingRights
+		castles []string
+	}{
+		{
+			name:    "king already on its castled square",
+			board:   "Kg1 Rh1 Rb1 ke8",
+			rights:  CastlingRights{KingSide: true, QueenSide: true, QueenSideRook: FileB},
+			castles: []string{"g1h1", "g1b1"},
+		},
+		{
+			name:    "rook stands on the king's castled square",
+			board:   "Kf1 Rg1 ke8",
+			rights:  CastlingRights{KingSide: true, KingSideRook: FileG},
+			castles: []string{"f1g1"},
+		},
+		{
+			name:    "king and rook swap squares",
+			board:   "Kd1 Rc1 ke8",
+			rights:  CastlingRights{QueenSide: true, QueenSideRook: FileC},
+			castles: []string{"d1c1"},
+		},
+		{
+			name:   "castling rook shields the king's path",
+			board:  "Kf1 Rb1 ke8 ra1",
+			rights: CastlingRights{QueenSide: true, QueenSideRook: FileB},
+		},
+		{
+			name:   "another piece on the rook's path",
+			board:  "Kb1 Ra1 Nd1 ke8",
+			rights: CastlingRights{QueenSide: true},
+		},
+		{
+			name:   "rook file on the wrong side of the king",
+			board:  "Kf1 Rb1 ke8",
+			rights: CastlingRights{KingSide: true, KingSideRook: FileB},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			position := &ChessPosition{
+				Board:          parseBoard(test.board),
+				PlayerToMove:   WhitePiece,
+				CastlingRights: map[ColorType]CastlingRights{WhitePiece: test.rights},
+			}
+			movement := NewChessMovement(position)
+			movement.Calculate()
+
+			castles := []string{}
+			for _, move := range movement.Moves {
+				if move.IsCastle {
+					castles = append(castles, move.Chess960UciString(NoPiece))
+				}
+			}
+			assert.ElementsMatch(t, test.castles, castles)
+		})
+	}
+}
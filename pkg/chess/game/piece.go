@@ -14,6 +14,9 @@ const (
 	King    PieceType = 'K'
 )
 
+// PromotionPieces lists the pieces a pawn may promote to, strongest first.
+var PromotionPieces = []PieceType{Queen, Rook, Bishop, Knight}
+
 type ChessPiece struct {
 	Piece PieceType
 	Color ColorType
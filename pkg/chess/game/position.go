@@ -8,6 +8,9 @@ type ChessPosition struct {
 	EnPassantSquare ChessLocation
 	HalfmoveClock   int
 	FullmoveNumber  int
+
+	// hash caches the Zobrist hash, zero until it is known
+	hash uint64
 }
 
 // CastlingRights represents the castling rights for a player
@@ -16,76 +19,89 @@ type CastlingRights struct {
 	KingSide bool
 	// QueenSide is true if the player can castle queen side
 	QueenSide bool
-}
 
-func (position *ChessPosition) CastleKingside() *ChessPosition {
+	// KingSideRook and QueenSideRook are the files of the rooks castled with in Chess960. NoFile stands for
+	// the h and a files of standard chess.
+	KingSideRook  FileType
+	QueenSideRook FileType
+}
 
-	rank := Rank1
-	if position.PlayerToMove == BlackPiece {
-		rank = Rank8
+// KingSideRookFile returns the file of the rook the king castles with on the king side.
+func (rights CastlingRights) KingSideRookFile() FileType {
+	if rights.KingSideRook == NoFile {
+		return FileH
 	}
+	return rights.KingSideRook
+}
 
-	kingSquare := position.Board.GetSquare(ChessLocation{FileE, rank})
-	rookSquare := position.Board.GetSquare(ChessLocation{FileH, rank})
-	newKingSquare := position.Board.GetSquare(ChessLocation{FileG, rank})
-	newRookSquare := position.Board.GetSquare(ChessLocation{FileF, rank})
-
-	// Create a new board
-	newBoard := position.Board.Clone()
-
-	// Update the new board
-	newBoard.SetSquare(newKingSquare.Location, kingSquare.Piece)
-	newBoard.SetSquare(newRookSquare.Location, rookSquare.Piece)
-	newBoard.SetSquare(kingSquare.Location, ChessPiece{NoPiece, NoColor})
-	newBoard.SetSquare(rookSquare.Location, ChessPiece{NoPiece, NoColor})
-
-	// Update castling rights
-	castlingRights := map[ColorType]CastlingRights{}
-	for color, rights := range position.CastlingRights {
-		if color == position.PlayerToMove {
-			// After castling, the player loses all castling rights
-			castlingRights[color] = CastlingRights{}
-		} else {
-			// Other player's castling rights remain unchanged
-			castlingRights[color] = rights
-		}
+// QueenSideRookFile returns the file of the rook the king castles with on the queen side.
+func (rights CastlingRights) QueenSideRookFile() FileType {
+	if rights.QueenSideRook == NoFile {
+		return FileA
 	}
+	return rights.QueenSideRook
+}
 
-	// Switch player to move
-	playerToMove := BlackPiece
-	if position.PlayerToMove == BlackPiece {
-		playerToMove = WhitePiece
+// withoutRook removes the right to castle with the rook on the given file.
+func (rights CastlingRights) withoutRook(file FileType) CastlingRights {
+	if rights.KingSide && rights.KingSideRookFile() == file {
+		rights.KingSide, rights.KingSideRook = false, NoFile
 	}
-
-	// Update fullmove number if black just moved
-	fullMoveNumber := position.FullmoveNumber
-	if position.PlayerToMove == BlackPiece {
-		fullMoveNumber++
+	if rights.QueenSide && rights.QueenSideRookFile() == file {
+		rights.QueenSide, rights.QueenSideRook = false, NoFile
 	}
+	return rights
+}
 
-	// Return a new position
-	return &ChessPosition{
-		Board:           newBoard,
-		PlayerToMove:    playerToMove,
-		CastlingRights:  castlingRights,
-		EnPassantSquare: ChessLocation{},            // No en passant target after castling
-		HalfmoveClock:   position.HalfmoveClock + 1, // Increment half-move clock
-		FullmoveNumber:  fullMoveNumber,
+// backRank returns the rank the pieces of a color start on.
+func backRank(color ColorType) RankType {
+	if color == BlackPiece {
+		return Rank8
 	}
+	return Rank1
 }
 
+// CastleKingside castles the player to move with the king side rook, does not take into consideration
+// whether castling is allowed.
+func (position *ChessPosition) CastleKingside() *ChessPosition {
+	return position.castle(true)
+}
+
+// CastleQueenside castles the player to move with the queen side rook, does not take into consideration
+// whether castling is allowed.
 func (position *ChessPosition) CastleQueenside() *ChessPosition {
-	rank := Rank1
-	if position.PlayerToMove == BlackPiece {
-		rank = Rank8
+	return position.castle(false)
+}
+
+// castle moves the king to the g or c file and the rook beside it on the f or d file, wherever they start
+// on the back rank, so the same move serves standard chess and Chess960.
+func (position *ChessPosition) castle(kingSide bool) *ChessPosition {
+	rank := backRank(position.PlayerToMove)
+	rights := position.CastlingRights[position.PlayerToMove]
+
+	kingLocation := ChessLocation{FileE, rank}
+	if kings := position.Board.PieceBitboard(ChessPiece{King, position.PlayerToMove}); kings != 0 {
+		kingLocation = LocationFromIndex(kings.First())
+	}
+	rookLocation := ChessLocation{rights.QueenSideRookFile(), rank}
+	newKingLocation := ChessLocation{FileC, rank}
+	newRookLocation := ChessLocation{FileD, rank}
+	if kingSide {
+		rookLocation = ChessLocation{rights.KingSideRookFile(), rank}
+		newKingLocation = ChessLocation{FileG, rank}
+		newRookLocation = ChessLocation{FileF, rank}
 	}
+	kingSquare := position.Board.GetSquare(kingLocation)
+	rookSquare := position.Board.GetSquare(rookLocation)
 
-	kingSquare := position.Board.GetSquare(ChessLocation{FileE, rank})
-	rookSquare := position.Board.GetSquare(ChessLocation{FileA, rank})
-	newKingSquare := position.Board.GetSquare(ChessLocation{FileC, rank})
-	newRookSquare := position.Board.GetSquare(ChessLocation{FileD, rank})
+	// the king or rook may land where the other started, so both are lifted before either is placed
+	newBoard := position.Board.Clone()
+	newBoard.SetSquare(kingLocation, ChessPiece{NoPiece, NoColor})
+	newBoard.SetSquare(rookLocation, ChessPiece{NoPiece, NoColor})
+	newBoard.SetSquare(newKingLocation, kingSquare.Piece)
+	newBoard.SetSquare(newRookLocation, rookSquare.Piece)
 
-	// update castling rights
+	// after castling the player loses all castling rights, the other player's remain unchanged
 	castlingRights := map[ColorType]CastlingRights{}
 	for color, rights := range position.CastlingRights {
 		if color == position.PlayerToMove {
@@ -95,32 +111,21 @@ func (position *ChessPosition) CastleQueenside() *ChessPosition {
 		}
 	}
 
-	// update fullmove counter if black moved
 	fullMoveNumber := position.FullmoveNumber
 	if position.PlayerToMove == BlackPiece {
 		fullMoveNumber++
 	}
 
-	// switch player to move
-	playerToMove := BlackPiece
-	if position.PlayerToMove == BlackPiece {
-		playerToMove = WhitePiece
-	}
-
-	newBoard := position.Board.Clone()
-	newBoard.SetSquare(newKingSquare.Location, kingSquare.Piece)
-	newBoard.SetSquare(newRookSquare.Location, rookSquare.Piece)
-	newBoard.SetSquare(kingSquare.Location, ChessPiece{NoPiece, NoColor})
-	newBoard.SetSquare(rookSquare.Location, ChessPiece{NoPiece, NoColor})
-
-	return &ChessPosition{
+	next := &ChessPosition{
 		Board:           newBoard,
-		PlayerToMove:    playerToMove,
+		PlayerToMove:    position.PlayerToMove.OppositeColor(),
 		CastlingRights:  castlingRights,
-		EnPassantSquare: ChessLocation{},
-		HalfmoveClock:   position.HalfmoveClock + 1,
+		EnPassantSquare: ChessLocation{},            // No en passant target after castling
+		HalfmoveClock:   position.HalfmoveClock + 1, // Increment half-move clock
 		FullmoveNumber:  fullMoveNumber,
 	}
+	hashAfterCastle(position, next, kingSquare, rookSquare, newKingLocation, newRookLocation)
+	return next
 }
 
 // Move performs a chess move, does not take into consideration whether the move is valid.
@@ -152,43 +157,15 @@ func (position *ChessPosition) Move(fromLocation ChessLocation, toLocation Chess
 	if fromSquare.Piece.Piece == King {
 		castlingRights[position.PlayerToMove] = CastlingRights{}
 	}
-	if fromSquare.Piece.Piece == Rook {
-		currentCastlingRights := castlingRights[position.PlayerToMove]
-		if position.PlayerToMove == WhitePiece {
-			if fromLocation.File == FileH && fromLocation.Rank == Rank1 {
-				castlingRights[position.PlayerToMove] = CastlingRights{QueenSide: currentCastlingRights.QueenSide, KingSide: false}
-			}
-			if fromLocation.File == FileA && fromLocation.Rank == Rank1 {
-				castlingRights[position.PlayerToMove] = CastlingRights{QueenSide: false, KingSide: currentCastlingRights.KingSide}
-			}
-		}
-		if position.PlayerToMove == BlackPiece {
-			if fromLocation.File == FileH && fromLocation.Rank == Rank8 {
-				castlingRights[position.PlayerToMove] = CastlingRights{QueenSide: currentCastlingRights.QueenSide, KingSide: false}
-			}
-			if fromLocation.File == FileA && fromLocation.Rank == Rank8 {
-				castlingRights[position.PlayerToMove] = CastlingRights{QueenSide: false, KingSide: currentCastlingRights.KingSide}
-			}
-		}
+	if fromSquare.Piece.Piece == Rook && fromLocation.Rank == backRank(position.PlayerToMove) {
+		castlingRights[position.PlayerToMove] = castlingRights[position.PlayerToMove].withoutRook(fromLocation.File)
 	}
 
-	// Revoke castling rights when a capture lands on a corner rook square.
-	type cornerEntry struct {
-		color    ColorType
-		kingSide bool
-	}
-	cornerRookSquares := map[ChessLocation]cornerEntry{
-		{FileA, Rank1}: {WhitePiece, false},
-		{FileH, Rank1}: {WhitePiece, true},
-		{FileA, Rank8}: {BlackPiece, false},
-		{FileH, Rank8}: {BlackPiece, true},
-	}
-	if corner, ok := cornerRookSquares[toLocation]; ok {
-		rights := castlingRights[corner.color]
-		if corner.kingSide {
-			castlingRights[corner.color] = CastlingRights{KingSide: false, QueenSide: rights.QueenSide}
-		} else {
-			castlingRights[corner.color] = CastlingRights{KingSide: rights.KingSide, QueenSide: false}
+	// Revoke castling rights when a capture lands on a castling rook's square.
+	opponent := position.PlayerToMove.OppositeColor()
+	if toLocation.Rank == backRank(opponent) {
+		if rights, ok := castlingRights[opponent]; ok {
+			castlingRights[opponent] = rights.withoutRook(toLocation.File)
 		}
 	}
 
@@ -219,15 +196,19 @@ func (position *ChessPosition) Move(fromLocation ChessLocation, toLocation Chess
 	}
 	newBoard.SetSquare(toLocation, pieceToPlace)
 
+	removed := []pieceOnSquare{{fromSquare.Piece, fromLocation.ToIndex()}, {toSquare.Piece, toLocation.ToIndex()}}
+
 	// if this is an en passant move, need to remove the pawn
 	if fromSquare.Piece.Piece == Pawn {
 		if toLocation == position.EnPassantSquare {
-			newBoard.SetSquare(ChessLocation{toLocation.File, fromLocation.Rank}, ChessPiece{NoPiece, NoColor})
+			capturedLocation := ChessLocation{toLocation.File, fromLocation.Rank}
+			removed = append(removed, pieceOnSquare{position.Board.GetSquare(capturedLocation).Piece, capturedLocation.ToIndex()})
+			newBoard.SetSquare(capturedLocation, ChessPiece{NoPiece, NoColor})
 			halfmoveClock = 0
 		}
 	}
 
-	return &ChessPosition{
+	next := &ChessPosition{
 		Board:           newBoard,
 		PlayerToMove:    playerToMove,
 		CastlingRights:  castlingRights,
@@ -235,6 +216,20 @@ func (position *ChessPosition) Move(fromLocation ChessLocation, toLocation Chess
 		HalfmoveClock:   halfmoveClock,
 		FullmoveNumber:  fullMoveNumber,
 	}
+	hashAfterMove(position, next, removed, []pieceOnSquare{{pieceToPlace, toLocation.ToIndex()}})
+	return next
+}
+
+// ApplyMove plays a move generated by ChessMovement, routing castling moves through CastleKingside or
+// CastleQueenside. promotionPiece is only used when the move is a promotion.
+func (position *ChessPosition) ApplyMove(move ChessMove, promotionPiece PieceType) *ChessPosition {
+	if move.IsCastle {
+		return position.castle(move.CastlesKingSide())
+	}
+	if !move.IsPromotion {
+		promotionPiece = NoPiece
+	}
+	return position.Move(move.From.Location, move.To, promotionPiece)
 }
 
 func NewStandardStartingPosition() *ChessPosition {
@@ -376,3 +376,55 @@ func TestNewStandardStartingPosition_FullmoveNumberIsOne(t *testing.T) {
 	pos := NewStandardStartingPosition()
 	assert.Equal(t, 1, pos.FullmoveNumber, "standard starting position should have FullmoveNumber 1 per FEN specification")
 }
+
+func TestApplyMove_Chess960Castle(t *testing.T) {
+	tests := []struct {
+		name     string
+		board    string
+		rights   CastlingRights
+		kingSide bool
+		expected string
+	}{
+		{"rook on the king's castled square", "Kf1 Rg1 ke8", CastlingRights{KingSide: true, KingSideRook: FileG}, true, "Kg1 Rf1 ke8"},
+		{"king does not move", "Kg1 Rh1 Rb1 ke8", CastlingRights{KingSide: true, QueenSide: true, QueenSideRook: FileB}, true, "Kg1 Rf1 Rb1 ke8"},
+		{"king and rook swap squares", "Kd1 Rc1 ke8", CastlingRights{QueenSide: true, QueenSideRook: FileC}, false, "Kc1 Rd1 ke8"},
+		{"king crosses the board", "Kg1 Rb1 ke8", CastlingRights{QueenSide: true, QueenSideRook: FileB}, false, "Kc1 Rd1 ke8"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			position := &ChessPosition{
+				Board:          parseBoard(test.board),
+				PlayerToMove:   WhitePiece,
+				CastlingRights: map[ColorType]CastlingRights{WhitePiece: test.rights, BlackPiece: {}},
+				FullmoveNumber: 1,
+			}
+			movement := NewChessMovement(position)
+			movement.Calculate()
+			for _, move := range movement.Moves {
+				if !move.IsCastle || move.CastlesKingSide() != test.kingSide {
+					continue
+				}
+				next := position.ApplyMove(move, NoPiece)
+				assert.Equal(t, parseBoard(test.expected).Occupied(), next.Board.Occupied())
+				assert.Equal(t, parseBoard(test.expected).ColorBitboard(WhitePiece), next.Board.ColorBitboard(WhitePiece))
+				assert.Equal(t, CastlingRights{}, next.CastlingRights[WhitePiece])
+				assert.Equal(t, computeHash(next), next.Hash())
+				return
+			}
+			t.Fatal("castle not generated")
+		})
+	}
+}
+
+func TestMove_Chess960RookLosesItsCastlingRight(t *testing.T) {
+	rights := CastlingRights{KingSide: true, QueenSide: true, KingSideRook: FileG, QueenSideRook: FileB}
+	position := &ChessPosition{
+		Board:          parseBoard("Rb1 Kf1 Rg1 rb8 kf8 rg8"),
+		PlayerToMove:   WhitePiece,
+		CastlingRights: map[ColorType]CastlingRights{WhitePiece: rights, BlackPiece: rights},
+	}
+
+	next := position.Move(ChessLocation{FileB, Rank1}, ChessLocation{FileB, Rank8}, NoPiece)
+	assert.Equal(t, CastlingRights{KingSide: true, KingSideRook: FileG}, next.CastlingRights[WhitePiece])
+	assert.Equal(t, CastlingRights{KingSide: true, KingSideRook: FileG}, next.CastlingRights[BlackPiece])
+}
@@ -14,18 +14,21 @@ const (
 	DrawStalemate
 	// DrawFiftyMove means the game ended in a draw by the fifty-move rule.
 	DrawFiftyMove
-	// DrawInsufficientMaterial means the game ended in a draw due to insufficient material.
+	// DrawInsufficientMaterial means the game ended in a draw because neither side had the material to checkmate.
 	DrawInsufficientMaterial
 	// DrawRepetition means the game ended in a draw by threefold repetition.
 	DrawRepetition
 	// DrawAgreement means the game ended in a draw by mutual agreement.
 	DrawAgreement
+	// DrawDeadPosition means the game ended in a draw because the pawns were locked and neither king could
+	// reach a pawn to take, so neither side could checkmate despite having the material.
+	DrawDeadPosition
 )
 
 // IsDraw returns true if the result represents any kind of draw.
 func (r GameResult) IsDraw() bool {
 	return r == DrawStalemate || r == DrawFiftyMove || r == DrawInsufficientMaterial ||
-		r == DrawRepetition || r == DrawAgreement
+		r == DrawRepetition || r == DrawAgreement || r == DrawDeadPosition
 }
 
 // IsDecided returns true if the game has ended (i.e., it is not InProgress).
@@ -52,68 +55,86 @@ func (r GameResult) String() string {
 		return "Draw (Threefold Repetition)"
 	case DrawAgreement:
 		return "Draw (Agreement)"
+	case DrawDeadPosition:
+		return "Draw (Dead Position)"
 	default:
 		return "Unknown"
 	}
 }
 
-// hasInsufficientMaterial returns true when neither side has enough material to
-// force checkmate. The recognised cases are:
-//   - K vs K
-//   - K+B vs K or K+N vs K (one minor piece total)
-//   - K+B vs K+B where both bishops travel on the same square colour
-func hasInsufficientMaterial(board *ChessBoard) bool {
-	var whiteKnights, whiteBishops, whiteOther int
-	var blackKnights, blackBishops, blackOther int
-	var whiteBishopColor, blackBishopColor int
+// ResultReason records how a game ended, which the result alone doesn't tell for a win.
+type ResultReason int
 
-	for square := range board.IterateSquares() {
-		p := square.Piece
-		if p.Piece == NoPiece || p.Piece == King {
-			continue
-		}
-		squareColor := (square.Location.File.ToIndex() + square.Location.Rank.ToIndex()) % 2
-		switch {
-		case p.Color == WhitePiece && p.Piece == Knight:
-			whiteKnights++
-		case p.Color == WhitePiece && p.Piece == Bishop:
-			whiteBishops++
-			whiteBishopColor = squareColor
-		case p.Color == BlackPiece && p.Piece == Knight:
-			blackKnights++
-		case p.Color == BlackPiece && p.Piece == Bishop:
-			blackBishops++
-			blackBishopColor = squareColor
-		default:
-			// Pawn, Rook, or Queen — sufficient mating material
-			if p.Color == WhitePiece {
-				whiteOther++
-			} else {
-				blackOther++
-			}
-		}
-	}
-
-	if whiteOther > 0 || blackOther > 0 {
-		return false
-	}
-
-	total := whiteKnights + whiteBishops + blackKnights + blackBishops
-
-	// K vs K
-	if total == 0 {
-		return true
-	}
+const (
+	// ReasonNone means the game is still in progress.
+	ReasonNone ResultReason = iota
+	// ReasonCheckmate means the side to move was checkmated.
+	ReasonCheckmate
+	// ReasonResignation means a player resigned.
+	ReasonResignation
+	// ReasonAgreement means the players agreed to a draw.
+	ReasonAgreement
+	// ReasonTimeout means a player ran out of time.
+	ReasonTimeout
+	// ReasonStalemate means the side to move had no legal move and was not in check.
+	ReasonStalemate
+	// ReasonFiftyMove means the game was drawn by the fifty-move rule.
+	ReasonFiftyMove
+	// ReasonRepetition means the game was drawn by repetition of the position.
+	ReasonRepetition
+	// ReasonInsufficientMaterial means neither side had the material to checkmate.
+	ReasonInsufficientMaterial
+	// ReasonDeadPosition means neither side could checkmate in a locked pawn structure.
+	ReasonDeadPosition
+)
 
-	// K+minor vs K
-	if total == 1 {
-		return true
+// ReasonOf returns the reason a result decided by the position was reached: checkmate for a win and the kind
+// of draw for a draw.
+func ReasonOf(result GameResult) ResultReason {
+	switch result {
+	case WhiteWins, BlackWins:
+		return ReasonCheckmate
+	case DrawStalemate:
+		return ReasonStalemate
+	case DrawFiftyMove:
+		return ReasonFiftyMove
+	case DrawInsufficientMaterial:
+		return ReasonInsufficientMaterial
+	case DrawRepetition:
+		return ReasonRepetition
+	case DrawAgreement:
+		return ReasonAgreement
+	case DrawDeadPosition:
+		return ReasonDeadPosition
+	default:
+		return ReasonNone
 	}
+}
 
-	// K+B vs K+B with bishops on the same square colour
-	if whiteKnights == 0 && whiteBishops == 1 && blackKnights == 0 && blackBishops == 1 {
-		return whiteBishopColor == blackBishopColor
+// String returns a human-readable description of the reason.
+func (r ResultReason) String() string {
+	switch r {
+	case ReasonNone:
+		return "None"
+	case ReasonCheckmate:
+		return "Checkmate"
+	case ReasonResignation:
+		return "Resignation"
+	case ReasonAgreement:
+		return "Agreement"
+	case ReasonTimeout:
+		return "Timeout"
+	case ReasonStalemate:
+		return "Stalemate"
+	case ReasonFiftyMove:
+		return "Fifty-Move Rule"
+	case ReasonRepetition:
+		return "Repetition"
+	case ReasonInsufficientMaterial:
+		return "Insufficient Material"
+	case ReasonDeadPosition:
+		return "Dead Position"
+	default:
+		return "Unknown"
 	}
-
-	return false
 }
@@ -8,7 +8,7 @@ import (
 
 func TestGameResult_IsDraw(t *testing.T) {
 	drawResults := []GameResult{
-		DrawStalemate, DrawFiftyMove, DrawInsufficientMaterial, DrawRepetition, DrawAgreement,
+		DrawStalemate, DrawFiftyMove, DrawInsufficientMaterial, DrawRepetition, DrawAgreement, DrawDeadPosition,
 	}
 	for _, r := range drawResults {
 		assert.True(t, r.IsDraw(), "%s should be a draw", r)
@@ -25,7 +25,7 @@ func TestGameResult_IsDecided(t *testing.T) {
 
 	decidedResults := []GameResult{
 		WhiteWins, BlackWins, DrawStalemate, DrawFiftyMove,
-		DrawInsufficientMaterial, DrawRepetition, DrawAgreement,
+		DrawInsufficientMaterial, DrawRepetition, DrawAgreement, DrawDeadPosition,
 	}
 	for _, r := range decidedResults {
 		assert.True(t, r.IsDecided(), "%s should be decided", r)
@@ -42,17 +42,36 @@ func TestGameResult_String(t *testing.T) {
 		DrawInsufficientMaterial: "Draw (Insufficient Material)",
 		DrawRepetition:           "Draw (Threefold Repetition)",
 		DrawAgreement:            "Draw (Agreement)",
+		DrawDeadPosition:         "Draw (Dead Position)",
 	}
 	for result, want := range cases {
 		assert.Equal(t, want, result.String())
 	}
 }
 
+func TestReasonOf(t *testing.T) {
+	cases := map[GameResult]ResultReason{
+		InProgress:               ReasonNone,
+		WhiteWins:                ReasonCheckmate,
+		BlackWins:                ReasonCheckmate,
+		DrawStalemate:            ReasonStalemate,
+		DrawFiftyMove:            ReasonFiftyMove,
+		DrawInsufficientMaterial: ReasonInsufficientMaterial,
+		DrawRepetition:           ReasonRepetition,
+		DrawAgreement:            ReasonAgreement,
+		DrawDeadPosition:         ReasonDeadPosition,
+	}
+	for result, want := range cases {
+		assert.Equal(t, want, ReasonOf(result), "%s", result)
+	}
+	assert.Equal(t, "Resignation", ReasonResignation.String())
+}
+
 func TestHasInsufficientMaterial_KvsK(t *testing.T) {
 	board := NewChessBoard()
 	board.SetSquare(ChessLocation{FileE, Rank1}, ChessPiece{King, WhitePiece})
 	board.SetSquare(ChessLocation{FileE, Rank8}, ChessPiece{King, BlackPiece})
-	assert.True(t, hasInsufficientMaterial(board))
+	assert.True(t, AnalyzeDeadPosition(&ChessPosition{Board: board}).IsDead())
 }
 
 func TestHasInsufficientMaterial_KBvsK(t *testing.T) {
@@ -60,7 +79,7 @@ func TestHasInsufficientMaterial_KBvsK(t *testing.T) {
 	board.SetSquare(ChessLocation{FileE, Rank1}, ChessPiece{King, WhitePiece})
 	board.SetSquare(ChessLocation{FileC, Rank1}, ChessPiece{Bishop, WhitePiece})
 	board.SetSquare(ChessLocation{FileE, Rank8}, ChessPiece{King, BlackPiece})
-	assert.True(t, hasInsufficientMaterial(board))
+	assert.True(t, AnalyzeDeadPosition(&ChessPosition{Board: board}).IsDead())
 }
 
 func TestHasInsufficientMaterial_KNvsK(t *testing.T) {
@@ -68,7 +87,7 @@ func TestHasInsufficientMaterial_KNvsK(t *testing.T) {
 	board.SetSquare(ChessLocation{FileE, Rank1}, ChessPiece{King, WhitePiece})
 	board.SetSquare(ChessLocation{FileG, Rank1}, ChessPiece{Knight, WhitePiece})
 	board.SetSquare(ChessLocation{FileE, Rank8}, ChessPiece{King, BlackPiece})
-	assert.True(t, hasInsufficientMaterial(board))
+	assert.True(t, AnalyzeDeadPosition(&ChessPosition{Board: board}).IsDead())
 }
 
 func TestHasInsufficientMaterial_KBvsKBSameColor(t *testing.T) {
@@ -78,7 +97,7 @@ func TestHasInsufficientMaterial_KBvsKBSameColor(t *testing.T) {
 	board.SetSquare(ChessLocation{FileC, Rank1}, ChessPiece{Bishop, WhitePiece}) // dark square
 	board.SetSquare(ChessLocation{FileE, Rank8}, ChessPiece{King, BlackPiece})
 	board.SetSquare(ChessLocation{FileA, Rank3}, ChessPiece{Bishop, BlackPiece}) // dark square (0+2=even)
-	assert.True(t, hasInsufficientMaterial(board))
+	assert.True(t, AnalyzeDeadPosition(&ChessPosition{Board: board}).IsDead())
 }
 
 func TestHasInsufficientMaterial_KBvsKBDifferentColor(t *testing.T) {
@@ -88,7 +107,7 @@ func TestHasInsufficientMaterial_KBvsKBDifferentColor(t *testing.T) {
 	board.SetSquare(ChessLocation{FileC, Rank1}, ChessPiece{Bishop, WhitePiece}) // dark square
 	board.SetSquare(ChessLocation{FileE, Rank8}, ChessPiece{King, BlackPiece})
 	board.SetSquare(ChessLocation{FileD, Rank1}, ChessPiece{Bishop, BlackPiece}) // light square (3+0=odd)
-	assert.False(t, hasInsufficientMaterial(board))
+	assert.False(t, AnalyzeDeadPosition(&ChessPosition{Board: board}).IsDead())
 }
 
 func TestHasInsufficientMaterial_WithPawn(t *testing.T) {
@@ -96,10 +115,10 @@ func TestHasInsufficientMaterial_WithPawn(t *testing.T) {
 	board.SetSquare(ChessLocation{FileE, Rank1}, ChessPiece{King, WhitePiece})
 	board.SetSquare(ChessLocation{FileE, Rank2}, ChessPiece{Pawn, WhitePiece})
 	board.SetSquare(ChessLocation{FileE, Rank8}, ChessPiece{King, BlackPiece})
-	assert.False(t, hasInsufficientMaterial(board))
+	assert.False(t, AnalyzeDeadPosition(&ChessPosition{Board: board}).IsDead())
 }
 
 func TestHasInsufficientMaterial_FullBoard(t *testing.T) {
 	board := NewStandardChessBoard()
-	assert.False(t, hasInsufficientMaterial(board))
+	assert.False(t, AnalyzeDeadPosition(&ChessPosition{Board: board}).IsDead())
 }
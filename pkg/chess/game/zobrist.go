@@ -0,0 +1,107 @@
+package game
+
+// Offsets into polyglotRandom.
+const (
+	polyglotCastlingOffset  = 768
+	polyglotEnPassantOffset = 772
+	polyglotTurnOffset      = 780
+)
+
+// Hash returns the 64 bit Zobrist hash of the position: its pieces, side to move, castling rights and, when a
+// pawn of the side to move can capture en passant, the en passant file. It uses the Polyglot random table, so
+// it is also the key of the position in a Polyglot opening book.
+//
+// Positions made by Move, CastleKingside and CastleQueenside have their hash updated from the previous
+// position; for other positions it is computed on first use, after which the position must not be changed.
+func (position *ChessPosition) Hash() uint64 {
+	if position.hash == 0 {
+		position.hash = computeHash(position)
+	}
+	return position.hash
+}
+
+// computeHash hashes a position from scratch.
+func computeHash(position *ChessPosition) uint64 {
+	hash := uint64(0)
+	for _, color := range AllColors {
+		for _, pieceType := range []PieceType{Pawn, Knight, Bishop, Rook, Queen, King} {
+			piece := ChessPiece{Piece: pieceType, Color: color}
+			for index := range position.Board.PieceBitboard(piece).Squares {
+				hash ^= pieceKey(piece, index)
+			}
+		}
+	}
+	return hash ^ castlingKey(position.CastlingRights) ^ enPassantKey(position) ^ turnKey(position.PlayerToMove)
+}
+
+// pieceKey returns the key of a piece on a square. Polyglot orders the pieces black pawn, white pawn, black
+// knight and so on up to the white king.
+func pieceKey(piece ChessPiece, index int) uint64 {
+	kind := pieceIndex(piece.Piece) * 2
+	if piece.Color == WhitePiece {
+		kind++
+	}
+	return polyglotRandom[kind*64+index]
+}
+
+func castlingKey(rights map[ColorType]CastlingRights) uint64 {
+	key := uint64(0)
+	white, black := rights[WhitePiece], rights[BlackPiece]
+	for i, allowed := range []bool{white.KingSide, white.QueenSide, black.KingSide, black.QueenSide} {
+		if allowed {
+			key ^= polyglotRandom[polyglotCastlingOffset+i]
+		}
+	}
+	return key
+}
+
+// enPassantKey returns the key of the en passant file, or zero when no pawn of the side to move stands
+// ready to capture en passant. Whether the capture would leave the king in check is not considered.
+func enPassantKey(position *ChessPosition) uint64 {
+	if !position.EnPassantSquare.IsOnBoard() {
+		return 0
+	}
+	target := position.EnPassantSquare.ToIndex()
+	pawns := position.Board.PieceBitboard(ChessPiece{Piece: Pawn, Color: position.PlayerToMove})
+	// the capturing pawns stand where a pawn of the other side on the target square would attack
+	if pawnAttackTable[colorIndex(position.PlayerToMove.OppositeColor())][target]&pawns == 0 {
+		return 0
+	}
+	return polyglotRandom[polyglotEnPassantOffset+position.EnPassantSquare.File.ToIndex()]
+}
+
+func turnKey(playerToMove ColorType) uint64 {
+	if playerToMove == WhitePiece {
+		return polyglotRandom[polyglotTurnOffset]
+	}
+	return 0
+}
+
+// hashAfterMove updates the hash of position for the board changes of a move, given as the pieces removed
+// from and placed on squares, and sets it on next.
+func hashAfterMove(position *ChessPosition, next *ChessPosition, removed []pieceOnSquare, placed []pieceOnSquare) {
+	hash := position.Hash()
+	for _, change := range append(removed, placed...) {
+		// Move does not check its squares, so an empty square may be moved from
+		if change.piece.Piece != NoPiece {
+			hash ^= pieceKey(change.piece, change.index)
+		}
+	}
+	hash ^= castlingKey(position.CastlingRights) ^ castlingKey(next.CastlingRights)
+	hash ^= enPassantKey(position) ^ enPassantKey(next)
+	hash ^= turnKey(position.PlayerToMove) ^ turnKey(next.PlayerToMove)
+	next.hash = hash
+}
+
+// hashAfterCastle sets the hash of next from the king and rook moving to their new squares.
+func hashAfterCastle(position *ChessPosition, next *ChessPosition, king ChessSquare, rook ChessSquare, newKing ChessLocation, newRook ChessLocation) {
+	hashAfterMove(position, next,
+		[]pieceOnSquare{{king.Piece, king.Location.ToIndex()}, {rook.Piece, rook.Location.ToIndex()}},
+		[]pieceOnSquare{{king.Piece, newKing.ToIndex()}, {rook.Piece, newRook.ToIndex()}})
+}
+
+// pieceOnSquare is a piece added to or removed from the board by a move.
+type pieceOnSquare struct {
+	piece ChessPiece
+	index int
+}
@@ -0,0 +1,108 @@
+package game
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// playUci plays space separated moves in UCI notation without checking them.
+func playUci(position *ChessPosition, moves string) *ChessPosition {
+	for _, move := range strings.Fields(moves) {
+		promotion := NoPiece
+		if len(move) == 5 {
+			promotion = PieceType(strings.ToUpper(move[4:])[0])
+		}
+		position = position.Move(ParseChessLocation(move[0:2]), ParseChessLocation(move[2:4]), promotion)
+	}
+	return position
+}
+
+// Keys from the Polyglot book format description, http://hgm.nubati.net/book_format.html
+func TestHash_PolyglotKeys(t *testing.T) {
+	tests := []struct {
+		moves string
+		key   uint64
+	}{
+		{"", 0x463b96181691fc9c},
+		{"e2e4", 0x823c9b50fd114196},
+		{"e2e4 d7d5", 0x0756b94461c50fb0},
+		{"e2e4 d7d5 e4e5", 0x662fafb965db29d4},
+		{"e2e4 d7d5 e4e5 f7f5", 0x22a48b5a8e47ff78},
+		{"e2e4 d7d5 e4e5 f7f5 e1e2", 0x652a607ca3f242c1},
+		{"e2e4 d7d5 e4e5 f7f5 e1e2 e8f7", 0x00fdd303c946bdd9},
+		{"a2a4 b7b5 h2h4 b5b4 c2c4", 0x3c8123ea7b067637},
+		{"a2a4 b7b5 h2h4 b5b4 c2c4 b4c3 a1a3", 0x5c3f9b829b279560},
+	}
+	for _, test := range tests {
+		t.Run(test.moves, func(t *testing.T) {
+			position := playUci(NewStandardStartingPosition(), test.moves)
+
+			assert.Equal(t, test.key, position.Hash())
+			assert.Equal(t, test.key, computeHash(position))
+		})
+	}
+}
+
+// assertIncrementalHashes walks every line to the given depth checking the hash kept by each move against one
+// computed from scratch.
+func assertIncrementalHashes(t *testing.T, position *ChessPosition, depth int) {
+	t.Helper()
+	if depth == 0 {
+		return
+	}
+	movement := NewChessMovement(position)
+	movement.Calculate()
+	for _, move := range movement.Moves {
+		promotions := []PieceType{NoPiece}
+		if move.IsPromotion {
+			promotions = PromotionPieces
+		}
+		for _, promotion := range promotions {
+			next := position.ApplyMove(move, promotion)
+			if !assert.Equal(t, computeHash(next), next.Hash(), "after %s", move.UciString(promotion)) {
+				return
+			}
+			assertIncrementalHashes(t, next, depth-1)
+		}
+	}
+}
+
+func TestHash_IncrementalMatchesComputed(t *testing.T) {
+	allRights := map[ColorType]CastlingRights{WhitePiece: {KingSide: true, QueenSide: true}, BlackPiece: {KingSide: true, QueenSide: true}}
+	positions := map[string]*ChessPosition{
+		"starting position": NewStandardStartingPosition(),
+		"castling, en passant and promotion": {
+			Board:          parseBoard("Ke1 Ra1 Rh1 Pb7 Pe5 Pg2 ke8 ra8 rh8 pd7 pf7 pa2"),
+			PlayerToMove:   BlackPiece,
+			CastlingRights: allRights,
+			FullmoveNumber: 1,
+		},
+	}
+	for name, position := range positions {
+		t.Run(name, func(t *testing.T) {
+			assertIncrementalHashes(t, position, 3)
+		})
+	}
+}
+
+func TestHash_EnPassantOnlyWhenCaptureIsPossible(t *testing.T) {
+	rights := map[ColorType]CastlingRights{}
+	withTarget := &ChessPosition{Board: parseBoard("Ke1 Pe4 ke8"), PlayerToMove: BlackPiece, CastlingRights: rights, EnPassantSquare: ChessLocation{FileE, Rank3}}
+	withoutTarget := &ChessPosition{Board: parseBoard("Ke1 Pe4 ke8"), PlayerToMove: BlackPiece, CastlingRights: rights}
+	assert.Equal(t, withoutTarget.Hash(), withTarget.Hash())
+
+	capturable := &ChessPosition{Board: parseBoard("Ke1 Pe4 ke8 pd4"), PlayerToMove: BlackPiece, CastlingRights: rights, EnPassantSquare: ChessLocation{FileE, Rank3}}
+	notCapturable := &ChessPosition{Board: parseBoard("Ke1 Pe4 ke8 pd4"), PlayerToMove: BlackPiece, CastlingRights: rights}
+	assert.NotEqual(t, notCapturable.Hash(), capturable.Hash())
+}
+
+func TestHash_DistinguishesSideToMoveAndCastling(t *testing.T) {
+	white := &ChessPosition{Board: parseBoard("Ke1 Rh1 ke8"), PlayerToMove: WhitePiece, CastlingRights: map[ColorType]CastlingRights{}}
+	black := &ChessPosition{Board: parseBoard("Ke1 Rh1 ke8"), PlayerToMove: BlackPiece, CastlingRights: map[ColorType]CastlingRights{}}
+	castling := &ChessPosition{Board: parseBoard("Ke1 Rh1 ke8"), PlayerToMove: WhitePiece, CastlingRights: map[ColorType]CastlingRights{WhitePiece: {KingSide: true}}}
+
+	assert.NotEqual(t, white.Hash(), black.Hash())
+	assert.NotEqual(t, white.Hash(), castling.Hash())
+}
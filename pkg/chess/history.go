@@ -0,0 +1,150 @@
+package chess
+
+import (
+	"fmt"
+	"maps"
+
+	"github.com/jerhon/chess/pkg/chess/game"
+)
+
+// MoveRecord describes a move that was played in a ChessGame along with the state it replaced,
+// which is enough to describe the move in a move list and to take it back.
+type MoveRecord struct {
+	// San is the move in standard algebraic notation
+	San string
+
+	From game.ChessLocation
+	To   game.ChessLocation
+
+	// Piece is the piece that moved, for castling this is the king
+	Piece game.ChessPiece
+	// Captured is the piece that was captured, NoPiece if the move was not a capture
+	Captured game.ChessPiece
+	// Promotion is the piece a pawn promoted to, NoPiece if the move was not a promotion
+	Promotion game.PieceType
+
+	IsCastle    bool
+	IsEnPassant bool
+	// CastlingRook is the square the rook started on when the move is a castle
+	CastlingRook game.ChessLocation
+
+	// The castling rights, en passant square and halfmove clock of the position before the move
+	PriorCastlingRights  map[game.ColorType]game.CastlingRights
+	PriorEnPassantSquare game.ChessLocation
+	PriorHalfmoveClock   int
+}
+
+func newMoveRecord(position *game.ChessPosition, move game.ChessMove, promotionPiece game.PieceType, sanText string) MoveRecord {
+	record := MoveRecord{
+		San:                  sanText,
+		From:                 move.From.Location,
+		To:                   move.To,
+		Piece:                move.From.Piece,
+		Captured:             position.Board.GetSquare(move.To).Piece,
+		IsCastle:             move.IsCastle,
+		PriorCastlingRights:  maps.Clone(position.CastlingRights),
+		PriorEnPassantSquare: position.EnPassantSquare,
+		PriorHalfmoveClock:   position.HalfmoveClock,
+	}
+
+	if move.IsPromotion {
+		record.Promotion = promotionPiece
+	}
+
+	// in Chess960 the king may castle onto its own rook's square
+	if move.IsCastle {
+		record.Captured = game.ChessPiece{}
+		record.CastlingRook = move.CastlingRook
+	}
+
+	// an en passant capture lands on an empty square, the captured pawn sits beside the moving pawn
+	if move.From.Piece.Piece == game.Pawn && move.To == position.EnPassantSquare && record.Captured.Piece == game.NoPiece {
+		record.IsEnPassant = true
+		record.Captured = position.Board.GetSquare(game.ChessLocation{File: move.To.File, Rank: move.From.Location.Rank}).Piece
+	}
+
+	return record
+}
+
+// History returns the moves played to reach the current position, oldest first.
+// Moves that were undone are not included until they are redone.
+func (g *ChessGame) History() []MoveRecord {
+	history := make([]MoveRecord, g.ply)
+	copy(history, g.records[:g.ply])
+	return history
+}
+
+// StartingPosition returns the position the game started from.
+func (g *ChessGame) StartingPosition() *game.ChessPosition {
+	return g.positions[0]
+}
+
+// Positions returns the starting position followed by the position after each move played, ending with the
+// current position.
+func (g *ChessGame) Positions() []*game.ChessPosition {
+	positions := make([]*game.ChessPosition, g.ply+1)
+	copy(positions, g.positions[:g.ply+1])
+	return positions
+}
+
+// Ply returns the number of half moves played to reach the current position.
+func (g *ChessGame) Ply() int {
+	return g.ply
+}
+
+// CanUndo returns true if there is a move to take back.
+func (g *ChessGame) CanUndo() bool {
+	return g.ply > 0
+}
+
+// CanRedo returns true if a move was undone and can be played again.
+func (g *ChessGame) CanRedo() bool {
+	return g.ply < len(g.records)
+}
+
+// Undo takes back the last move played. It returns false if there is no move to take back.
+// The move is kept so it can be replayed with Redo until a different move is played.
+func (g *ChessGame) Undo() bool {
+	if !g.CanUndo() {
+		return false
+	}
+
+	// taking a move back reopens a game the players ended and withdraws a draw offer
+	g.ending, g.drawOffer = nil, game.NoColor
+
+	g.positionHistory[g.position.Hash()]--
+	g.ply--
+	g.position = g.positions[g.ply]
+	g.calculate()
+	g.restartClock()
+	return true
+}
+
+// Redo replays the last move taken back with Undo. It returns false if there is no move to replay.
+func (g *ChessGame) Redo() bool {
+	if !g.CanRedo() {
+		return false
+	}
+
+	g.ply++
+	g.position = g.positions[g.ply]
+	g.calculate()
+	g.recordCurrentPosition()
+	g.restartClock()
+	return true
+}
+
+// GoToPly undoes or redoes moves until ply half moves have been played. Ply 0 is the starting position.
+func (g *ChessGame) GoToPly(ply int) error {
+	if ply < 0 || ply > len(g.records) {
+		return fmt.Errorf("ply %d is out of range, expected 0 to %d", ply, len(g.records))
+	}
+
+	for g.ply > ply {
+		g.Undo()
+	}
+	for g.ply < ply {
+		g.Redo()
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package chess
+
+import (
+	"testing"
+
+	"github.com/jerhon/chess/pkg/chess/fen"
+	"github.com/jerhon/chess/pkg/chess/game"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func playMoves(t *testing.T, g *ChessGame, moves ...string) {
+	t.Helper()
+	for _, move := range moves {
+		ok, err := g.TrySanMove(move)
+		require.True(t, ok, "move %s should succeed", move)
+		require.NoError(t, err)
+	}
+}
+
+func TestHistory_RecordsPlayedMoves(t *testing.T) {
+	g := NewGame()
+	playMoves(t, g, "e4", "d5", "exd5")
+
+	history := g.History()
+	require.Len(t, history, 3)
+	assert.Equal(t, "e4", history[0].San)
+	assert.Equal(t, game.ChessLocation{File: game.FileE, Rank: game.Rank2}, history[0].From)
+	assert.Equal(t, game.ChessLocation{File: game.FileE, Rank: game.Rank4}, history[0].To)
+	assert.Equal(t, game.ChessPiece{Piece: game.Pawn, Color: game.WhitePiece}, history[0].Piece)
+	assert.Equal(t, game.ChessPiece{}, history[0].Captured)
+
+	assert.Equal(t, game.ChessLocation{File: game.FileE, Rank: game.Rank3}, history[1].PriorEnPassantSquare)
+	assert.Equal(t, game.ChessPiece{Piece: game.Pawn, Color: game.BlackPiece}, history[2].Captured)
+	assert.Equal(t, 3, g.Ply())
+}
+
+func TestHistory_RecordsCastleEnPassantAndPromotion(t *testing.T) {
+	g := newGameFromFen(t, "4k3/1P6/8/3pP3/8/8/8/R3K3 w Q d6 5 30")
+	playMoves(t, g, "exd6", "Kf7", "b8=N", "Ke6", "O-O-O")
+
+	history := g.History()
+	require.Len(t, history, 5)
+
+	assert.True(t, history[0].IsEnPassant)
+	assert.Equal(t, game.ChessPiece{Piece: game.Pawn, Color: game.BlackPiece}, history[0].Captured)
+	assert.Equal(t, 5, history[0].PriorHalfmoveClock)
+
+	assert.Equal(t, game.Knight, history[2].Promotion)
+
+	assert.True(t, history[4].IsCastle)
+	assert.Equal(t, game.ChessPiece{Piece: game.King, Color: game.WhitePiece}, history[4].Piece)
+	assert.Equal(t, game.CastlingRights{QueenSide: true}, history[4].PriorCastlingRights[game.WhitePiece])
+}
+
+func TestUndoRedo_RestoresPositions(t *testing.T) {
+	g := NewGame()
+	start := fen.ToFenString(g.GetPosition())
+	playMoves(t, g, "e4", "e5")
+	afterE5 := fen.ToFenString(g.GetPosition())
+
+	assert.True(t, g.Undo())
+	assert.True(t, g.Undo())
+	assert.False(t, g.Undo())
+	assert.Equal(t, start, fen.ToFenString(g.GetPosition()))
+	assert.Empty(t, g.History())
+	assert.True(t, g.CanRedo())
+
+	assert.True(t, g.Redo())
+	assert.True(t, g.Redo())
+	assert.False(t, g.Redo())
+	assert.Equal(t, afterE5, fen.ToFenString(g.GetPosition()))
+	assert.Len(t, g.History(), 2)
+}
+
+func TestUndo_NewMoveDiscardsRedo(t *testing.T) {
+	g := NewGame()
+	playMoves(t, g, "e4", "e5")
+
+	assert.True(t, g.Undo())
+	playMoves(t, g, "c5")
+
+	assert.False(t, g.CanRedo())
+	history := g.History()
+	require.Len(t, history, 2)
+	assert.Equal(t, "c5", history[1].San)
+}
+
+func TestGoToPly(t *testing.T) {
+	g := NewGame()
+	playMoves(t, g, "e4", "e5", "Nf3", "Nc6")
+
+	require.NoError(t, g.GoToPly(1))
+	assert.Equal(t, 1, g.Ply())
+	assert.Equal(t, game.BlackPiece, g.GetPosition().PlayerToMove)
+
+	require.NoError(t, g.GoToPly(4))
+	assert.Equal(t, 4, g.Ply())
+	assert.Len(t, g.History(), 4)
+
+	assert.Error(t, g.GoToPly(5))
+	assert.Error(t, g.GoToPly(-1))
+}
+
+func TestUndo_KeepsRepetitionCountConsistent(t *testing.T) {
+	g := NewGame()
+
+	// The start position occurs for the second time after these moves.
+	playMoves(t, g, "Nc3", "Nc6", "Nb1", "Nb8")
+
+	// Undoing and replaying the same moves must not count the start position again.
+	for i := 0; i < 3; i++ {
+		require.NoError(t, g.GoToPly(0))
+		require.NoError(t, g.GoToPly(4))
+		assert.Equal(t, game.InProgress, g.GetResult())
+	}
+
+	// A genuine third occurrence is still a draw, and undoing it resumes the game.
+	playMoves(t, g, "Nc3", "Nc6", "Nb1", "Nb8")
+	assert.Equal(t, game.DrawRepetition, g.GetResult())
+
+	assert.True(t, g.Undo())
+	assert.Equal(t, game.InProgress, g.GetResult())
+	assert.True(t, g.Redo())
+	assert.Equal(t, game.DrawRepetition, g.GetResult())
+}
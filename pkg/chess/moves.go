@@ -0,0 +1,78 @@
+package chess
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jerhon/chess/pkg/chess/game"
+	"github.com/jerhon/chess/pkg/chess/san"
+)
+
+// TryUciMove plays a move given in UCI long algebraic notation, e.g. e2e4, e1g1 for castling or e7e8q
+// for a promotion. It returns the move in standard algebraic notation.
+func (g *ChessGame) TryUciMove(uciText string) (string, error) {
+	from, to, promotionPiece, err := parseUciMove(uciText)
+	if err != nil {
+		return "", err
+	}
+	return g.TryMove(from, to, promotionPiece)
+}
+
+// TryMove plays the legal move from one square to another. Castling is expressed as the king's move,
+// e.g. e1 to g1, or as the king capturing its own rook as in Chess960, e.g. e1 to h1. promotionPiece
+// is required for promotions and must be NoPiece otherwise. It returns the move in standard algebraic
+// notation.
+func (g *ChessGame) TryMove(from game.ChessLocation, to game.ChessLocation, promotionPiece game.PieceType) (string, error) {
+	if err := g.checkInProgress(); err != nil {
+		return "", err
+	}
+
+	for _, move := range g.GetLegalMoves() {
+		kingTakesRook := move.IsCastle && move.CastlingRook == to
+		if move.From.Location != from || (move.To != to && !kingTakesRook) {
+			continue
+		}
+
+		if move.IsPromotion {
+			switch promotionPiece {
+			case game.Queen, game.Rook, game.Bishop, game.Knight:
+			default:
+				return "", fmt.Errorf("move %s%s requires a promotion piece of q, r, b or n", from, to)
+			}
+		} else if promotionPiece != game.NoPiece {
+			return "", fmt.Errorf("move %s%s is not a promotion", from, to)
+		}
+
+		sanText := san.FromMove(g.position, move, promotionPiece)
+		g.playMove(move, promotionPiece, sanText)
+		return sanText, nil
+	}
+
+	return "", fmt.Errorf("illegal move %s%s", from, to)
+}
+
+// parseUciMove splits a UCI move such as e2e4 or e7e8q into its squares and promotion piece.
+func parseUciMove(uciText string) (game.ChessLocation, game.ChessLocation, game.PieceType, error) {
+	uciText = strings.TrimSpace(uciText)
+	if len(uciText) != 4 && len(uciText) != 5 {
+		return game.ChessLocation{}, game.ChessLocation{}, game.NoPiece, fmt.Errorf("invalid UCI move %q, expected a move such as e2e4 or e7e8q", uciText)
+	}
+
+	from := game.ParseChessLocation(uciText[0:2])
+	to := game.ParseChessLocation(uciText[2:4])
+	if !from.IsOnBoard() || !to.IsOnBoard() {
+		return game.ChessLocation{}, game.ChessLocation{}, game.NoPiece, fmt.Errorf("invalid UCI move %q, squares must be a1 to h8", uciText)
+	}
+
+	promotionPiece := game.NoPiece
+	if len(uciText) == 5 {
+		promotionPiece = game.PieceType(strings.ToUpper(uciText[4:])[0])
+		switch promotionPiece {
+		case game.Queen, game.Rook, game.Bishop, game.Knight:
+		default:
+			return game.ChessLocation{}, game.ChessLocation{}, game.NoPiece, fmt.Errorf("invalid UCI move %q, promotion piece must be q, r, b or n", uciText)
+		}
+	}
+
+	return from, to, promotionPiece, nil
+}
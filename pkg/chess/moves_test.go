@@ -0,0 +1,127 @@
+package chess
+
+import (
+	"testing"
+
+	"github.com/jerhon/chess/pkg/chess/fen"
+	"github.com/jerhon/chess/pkg/chess/game"
+	"github.com/jerhon/chess/pkg/chess/san"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTryUciMove(t *testing.T) {
+	tests := []struct {
+		name        string
+		fen         string
+		uci         string
+		expectedSan string
+		expectErr   bool
+	}{
+		{"pawn push", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", "e2e4", "e4", false},
+		{"knight move", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", "g1f3", "Nf3", false},
+		{"illegal move", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", "e2e5", "", true},
+		{"malformed move", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", "e2", "", true},
+		{"castle king side as king move", "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", "e1g1", "O-O", false},
+		{"castle queen side as king move", "r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 0 1", "e8c8", "O-O-O", false},
+		{"castle without rights", "r3k2r/8/8/8/8/8/8/R3K2R w kq - 0 1", "e1g1", "", true},
+		{"en passant", "4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 2", "e5d6", "exd6", false},
+		{"promotion", "4k3/1P6/8/8/8/8/8/4K3 w - - 0 1", "b7b8q", "b8=Q+", false},
+		{"underpromotion", "4k3/1P6/8/8/8/8/8/4K3 w - - 0 1", "b7b8n", "b8=N", false},
+		{"promotion without piece", "4k3/1P6/8/8/8/8/8/4K3 w - - 0 1", "b7b8", "", true},
+		{"promotion piece on a normal move", "4k3/1P6/8/8/8/8/8/4K3 w - - 0 1", "e1e2q", "", true},
+		{"checkmate", "6k1/5ppp/8/8/8/8/8/R3K3 w - - 0 1", "a1a8", "Ra8#", false},
+		{"file disambiguation", "4k3/8/8/8/8/8/8/R4RK1 w - - 0 1", "a1d1", "Rad1", false},
+		{"rank disambiguation", "4k3/8/8/R7/8/8/8/R3K3 w - - 0 1", "a5a3", "R5a3", false},
+		{"file and rank disambiguation", "4k3/8/8/8/8/Q1Q5/8/Q3K3 w - - 0 1", "a3b2", "Qa3b2", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			g := newGameFromFen(t, test.fen)
+			sanText, err := g.TryUciMove(test.uci)
+			if test.expectErr {
+				assert.Error(t, err)
+				assert.Equal(t, 0, g.Ply(), "a rejected move must not be played")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedSan, sanText)
+			assert.Equal(t, test.expectedSan, g.History()[0].San)
+		})
+	}
+}
+
+func TestTryMove_CastleMovesRook(t *testing.T) {
+	g := newGameFromFen(t, "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1")
+
+	sanText, err := g.TryMove(game.ChessLocation{File: game.FileE, Rank: game.Rank1}, game.ChessLocation{File: game.FileG, Rank: game.Rank1}, game.NoPiece)
+	require.NoError(t, err)
+	assert.Equal(t, "O-O", sanText)
+
+	piece, ok := g.GetPosition().Board.GetPiece(game.ChessLocation{File: game.FileF, Rank: game.Rank1})
+	assert.True(t, ok)
+	assert.Equal(t, game.ChessPiece{Piece: game.Rook, Color: game.WhitePiece}, piece)
+}
+
+func TestTryMove_RejectedAfterGameOver(t *testing.T) {
+	g := newGameFromFen(t, "6k1/5ppp/8/8/8/8/8/R3K3 w - - 0 1")
+	_, err := g.TryUciMove("a1a8")
+	require.NoError(t, err)
+
+	_, err = g.TryUciMove("g8h8")
+	assert.Error(t, err)
+}
+
+func TestTrySanMove_AcceptsGeneratedSan(t *testing.T) {
+	positions := []string{
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 b kq - 0 1",
+		"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+	}
+
+	for _, fenString := range positions {
+		g := newGameFromFen(t, fenString)
+		for _, move := range g.GetLegalMoves() {
+			promotions := []game.PieceType{game.NoPiece}
+			if move.IsPromotion {
+				promotions = game.PromotionPieces
+			}
+			for _, promotion := range promotions {
+				sanText := san.FromMove(g.GetPosition(), move, promotion)
+				expected := fen.ToFenString(g.GetPosition().ApplyMove(move, promotion))
+
+				replay := newGameFromFen(t, fenString)
+				ok, err := replay.TrySanMove(sanText)
+				require.True(t, ok, sanText)
+				require.NoError(t, err, sanText)
+				assert.Equal(t, expected, fen.ToFenString(replay.GetPosition()), sanText)
+				assert.Equal(t, sanText, replay.History()[0].San)
+			}
+		}
+	}
+}
+
+func TestTrySanMove_RecordsNormalizedSan(t *testing.T) {
+	g := newGameFromFen(t, "6k1/5ppp/8/8/8/8/8/R3K3 w - - 0 1")
+	playMoves(t, g, "Ra1a8")
+	assert.Equal(t, "Ra8#", g.History()[0].San)
+}
+
+func TestTryUciMove_KingTakesRookCastles(t *testing.T) {
+	g := newGameFromFen(t, "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1")
+	sanText, err := g.TryUciMove("e1h1")
+	require.NoError(t, err)
+	assert.Equal(t, "O-O", sanText)
+
+	g = newGameFromFen(t, "1r4kr/8/8/8/8/8/8/1R2N1KR w HBhb - 0 1")
+	_, err = g.TryUciMove("g1b1")
+	assert.Error(t, err, "the knight stands on the king's path")
+
+	g = newGameFromFen(t, "1r4kr/8/8/8/8/8/8/1R4KR w HBhb - 0 1")
+	sanText, err = g.TryUciMove("g1b1")
+	require.NoError(t, err)
+	assert.Equal(t, "O-O-O", sanText)
+	assert.Equal(t, "1r4kr/8/8/8/8/8/8/2KR3R b hb - 1 1", fen.ToShredderFenString(g.GetPosition()))
+}
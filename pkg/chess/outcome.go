@@ -0,0 +1,143 @@
+package chess
+
+import (
+	"fmt"
+
+	"github.com/jerhon/chess/pkg/chess/game"
+)
+
+// gameEnding is the result of a game ended by the players rather than by the position.
+type gameEnding struct {
+	result game.GameResult
+	reason game.ResultReason
+}
+
+// GetResultReason returns how the game ended, ReasonNone while it is in progress.
+func (g *ChessGame) GetResultReason() game.ResultReason {
+	_, reason := g.result()
+	return reason
+}
+
+// result returns the result of the game and how it was reached. It leaves the game as it is, a fallen flag
+// decides the result each time it is read.
+func (g *ChessGame) result() (game.GameResult, game.ResultReason) {
+	if g.ending != nil {
+		return g.ending.result, g.ending.reason
+	}
+	result := g.positionResult()
+	if result == game.InProgress {
+		if flagResult, flagged := g.flagResult(); flagged {
+			return flagResult, game.ReasonTimeout
+		}
+	}
+	return result, game.ReasonOf(result)
+}
+
+// Resign ends the game as a win for the opponent of color.
+func (g *ChessGame) Resign(color game.ColorType) error {
+	if err := g.checkInProgress(); err != nil {
+		return err
+	}
+
+	result := game.WhiteWins
+	switch color {
+	case game.WhitePiece:
+		result = game.BlackWins
+	case game.BlackPiece:
+	default:
+		return fmt.Errorf("invalid color %q, expected white or black", color)
+	}
+	g.end(result, game.ReasonResignation)
+	return nil
+}
+
+// OfferDraw offers a draw from color to its opponent. The offer stands until the opponent accepts or declines
+// it, or makes a move.
+func (g *ChessGame) OfferDraw(color game.ColorType) error {
+	if err := g.checkInProgress(); err != nil {
+		return err
+	}
+	if color != game.WhitePiece && color != game.BlackPiece {
+		return fmt.Errorf("invalid color %q, expected white or black", color)
+	}
+	if g.drawOffer == color.OppositeColor() {
+		return fmt.Errorf("a draw offer from the opponent is open, accept or decline it")
+	}
+	g.drawOffer = color
+	return nil
+}
+
+// DrawOffer returns the color whose draw offer is open, NoColor when there is none.
+func (g *ChessGame) DrawOffer() game.ColorType {
+	return g.drawOffer
+}
+
+// AcceptDraw accepts the open draw offer for color, ending the game as a draw by agreement. Only the opponent
+// of the offering player can accept it.
+func (g *ChessGame) AcceptDraw(color game.ColorType) error {
+	if err := g.checkDrawAnswer(color, "accepted"); err != nil {
+		return err
+	}
+	g.end(game.DrawAgreement, game.ReasonAgreement)
+	return nil
+}
+
+// DeclineDraw declines the open draw offer for color and the game goes on. Only the opponent of the offering
+// player can decline it.
+func (g *ChessGame) DeclineDraw(color game.ColorType) error {
+	if err := g.checkDrawAnswer(color, "declined"); err != nil {
+		return err
+	}
+	g.drawOffer = game.NoColor
+	return nil
+}
+
+// checkDrawAnswer returns an error unless the game is in progress and color can answer the open draw offer.
+func (g *ChessGame) checkDrawAnswer(color game.ColorType, answer string) error {
+	if err := g.checkInProgress(); err != nil {
+		return err
+	}
+	if g.drawOffer == game.NoColor {
+		return fmt.Errorf("no draw offer is open")
+	}
+	if color != g.drawOffer.OppositeColor() {
+		return fmt.Errorf("the draw offer can only be %s by the opponent of the offering player", answer)
+	}
+	return nil
+}
+
+// ClaimDraw ends the game as a draw when the player to move can claim one under the draw rules, see
+// CanClaimDraw.
+func (g *ChessGame) ClaimDraw() error {
+	if err := g.checkInProgress(); err != nil {
+		return err
+	}
+	reason, ok := g.CanClaimDraw()
+	if !ok {
+		return fmt.Errorf("no draw can be claimed, the position has occurred %d times and the halfmove clock is %d",
+			g.positionHistory[g.position.Hash()], g.position.HalfmoveClock)
+	}
+
+	result := game.DrawRepetition
+	if reason == game.ReasonFiftyMove {
+		result = game.DrawFiftyMove
+	}
+	g.end(result, reason)
+	return nil
+}
+
+func (g *ChessGame) end(result game.GameResult, reason game.ResultReason) {
+	g.ending = &gameEnding{result: result, reason: reason}
+	g.drawOffer = game.NoColor
+	if g.clock != nil {
+		g.clock.Stop()
+	}
+}
+
+// checkInProgress returns an *ErrGameOver once the game is over.
+func (g *ChessGame) checkInProgress() error {
+	if result, reason := g.result(); result != game.InProgress {
+		return &ErrGameOver{Result: result, Reason: reason}
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package chess
+
+import (
+	"testing"
+
+	"github.com/jerhon/chess/pkg/chess/game"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResign(t *testing.T) {
+	g := NewGame()
+	playMoves(t, g, "e4")
+
+	require.NoError(t, g.Resign(game.BlackPiece))
+	assert.Equal(t, game.WhiteWins, g.GetResult())
+	assert.Equal(t, game.ReasonResignation, g.GetResultReason())
+
+	_, err := g.TrySanMove("e5")
+	assert.ErrorContains(t, err, "game is over")
+	assert.Error(t, g.Resign(game.WhitePiece))
+
+	// taking the last move back reopens the game
+	require.True(t, g.Undo())
+	assert.Equal(t, game.InProgress, g.GetResult())
+	assert.Equal(t, game.ReasonNone, g.GetResultReason())
+
+	assert.Error(t, g.Resign(game.NoColor))
+	require.NoError(t, g.Resign(game.WhitePiece))
+	assert.Equal(t, game.BlackWins, g.GetResult())
+}
+
+func TestOfferDraw_Accepted(t *testing.T) {
+	g := NewGame()
+	playMoves(t, g, "e4")
+
+	assert.Error(t, g.AcceptDraw(game.BlackPiece))
+	require.NoError(t, g.OfferDraw(game.WhitePiece))
+	assert.Equal(t, game.WhitePiece, g.DrawOffer())
+	assert.Error(t, g.OfferDraw(game.BlackPiece))
+
+	require.NoError(t, g.AcceptDraw(game.BlackPiece))
+	assert.Equal(t, game.DrawAgreement, g.GetResult())
+	assert.Equal(t, game.ReasonAgreement, g.GetResultReason())
+	assert.Equal(t, game.NoColor, g.DrawOffer())
+}
+
+func TestOfferDraw_Declined(t *testing.T) {
+	g := NewGame()
+	require.NoError(t, g.OfferDraw(game.BlackPiece))
+	require.NoError(t, g.DeclineDraw(game.WhitePiece))
+	assert.Equal(t, game.NoColor, g.DrawOffer())
+	assert.Error(t, g.DeclineDraw(game.WhitePiece))
+	assert.Error(t, g.AcceptDraw(game.WhitePiece))
+	assert.Equal(t, game.InProgress, g.GetResult())
+}
+
+func TestOfferDraw_OwnOfferCannotBeAccepted(t *testing.T) {
+	g := NewGame()
+	require.NoError(t, g.OfferDraw(game.WhitePiece))
+
+	assert.ErrorContains(t, g.AcceptDraw(game.WhitePiece), "can only be accepted by")
+	assert.Error(t, g.AcceptDraw(game.NoColor))
+	assert.Equal(t, game.InProgress, g.GetResult())
+	assert.Equal(t, game.WhitePiece, g.DrawOffer())
+
+	require.NoError(t, g.AcceptDraw(game.BlackPiece))
+	assert.Equal(t, game.DrawAgreement, g.GetResult())
+}
+
+func TestOfferDraw_OwnOfferCannotBeDeclined(t *testing.T) {
+	g := NewGame()
+	require.NoError(t, g.OfferDraw(game.WhitePiece))
+
+	assert.ErrorContains(t, g.DeclineDraw(game.WhitePiece), "can only be declined by")
+	assert.Error(t, g.DeclineDraw(game.NoColor))
+	assert.Equal(t, game.WhitePiece, g.DrawOffer())
+
+	require.NoError(t, g.DeclineDraw(game.BlackPiece))
+	assert.Equal(t, game.NoColor, g.DrawOffer())
+}
+
+func TestOfferDraw_CannotBeAnsweredOnceTheGameIsOver(t *testing.T) {
+	// black offers a draw and mates with the offer still standing
+	g := NewGame()
+	playMoves(t, g, "f3", "e5", "g4")
+	require.NoError(t, g.OfferDraw(game.BlackPiece))
+	playMoves(t, g, "Qh4#")
+
+	assert.ErrorContains(t, g.DeclineDraw(game.WhitePiece), "game is over")
+	assert.ErrorContains(t, g.AcceptDraw(game.WhitePiece), "game is over")
+	assert.Equal(t, game.BlackWins, g.GetResult())
+}
+
+func TestOfferDraw_ExpiresWhenTheOpponentMoves(t *testing.T) {
+	g := NewGame()
+
+	// an offer made before moving stands through the offering player's own move
+	require.NoError(t, g.OfferDraw(game.WhitePiece))
+	playMoves(t, g, "e4")
+	assert.Equal(t, game.WhitePiece, g.DrawOffer())
+
+	// and lapses once the opponent moves instead of answering
+	playMoves(t, g, "e5")
+	assert.Equal(t, game.NoColor, g.DrawOffer())
+	assert.Error(t, g.AcceptDraw(game.BlackPiece))
+}
+
+func TestClaimDraw_NotClaimable(t *testing.T) {
+	g := NewGame()
+	playMoves(t, g, "Nf3", "Nf6", "Ng1", "Ng8")
+
+	err := g.ClaimDraw()
+	assert.ErrorContains(t, err, "occurred 2 times")
+	assert.Equal(t, game.InProgress, g.GetResult())
+
+	require.NoError(t, g.Resign(game.WhitePiece))
+	assert.ErrorContains(t, g.ClaimDraw(), "game is over")
+}
+
+func TestGetResultReason_FromThePosition(t *testing.T) {
+	g := NewGame()
+	playMoves(t, g, "f3", "e5", "g4", "Qh4#")
+	assert.Equal(t, game.BlackWins, g.GetResult())
+	assert.Equal(t, game.ReasonCheckmate, g.GetResultReason())
+
+	stalemate := newGameFromFen(t, "k7/8/1Q6/8/8/8/8/7K b - - 0 1")
+	assert.Equal(t, game.ReasonStalemate, stalemate.GetResultReason())
+}
+
+func TestGetResultReason_LockedPosition(t *testing.T) {
+	g := newGameFromFen(t, "4k3/8/8/1p1p1p1p/pPpPpPpP/P1P1P1P1/8/4K3 w - - 0 40")
+	assert.Equal(t, game.DrawDeadPosition, g.GetResult())
+	assert.Equal(t, game.ReasonDeadPosition, g.GetResultReason())
+
+	// until the en passant capture black's last move allows is taken
+	g = newGameFromFen(t, "4k3/8/4p3/1p1pPp1p/pPpP1PpP/P1P3P1/8/4K3 w - d6 0 40")
+	assert.Equal(t, game.InProgress, g.GetResult())
+	playMoves(t, g, "exd6")
+	assert.Equal(t, game.InProgress, g.GetResult())
+}
@@ -0,0 +1,82 @@
+// Package perft counts the leaf nodes of the legal move tree to verify the move generator
+// against published results, see https://www.chessprogramming.org/Perft_Results
+package perft
+
+import (
+	"sort"
+
+	"github.com/jerhon/chess/pkg/chess/game"
+)
+
+// DivideEntry is the node count below a single root move.
+type DivideEntry struct {
+	// Move is the root move in UCI long algebraic notation, e.g. e2e4 or e7e8q
+	Move  string
+	Nodes uint64
+}
+
+// Perft returns the number of leaf nodes reachable from position in exactly depth plies.
+// Promotions count once per promotion piece and castling counts as a single move.
+func Perft(position *game.ChessPosition, depth int) uint64 {
+	if depth <= 0 {
+		return 1
+	}
+
+	movement := game.NewChessMovement(position)
+	movement.Calculate()
+
+	// bulk count the final ply instead of playing each move
+	if depth == 1 {
+		nodes := uint64(0)
+		for _, move := range movement.Moves {
+			if move.IsPromotion {
+				nodes += uint64(len(game.PromotionPieces))
+			} else {
+				nodes++
+			}
+		}
+		return nodes
+	}
+
+	nodes := uint64(0)
+	for _, move := range movement.Moves {
+		for _, promotion := range promotionChoices(move) {
+			nodes += Perft(position.ApplyMove(move, promotion), depth-1)
+		}
+	}
+	return nodes
+}
+
+// Divide returns the perft node count below each legal root move, sorted by move.
+// The sum of the entries equals Perft(position, depth).
+func Divide(position *game.ChessPosition, depth int) []DivideEntry {
+	entries := []DivideEntry{}
+	if depth <= 0 {
+		return entries
+	}
+
+	movement := game.NewChessMovement(position)
+	movement.Calculate()
+
+	for _, move := range movement.Moves {
+		for _, promotion := range promotionChoices(move) {
+			entries = append(entries, DivideEntry{
+				Move:  move.UciString(promotion),
+				Nodes: Perft(position.ApplyMove(move, promotion), depth-1),
+			})
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Move < entries[j].Move
+	})
+	return entries
+}
+
+// promotionChoices returns the promotion pieces to try for a move, or a single NoPiece for other moves.
+func promotionChoices(move game.ChessMove) []game.PieceType {
+	if move.IsPromotion {
+		return game.PromotionPieces
+	}
+	return []game.PieceType{game.NoPiece}
+}
@@ -0,0 +1,165 @@
+package perft
+
+import (
+	"testing"
+
+	"github.com/jerhon/chess/pkg/chess/fen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Node counts from https://www.chessprogramming.org/Perft_Results
+var perftPositions = []struct {
+	name   string
+	fen    string
+	counts []uint64
+}{
+	{
+		name:   "start position",
+		fen:    "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+		counts: []uint64{20, 400, 8902, 197281},
+	},
+	{
+		name:   "kiwipete",
+		fen:    "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		counts: []uint64{48, 2039, 97862},
+	},
+	{
+		name:   "position 3",
+		fen:    "8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+		counts: []uint64{14, 191, 2812, 43238, 674624},
+	},
+	{
+		name:   "position 4",
+		fen:    "r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+		counts: []uint64{6, 264, 9467, 422333},
+	},
+	{
+		name:   "position 4 mirrored",
+		fen:    "r2q1rk1/pP1p2pp/Q4n2/bbp1p3/Np6/1B3NBn/pPPP1PPP/R3K2R b KQ - 0 1",
+		counts: []uint64{6, 264, 9467, 422333},
+	},
+	{
+		name:   "position 5",
+		fen:    "rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8",
+		counts: []uint64{44, 1486, 62379},
+	},
+	{
+		name:   "position 6",
+		fen:    "r4rk1/1pp1qppp/p1np1n2/2b1p1B1/2B1P1b1/P1NP1N2/1PP1QPPP/R4RK1 w - - 0 10",
+		counts: []uint64{46, 2079, 89890},
+	},
+}
+
+// Chess960 node counts from https://www.chessprogramming.org/Chess960_Perft_Results, given in Shredder-FEN
+var chess960Positions = []struct {
+	name   string
+	fen    string
+	counts []uint64
+}{
+	{
+		name:   "king castles queen side past its inner rook",
+		fen:    "bqnb1rkr/pp3ppp/3ppn2/2p5/5P2/P2P4/NPP1P1PP/BQ1BNRKR w HFhf - 2 9",
+		counts: []uint64{21, 528, 12189, 326672},
+	},
+	{
+		name:   "king on the g file",
+		fen:    "2nnrbkr/p1qppppp/8/1ppb4/6PP/3PP3/PPP2P2/BQNNRBKR w HEhe - 1 9",
+		counts: []uint64{21, 807, 18002, 667366},
+	},
+	{
+		name:   "rooks beside the king",
+		fen:    "b1q1rrkb/pppppppp/3nn3/8/P7/1PPP4/4PPPP/BQNNRKRB w GE - 1 9",
+		counts: []uint64{20, 479, 10471, 273318},
+	},
+}
+
+// Edge cases for en passant, castling and promotion, from the perft suite collected at
+// http://www.talkchess.com/forum/viewtopic.php?t=47318
+var edgeCasePositions = []struct {
+	name  string
+	fen   string
+	depth int
+	nodes uint64
+}{
+	{"en passant capture exposes the king", "3k4/3p4/8/K1P4r/8/8/8/8 b - - 0 1", 6, 1134888},
+	{"en passant capture checks the opponent", "8/8/4k3/8/2p5/8/B2P2K1/8 w - - 0 1", 6, 1015133},
+	{"short castling gives check", "5k2/8/8/8/8/8/8/4K2R w K - 0 1", 6, 661072},
+	{"long castling gives check", "3k4/8/8/8/8/8/8/R3K3 w Q - 0 1", 6, 803711},
+	{"promote to give check", "4k3/1P6/8/8/8/8/K7/8 w - - 0 1", 6, 217342},
+	{"underpromote to check", "8/P1k5/K7/8/8/8/8/8 w - - 0 1", 6, 92683},
+	{"self stalemate", "K1k5/8/P7/8/8/8/8/8 w - - 0 1", 6, 2217},
+	{"stalemate and checkmate", "8/k1P5/8/1K6/8/8/8/8 w - - 0 1", 7, 567584},
+	{"double check", "8/8/2k5/5q2/5n2/8/5K2/8 b - - 0 1", 4, 23527},
+}
+
+func TestPerft(t *testing.T) {
+	for _, test := range perftPositions {
+		position, err := fen.ParseFen(test.fen)
+		require.NoError(t, err)
+
+		for i, expected := range test.counts {
+			depth := i + 1
+			t.Run(test.name, func(t *testing.T) {
+				assert.Equal(t, expected, Perft(&position, depth), "depth %d", depth)
+			})
+		}
+	}
+}
+
+func TestPerft_Chess960(t *testing.T) {
+	for _, test := range chess960Positions {
+		position, err := fen.ParseFen(test.fen)
+		require.NoError(t, err)
+
+		for i, expected := range test.counts {
+			depth := i + 1
+			t.Run(test.name, func(t *testing.T) {
+				assert.Equal(t, expected, Perft(&position, depth), "depth %d", depth)
+			})
+		}
+	}
+}
+
+func TestPerft_EdgeCases(t *testing.T) {
+	for _, test := range edgeCasePositions {
+		t.Run(test.name, func(t *testing.T) {
+			position, err := fen.ParseFen(test.fen)
+			require.NoError(t, err)
+			assert.Equal(t, test.nodes, Perft(&position, test.depth))
+		})
+	}
+}
+
+func TestDivide_SumsToPerft(t *testing.T) {
+	position, err := fen.ParseFen("r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1")
+	require.NoError(t, err)
+
+	entries := Divide(&position, 2)
+	assert.Len(t, entries, 48)
+
+	total := uint64(0)
+	moves := map[string]uint64{}
+	for _, entry := range entries {
+		total += entry.Nodes
+		moves[entry.Move] = entry.Nodes
+	}
+	assert.Equal(t, uint64(2039), total)
+
+	// castling is reported as a king move
+	assert.Contains(t, moves, "e1g1")
+	assert.Contains(t, moves, "e1c1")
+}
+
+func TestDivide_ListsEachPromotionPiece(t *testing.T) {
+	position, err := fen.ParseFen("4k3/1P6/8/8/8/8/8/4K3 w - - 0 1")
+	require.NoError(t, err)
+
+	moves := map[string]uint64{}
+	for _, entry := range Divide(&position, 1) {
+		moves[entry.Move] = entry.Nodes
+	}
+	for _, promotion := range []string{"b7b8q", "b7b8r", "b7b8b", "b7b8n"} {
+		assert.Equal(t, uint64(1), moves[promotion], promotion)
+	}
+}
@@ -0,0 +1,141 @@
+package pgn
+
+// database.go streams the games of a PGN database, a file holding any number of games one after another
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+)
+
+// ReadGames returns an iterator over the games of a PGN database. Games are tokenized and parsed one at
+// a time so memory use does not grow with the size of the input. A game that cannot be read is yielded
+// with an error naming the game, along with whatever part of it could be parsed, and reading resumes at
+// the next [Event tag. Iteration ends after an error reading from the underlying reader.
+func ReadGames(reader io.Reader) iter.Seq2[PgnGame, error] {
+	return func(yield func(PgnGame, error) bool) {
+		database := &databaseReader{tokens: NewPgnTokenReader(reader)}
+		for {
+			tokens, err := database.readGameTokens()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			database.games++
+
+			// a game missing its result still ends at the next game so only other errors need to skip ahead
+			_, missingResult := err.(*missingResultError)
+			skip := err != nil && !missingResult
+
+			pgnGame, parseErr := NewPgnGameParser(tokens).Parse()
+			if err == nil {
+				err = parseErr
+			}
+			if err != nil {
+				err = fmt.Errorf("game %d: %w", database.games, err)
+			}
+
+			if !yield(pgnGame, err) || database.tokens.err != nil {
+				return
+			}
+			if skip {
+				database.skipToNextEvent()
+			}
+		}
+	}
+}
+
+// databaseReader splits the token stream of a PGN database into games
+type databaseReader struct {
+	tokens *PgnTokenReader
+	// pending holds tokens read ahead that belong to the next game
+	pending []PgnToken
+	games   int
+}
+
+// missingResultError reports a game whose movetext is not ended by a game termination marker
+type missingResultError struct {
+	position PgnTokenPosition
+}
+
+func (e *missingResultError) Error() string {
+	return generateErrorText(e.position, "Expected a game termination marker before the next game.")
+}
+
+func (d *databaseReader) nextToken() (PgnToken, error) {
+	if len(d.pending) > 0 {
+		token := d.pending[0]
+		d.pending = d.pending[1:]
+		return token, nil
+	}
+	return d.tokens.ReadToken()
+}
+
+// readGameTokens reads the tokens of the next game up to and including its game termination marker. It
+// returns io.EOF when no game remains.
+func (d *databaseReader) readGameTokens() ([]PgnToken, error) {
+	tokens := []PgnToken{}
+	significant := false
+	inTag, inMoveText := false, false
+
+	for {
+		token, err := d.nextToken()
+		if errors.Is(err, io.EOF) {
+			if !significant {
+				return nil, io.EOF
+			}
+			return tokens, &missingResultError{tokens[len(tokens)-1].Position}
+		}
+		if err != nil {
+			return tokens, err
+		}
+
+		isComment := token.Type == CommentInLine || token.Type == CommentRestOfLine || token.Type == Escape
+		switch {
+		case isComment:
+		case token.Type == LeftBracket:
+			// tags never appear in movetext, so a tag there belongs to the next game
+			if inMoveText {
+				d.pending = append(d.pending, token)
+				return tokens, &missingResultError{token.Position}
+			}
+			inTag = true
+		case token.Type == RightBracket:
+			inTag = false
+		case !inTag:
+			inMoveText = true
+		}
+
+		significant = significant || !isComment
+		tokens = append(tokens, token)
+
+		if inMoveText && (token.Type == Astrix || (token.Type == Symbol && isGameResult(token.Value))) {
+			return tokens, nil
+		}
+	}
+}
+
+// skipToNextEvent discards tokens until the [Event tag that starts the next game, leaving that tag to be read.
+func (d *databaseReader) skipToNextEvent() {
+	for {
+		token, err := d.nextToken()
+		if errors.Is(err, io.EOF) || d.tokens.err != nil {
+			return
+		}
+		if err != nil || token.Type != LeftBracket {
+			continue
+		}
+
+		name, err := d.nextToken()
+		if err != nil {
+			continue
+		}
+		if name.Type == Symbol && name.Value == "Event" {
+			d.pending = append(d.pending, token, name)
+			return
+		}
+		if name.Type == LeftBracket {
+			d.pending = append(d.pending, name)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package pgn
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const databaseSample = `[Event "First"]
+[Result "1-0"]
+
+1. e4 e5 2. Qh5 Nc6 3. Bc4 Nf6 4. Qxf7# 1-0
+
+[Event "Second"]
+[Result "*"]
+
+1. d4 d5 *
+
+; trailing comment
+[Event "Third"]
+[Result "0-1"]
+
+1. f3 e5 2. g4 Qh4# 0-1
+`
+
+type readGame struct {
+	game PgnGame
+	err  error
+}
+
+func readAllGames(t *testing.T, games func(func(PgnGame, error) bool)) []readGame {
+	t.Helper()
+	result := []readGame{}
+	for game, err := range games {
+		result = append(result, readGame{game, err})
+	}
+	return result
+}
+
+func eventOf(game PgnGame) string {
+	for _, tag := range game.TagSection {
+		if tag.Name == "Event" {
+			return tag.Value
+		}
+	}
+	return ""
+}
+
+func TestReadGames_ReadsEveryGame(t *testing.T) {
+	games := readAllGames(t, ReadGames(strings.NewReader(databaseSample)))
+
+	require.Len(t, games, 3)
+	for _, game := range games {
+		assert.NoError(t, game.err)
+	}
+	assert.Equal(t, "First", eventOf(games[0].game))
+	assert.Equal(t, "1-0", games[0].game.Result)
+	assert.Len(t, games[0].game.MoveText, 7)
+	assert.Equal(t, "Second", eventOf(games[1].game))
+	assert.Equal(t, "*", games[1].game.Result)
+	assert.Equal(t, "Third", eventOf(games[2].game))
+	assert.Equal(t, 14, games[2].game.MoveText[0].Position.Line)
+}
+
+func TestReadGames_ReadsFromPlainReader(t *testing.T) {
+	games := readAllGames(t, ReadGames(iotest.OneByteReader(strings.NewReader(databaseSample))))
+
+	require.Len(t, games, 3)
+	assert.Equal(t, "Third", eventOf(games[2].game))
+	assert.NoError(t, games[2].err)
+}
+
+func TestReadGames_RecoversFromMalformedGame(t *testing.T) {
+	input := `[Event "Broken \x escape"]
+[Result "1-0"]
+
+1. e4 e5 1-0
+
+[Event "Good"]
+[Result "*"]
+
+1. c4 *
+`
+	games := readAllGames(t, ReadGames(strings.NewReader(input)))
+
+	require.Len(t, games, 2)
+	assert.ErrorContains(t, games[0].err, "game 1")
+	assert.NoError(t, games[1].err)
+	assert.Equal(t, "Good", eventOf(games[1].game))
+	assert.Equal(t, "c4", games[1].game.MoveText[0].SanMove)
+}
+
+func TestReadGames_GameWithoutResult(t *testing.T) {
+	input := `[Event "Unfinished"]
+
+1. e4 e5
+
+[Event "Finished"]
+
+1. d4 1-0
+
+[Event "Truncated"]
+
+1. c4`
+	games := readAllGames(t, ReadGames(strings.NewReader(input)))
+
+	require.Len(t, games, 3)
+	assert.ErrorContains(t, games[0].err, "game 1")
+	assert.Len(t, games[0].game.MoveText, 2)
+	assert.NoError(t, games[1].err)
+	assert.Equal(t, "Finished", eventOf(games[1].game))
+	assert.ErrorContains(t, games[2].err, "game 3")
+	assert.Equal(t, "Truncated", eventOf(games[2].game))
+}
+
+func TestReadGames_TruncatedTag(t *testing.T) {
+	games := readAllGames(t, ReadGames(strings.NewReader(`[Event`)))
+
+	require.Len(t, games, 1)
+	assert.Error(t, games[0].err)
+}
+
+func TestReadGames_StopsOnReadError(t *testing.T) {
+	readErr := errors.New("disk on fire")
+	reader := iotest.DataErrReader(&failingReader{data: strings.NewReader(databaseSample[:60]), err: readErr})
+
+	games := readAllGames(t, ReadGames(reader))
+
+	require.NotEmpty(t, games)
+	assert.ErrorIs(t, games[len(games)-1].err, readErr)
+}
+
+func TestReadGames_StopsWhenConsumerStops(t *testing.T) {
+	count := 0
+	for range ReadGames(strings.NewReader(databaseSample)) {
+		count++
+		break
+	}
+	assert.Equal(t, 1, count)
+}
+
+// failingReader returns the data of the wrapped reader followed by an error
+type failingReader struct {
+	data *strings.Reader
+	err  error
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	n, _ := r.data.Read(p)
+	if n == 0 {
+		return 0, r.err
+	}
+	return n, nil
+}
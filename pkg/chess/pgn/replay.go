@@ -0,0 +1,86 @@
+package pgn
+
+// replay.go plays the movetext of a parsed game through the rules of chess
+
+import (
+	"fmt"
+
+	"github.com/jerhon/chess/pkg/chess"
+	"github.com/jerhon/chess/pkg/chess/fen"
+	"github.com/jerhon/chess/pkg/chess/game"
+)
+
+// ReplayedGame is a PGN game whose moves have been checked against the rules.
+type ReplayedGame struct {
+	// Game is the game after the last move that could be played
+	Game *chess.ChessGame
+	// Positions holds the starting position followed by the position after every ply, so Positions[ply]
+	// is the position after that many moves
+	Positions []*game.ChessPosition
+}
+
+// ReplayError reports a move in the movetext that is illegal, ambiguous or not valid SAN.
+type ReplayError struct {
+	// Ply is the 1 based number of the move in the movetext
+	Ply      int
+	San      string
+	Position PgnTokenPosition
+	// Err is the error of ChessGame.TrySanMove, such as a *chess.ErrAmbiguousMove
+	Err error
+}
+
+func (e *ReplayError) Error() string {
+	return generateErrorText(e.Position, fmt.Sprintf("ply %d, move %s: %v", e.Ply, e.San, e.Err))
+}
+
+func (e *ReplayError) Unwrap() error {
+	return e.Err
+}
+
+// Replay plays the main line of a PGN game from its starting position, the FEN tag when the game was
+// set up from a position and the standard position otherwise. When a move cannot be played the
+// positions up to that move are returned together with a *ReplayError.
+func Replay(pgnGame PgnGame) (*ReplayedGame, error) {
+	start, err := startingPosition(pgnGame.TagSection)
+	if err != nil {
+		return nil, err
+	}
+
+	chessGame := chess.NewGameFromPosition(start)
+	// recorded games may go on past a repetition or fifty moves that were not claimed, under rules such as
+	// those of the USCF that never end the game without a claim
+	chessGame.SetDrawRules(chess.USCFRules)
+	replayed := &ReplayedGame{Game: chessGame, Positions: []*game.ChessPosition{start}}
+
+	for i, element := range pgnGame.MoveText {
+		if _, err := chessGame.TrySanMove(element.SanMove); err != nil {
+			return replayed, &ReplayError{Ply: i + 1, San: element.SanMove, Position: element.Position, Err: err}
+		}
+		replayed.Positions = append(replayed.Positions, chessGame.GetPosition())
+	}
+
+	return replayed, nil
+}
+
+// startingPosition reads the FEN tag of a game, a SetUp tag of "0" means the FEN tag is ignored.
+func startingPosition(tags []PgnTag) (*game.ChessPosition, error) {
+	setUp, fenString := "", ""
+	for _, tag := range tags {
+		switch tag.Name {
+		case "SetUp":
+			setUp = tag.Value
+		case "FEN":
+			fenString = tag.Value
+		}
+	}
+
+	if fenString == "" || setUp == "0" {
+		return game.NewStandardStartingPosition(), nil
+	}
+
+	position, err := fen.ParseFen(fenString)
+	if err != nil {
+		return nil, fmt.Errorf("invalid FEN tag %q: %w", fenString, err)
+	}
+	return &position, nil
+}
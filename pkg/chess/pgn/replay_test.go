@@ -0,0 +1,123 @@
+package pgn
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jerhon/chess/pkg/chess"
+	"github.com/jerhon/chess/pkg/chess/fen"
+	"github.com/jerhon/chess/pkg/chess/game"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplay_SpecSample(t *testing.T) {
+	pgnGame, err := createPgnGameFromString(pgnSpecSample)
+	require.NoError(t, err)
+
+	replayed, err := Replay(pgnGame)
+	require.NoError(t, err)
+
+	assert.Len(t, replayed.Positions, 86)
+	assert.Equal(t, "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", fen.ToFenString(replayed.Positions[0]))
+	assert.Equal(t, "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1", fen.ToFenString(replayed.Positions[1]))
+	assert.Equal(t, "8/8/4R1p1/2k3p1/1p4P1/1P1b1P2/3K1n2/8 b - - 2 43", fen.ToFenString(replayed.Positions[85]))
+	assert.Equal(t, 85, replayed.Game.Ply())
+}
+
+func TestReplay_HonoursFenTag(t *testing.T) {
+	pgnGame, err := createPgnGameFromString(`[SetUp "1"]
+[FEN "4k3/8/8/8/8/8/4P3/4K3 b - - 0 40"]
+
+40... Kd7 41. e4 Kd6 *`)
+	require.NoError(t, err)
+
+	replayed, err := Replay(pgnGame)
+	require.NoError(t, err)
+	assert.Len(t, replayed.Positions, 4)
+	assert.Equal(t, "8/8/3k4/8/4P3/8/8/4K3 w - - 1 42", fen.ToFenString(replayed.Positions[3]))
+}
+
+func TestReplay_InvalidFenTag(t *testing.T) {
+	_, err := Replay(PgnGame{TagSection: []PgnTag{{"SetUp", "1"}, {"FEN", "not a fen"}}})
+	assert.Error(t, err)
+}
+
+func TestReplay_ReportsBadMoves(t *testing.T) {
+	testCases := []struct {
+		name      string
+		movetext  string
+		ply       int
+		san       string
+		line      int
+		column    int
+		positions int
+	}{
+		{"illegal", "1. e4 e5 2. Ke3 *", 3, "Ke3", 0, 12, 3},
+		{"ambiguous", "1. e4 e5 2. Nc3 Nc6 3. Ne2 *", 5, "Ne2", 0, 23, 5},
+		{"not san", "1. e4\n2. Zz9 *", 2, "Zz9", 1, 3, 2},
+		{"after checkmate", "1. f3 e5 2. g4 Qh4# 3. a3 *", 5, "a3", 0, 23, 5},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			pgnGame, err := createPgnGameFromString(testCase.movetext)
+			require.NoError(t, err)
+
+			replayed, err := Replay(pgnGame)
+
+			var replayError *ReplayError
+			require.True(t, errors.As(err, &replayError), "expected a ReplayError, got %v", err)
+			assert.Equal(t, testCase.ply, replayError.Ply)
+			assert.Equal(t, testCase.san, replayError.San)
+			assert.Equal(t, testCase.line, replayError.Position.Line)
+			assert.Equal(t, testCase.column, replayError.Position.LineOffset)
+			assert.Len(t, replayed.Positions, testCase.positions)
+		})
+	}
+}
+
+func TestReplay_PositionsAreIndependent(t *testing.T) {
+	pgnGame, err := createPgnGameFromString("1. e4 e5 *")
+	require.NoError(t, err)
+
+	replayed, err := Replay(pgnGame)
+	require.NoError(t, err)
+
+	piece, _ := replayed.Positions[0].Board.GetPiece(game.ChessLocation{File: game.FileE, Rank: game.Rank2})
+	assert.Equal(t, game.ChessPiece{Piece: game.Pawn, Color: game.WhitePiece}, piece)
+}
+
+func TestReplay_GoesOnPastAnUnclaimedRepetition(t *testing.T) {
+	pgnGame, err := createPgnGameFromString(`1. Nf3 Nf6 2. Ng1 Ng8 3. Nf3 Nf6 4. Ng1 Ng8 5. Nf3 Nf6 6. Ng1 Ng8
+7. Nf3 Nf6 8. Ng1 Ng8 9. e4 *`)
+	require.NoError(t, err)
+
+	replayed, err := Replay(pgnGame)
+	require.NoError(t, err)
+	assert.Equal(t, 17, replayed.Game.Ply())
+	assert.Equal(t, game.InProgress, replayed.Game.GetResult())
+}
+
+func TestReplay_GoesOnPastSeventyFiveMoves(t *testing.T) {
+	pgnGame, err := createPgnGameFromString(`[SetUp "1"]
+[FEN "4k3/8/8/8/8/8/8/R3K3 w - - 149 120"]
+
+120. Ra2 Kd8 121. Ra3 Kc8 *`)
+	require.NoError(t, err)
+
+	replayed, err := Replay(pgnGame)
+	require.NoError(t, err)
+	assert.Equal(t, "2k5/8/8/8/8/R7/8/4K3 w - - 153 122", fen.ToFenString(replayed.Game.GetPosition()))
+	assert.Equal(t, game.InProgress, replayed.Game.GetResult())
+}
+
+func TestReplay_ErrorsAreTyped(t *testing.T) {
+	pgnGame, err := createPgnGameFromString("1. e4 e5 2. Nc3 Nc6 3. Ne2 *")
+	require.NoError(t, err)
+
+	_, err = Replay(pgnGame)
+	var ambiguous *chess.ErrAmbiguousMove
+	require.True(t, errors.As(err, &ambiguous), "expected an ErrAmbiguousMove, got %v", err)
+	assert.ElementsMatch(t, []string{"Nce2", "Nge2"}, ambiguous.Candidates)
+}
@@ -3,8 +3,9 @@ package pgn
 // https://www.saremba.de/chessgml/standards/pgn/pgn-complete.htm
 
 import (
+	"bufio"
 	"fmt"
-	"strings"
+	"io"
 	"unicode"
 )
 
@@ -50,19 +51,28 @@ type PgnTokenPosition struct {
 
 // PgnTokenReader encapsulates several fields to keep track of reading tokens
 type PgnTokenReader struct {
-	reader     *strings.Reader
+	reader     io.RuneReader
 	line       int
 	lineOffset int
 	offset     int
 	runeOffset int
 	rune       rune
 	runeSize   int
+	// started is set once the first rune has been read, hasRune while rune holds a rune not yet tokenized
+	started bool
+	hasRune bool
+	// err holds a read error other than io.EOF
+	err error
 }
 
-// NewPgnTokenReader creates a new token reader for the given string reader
-func NewPgnTokenReader(reader *strings.Reader) *PgnTokenReader {
+// NewPgnTokenReader creates a new token reader for the given reader, readers that cannot read runes are buffered
+func NewPgnTokenReader(reader io.Reader) *PgnTokenReader {
+	runeReader, ok := reader.(io.RuneReader)
+	if !ok {
+		runeReader = bufio.NewReader(reader)
+	}
 	return &PgnTokenReader{
-		reader:     reader,
+		reader:     runeReader,
 		line:       0,
 		lineOffset: 0,
 		offset:     0,
@@ -88,28 +98,43 @@ var singleCharacterTokens = []struct {
 }
 
 // ReadTokens reads all the PGN tokens from a PgnTokenReader
-func (p *PgnTokenReader) ReadTokens() (tokens []PgnToken, err error) {
-	tokens = []PgnToken{}
+func (p *PgnTokenReader) ReadTokens() ([]PgnToken, error) {
+	tokens := []PgnToken{}
+	for {
+		token, err := p.ReadToken()
+		if err == io.EOF {
+			return tokens, nil
+		}
+		if err != nil {
+			return tokens, err
+		}
+		tokens = append(tokens, token)
+	}
+}
+
+// ReadToken reads the next PGN token, it returns io.EOF once the reader is exhausted
+func (p *PgnTokenReader) ReadToken() (PgnToken, error) {
+	if !p.started {
+		p.started = true
+		p.hasRune, _ = p.readRune()
+	}
+
+	match, r := p.hasRune, p.rune
+	// the rune after the token is kept for the next call
+	defer func() { p.hasRune = match }()
 
-	match, r := p.readRune()
 	for match {
 		newLine := p.lineOffset == 0
 		startPosition := p.getPosition()
 
-		singleCharMatch := false
 		for _, singleCharToken := range singleCharacterTokens {
 			if r == singleCharToken.value {
-				token := PgnToken{startPosition, string(r), singleCharToken.tokenType}
-				tokens = append(tokens, token)
-				singleCharMatch = true
-				break
+				match, r = p.readRune()
+				return PgnToken{startPosition, string(singleCharToken.value), singleCharToken.tokenType}, nil
 			}
 		}
 
-		if singleCharMatch {
-			match, r = p.readRune()
-			continue
-		} else if unicode.IsLetter(r) || unicode.IsDigit(r) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
 			value := ""
 			for isSymbolRune(r) && match {
 				value += string(r)
@@ -117,20 +142,19 @@ func (p *PgnTokenReader) ReadTokens() (tokens []PgnToken, err error) {
 			}
 
 			if isIntegerToken(value) {
-				token := PgnToken{startPosition, value, Integer}
-				tokens = append(tokens, token)
-			} else {
-				token := PgnToken{startPosition, value, Symbol}
-				tokens = append(tokens, token)
+				return PgnToken{startPosition, value, Integer}, nil
 			}
+			return PgnToken{startPosition, value, Symbol}, nil
 		} else if r == '"' {
 			escape := false
 			value := ""
+			var escapeErr error
 			match, r = p.readRune()
 			for match && (escape || r != '"') {
 				if escape {
-					if r != '\\' && r != '"' {
-						return tokens, fmt.Errorf("invalid escape sequence '\\%c' in string token at offset %d", r, p.runeOffset)
+					// the rest of the string is still read so the next token starts after it
+					if r != '\\' && r != '"' && escapeErr == nil {
+						escapeErr = fmt.Errorf("invalid escape sequence '\\%c' in string token at offset %d", r, p.runeOffset)
 					}
 					value += string(r)
 					escape = false
@@ -147,8 +171,10 @@ func (p *PgnTokenReader) ReadTokens() (tokens []PgnToken, err error) {
 			// advance past the final "
 			match, r = p.readRune()
 
-			token := PgnToken{startPosition, value, String}
-			tokens = append(tokens, token)
+			if escapeErr != nil {
+				return PgnToken{}, escapeErr
+			}
+			return PgnToken{startPosition, value, String}, nil
 		} else if r == '%' && newLine {
 			value := ""
 			match, r = p.readRune()
@@ -156,8 +182,7 @@ func (p *PgnTokenReader) ReadTokens() (tokens []PgnToken, err error) {
 				value += string(r)
 				match, r = p.readRune()
 			}
-			token := PgnToken{startPosition, value, Escape}
-			tokens = append(tokens, token)
+			return PgnToken{startPosition, value, Escape}, nil
 		} else if r == '$' {
 			value := ""
 			match, r = p.readRune()
@@ -165,8 +190,7 @@ func (p *PgnTokenReader) ReadTokens() (tokens []PgnToken, err error) {
 				value += string(r)
 				match, r = p.readRune()
 			}
-			token := PgnToken{startPosition, value, NumericAnnotationGlyph}
-			tokens = append(tokens, token)
+			return PgnToken{startPosition, value, NumericAnnotationGlyph}, nil
 		} else if r == ';' {
 			value := ""
 			match, r = p.readRune()
@@ -174,8 +198,7 @@ func (p *PgnTokenReader) ReadTokens() (tokens []PgnToken, err error) {
 				value += string(r)
 				match, r = p.readRune()
 			}
-			token := PgnToken{startPosition, value, CommentRestOfLine}
-			tokens = append(tokens, token)
+			return PgnToken{startPosition, value, CommentRestOfLine}, nil
 		} else if r == '{' {
 			value := ""
 			match, r = p.readRune()
@@ -185,22 +208,25 @@ func (p *PgnTokenReader) ReadTokens() (tokens []PgnToken, err error) {
 			}
 			// ignore ending }
 			match, r = p.readRune()
-			token := PgnToken{
+			return PgnToken{
 				Position: startPosition,
 				Value:    value,
 				Type:     CommentInLine,
-			}
-			tokens = append(tokens, token)
+			}, nil
 		} else if unicode.IsSpace(r) {
 			// ignore it
 			match, r = p.readRune()
 		} else {
 			token := PgnToken{startPosition, string(r), Unknown}
-			tokens = append(tokens, token)
 			match, r = p.readRune()
+			return token, nil
 		}
 	}
-	return
+
+	if p.err != nil {
+		return PgnToken{}, p.err
+	}
+	return PgnToken{}, io.EOF
 }
 
 // getPosition gets the current rules the PgnTokenReader is at
@@ -221,6 +247,9 @@ func (p *PgnTokenReader) readRune() (bool, rune) {
 
 	r, runeSize, err := p.reader.ReadRune()
 	if err != nil {
+		if err != io.EOF {
+			p.err = err
+		}
 		return false, 0
 	}
 
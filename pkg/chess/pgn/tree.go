@@ -1,6 +1,9 @@
 package pgn
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // tree.go contains syntax tree parsing for PGN games
 
@@ -17,33 +20,49 @@ type PgnTag struct {
 	Value string
 }
 
+// PgnElementSequence is a line of moves, used for the variations of a move
 type PgnElementSequence struct {
 	Elements []PgnElement
 }
 
 type PgnElement struct {
-	MoveNumberIndicator    string
-	SanMove                string
-	NumericAnnotationGlyph string
-	RecursiveAnnotation    []PgnElement
+	MoveNumberIndicator string
+	SanMove             string
+	// NumericAnnotationGlyphs holds the values of the NAGs following the move, suffix annotations such as !? are
+	// converted to their NAG
+	NumericAnnotationGlyphs []string
+	// RecursiveAnnotation holds the variations played instead of this move
+	RecursiveAnnotation []PgnElementSequence
+	// LeadingComments holds the commentary before the move when no move precedes it in its line
+	LeadingComments []string
+	// Comments holds the commentary that follows the move
+	Comments []string
+	// Position is where the SAN move starts in the source text
+	Position PgnTokenPosition
 }
 
 type PgnGameParser struct {
 	tokens            []PgnToken
 	idx               int
 	parsingAttributes bool
+	// comments collects the comments skipped by peekToken until they are attached to a move
+	comments    []string
+	parseErrors []string
 }
 
 func NewPgnGameParser(tokens []PgnToken) *PgnGameParser {
-	return &PgnGameParser{tokens, 0, true}
+	return &PgnGameParser{tokens: tokens, parsingAttributes: true}
 }
 
 func (p *PgnGameParser) peekToken() (match bool, token *PgnToken) {
 
-	// We want to ignore comments when we are parsing the PGN for evaluation
+	// Comments are set aside for the move they annotate and escaped lines are ignored
 	for p.idx < len(p.tokens) {
 		currentToken := p.tokens[p.idx]
-		if currentToken.Type == CommentInLine || currentToken.Type == CommentRestOfLine || currentToken.Type == Escape {
+		if currentToken.Type == CommentInLine || currentToken.Type == CommentRestOfLine {
+			p.comments = append(p.comments, strings.TrimSpace(currentToken.Value))
+			p.idx++
+		} else if currentToken.Type == Escape {
 			p.idx++
 		} else {
 			break
@@ -75,6 +94,17 @@ func (p *PgnGameParser) expectToken(expectedTokenType PgnTokenType) (bool, *PgnT
 	return true, token
 }
 
+// positionOf returns the position of a token, or of the last token when the tokens ran out
+func (p *PgnGameParser) positionOf(token *PgnToken) PgnTokenPosition {
+	if token != nil {
+		return token.Position
+	}
+	if len(p.tokens) > 0 {
+		return p.tokens[len(p.tokens)-1].Position
+	}
+	return PgnTokenPosition{}
+}
+
 func generateErrorText(position PgnTokenPosition, errorText string) string {
 	return fmt.Sprintf("[%d:%d] %s", position.Line, position.LineOffset, errorText)
 }
@@ -83,100 +113,192 @@ func (p *PgnGameParser) Parse() (PgnGame, error) {
 	tags := []PgnTag{}
 	elements := []PgnElement{}
 	result := ""
-	parseErrors := []string{}
 
 	match, _ := p.peekToken()
-	for match {
-		lastIdx := p.idx
-		if p.parsingAttributes {
-			leftBracketMatch, _ := p.expectToken(LeftBracket)
-			if leftBracketMatch {
-				nameMatch, nameToken := p.expectToken(Symbol)
-				if !nameMatch {
-					parseErrors = append(parseErrors, generateErrorText(nameToken.Position, "Expected tag name after '['"))
-					continue
-				}
-
-				valueMatch, valueToken := p.expectToken(String)
-				if !valueMatch {
-					parseErrors = append(parseErrors, generateErrorText(valueToken.Position, "Expected tag value after tag name"))
-					continue
-				}
-
-				endBracketMatch, _ := p.expectToken(RightBracket)
-				if !endBracketMatch {
-					parseErrors = append(parseErrors, generateErrorText(valueToken.Position, "Expected ']' after tag value"))
-					continue
-				}
-
-				tag := PgnTag{nameToken.Value, valueToken.Value}
-				tags = append(tags, tag)
+	for ; match && p.parsingAttributes; match, _ = p.peekToken() {
+		leftBracketMatch, _ := p.expectToken(LeftBracket)
+		if !leftBracketMatch {
+			p.parsingAttributes = false
+			break
+		}
+
+		nameMatch, nameToken := p.expectToken(Symbol)
+		if !nameMatch {
+			p.addError(nameToken, "Expected tag name after '['")
+			continue
+		}
+
+		valueMatch, valueToken := p.expectToken(String)
+		if !valueMatch {
+			p.addError(valueToken, "Expected tag value after tag name")
+			continue
+		}
+
+		endBracketMatch, _ := p.expectToken(RightBracket)
+		if !endBracketMatch {
+			p.addError(valueToken, "Expected ']' after tag value")
+			continue
+		}
+
+		tag := PgnTag{nameToken.Value, valueToken.Value}
+		tags = append(tags, tag)
+	}
+
+	if match {
+		elements, result = p.parseLine(false)
+	}
+
+	var err error = nil
+	if len(p.parseErrors) > 0 {
+		err = fmt.Errorf("one or more errors occurred parsing the PGN: %s", p.parseErrors)
+	}
+
+	return PgnGame{tags, elements, result}, err
+}
+
+// parseLine parses moves until the game termination marker or, for a variation, the closing parenthesis.
+// Variations are parsed recursively and attached to the move they replace. It returns the moves of the
+// line and the game termination marker when one was found.
+func (p *PgnGameParser) parseLine(inVariation bool) ([]PgnElement, string) {
+	elements := []PgnElement{}
+	leadingComments := []string{}
+
+	for {
+		match, token := p.peekToken()
+
+		// comments belong to the move before them, or the next move at the start of a line
+		if len(p.comments) > 0 {
+			if len(elements) > 0 {
+				elements[len(elements)-1].Comments = append(elements[len(elements)-1].Comments, p.comments...)
 			} else {
-				p.parsingAttributes = false
-				continue
+				leadingComments = append(leadingComments, p.comments...)
 			}
-		} else {
-			// ignore the move text until we get the tag parsing done
-
-			moveNumberText := ""
-			// parsing move text
-			numberMatch, numberToken := p.expectToken(Integer)
-			if numberMatch {
-
-				// if the first is an integer, then it's a move number
-				periods := ""
-				periodMatch, _ := p.expectToken(Period)
-				for periodMatch {
-					periods += "."
-					periodMatch, _ = p.expectToken(Period)
-				}
-
-				moveNumberText = numberToken.Value + periods
+			p.comments = nil
+		}
+
+		if !match {
+			if inVariation {
+				p.addError(nil, "Expected ')' to end the variation.")
 			}
+			return elements, ""
+		}
 
-			sanText := ""
-			symbolMatch, symbolToken := p.expectToken(Symbol)
-			if symbolMatch {
-				if isGameResult(symbolToken.Value) {
-					result = symbolToken.Value
-					break
-				} else {
-					sanText = symbolToken.Value
-				}
+		switch {
+		case token.Type == Astrix || (token.Type == Symbol && isGameResult(token.Value)):
+			p.advanceToken()
+			if inVariation {
+				p.addError(token, "Expected ')' to end the variation before the game termination marker.")
 			}
+			return elements, token.Value
 
-			if !numberMatch && !symbolMatch {
-				parseErrors = append(parseErrors, generateErrorText(symbolToken.Position, "Expected move number or move text.  Advancing to next PGN token."))
-				p.advanceToken()
-				continue
+		case token.Type == RightParen:
+			p.advanceToken()
+			if inVariation {
+				return elements, ""
 			}
+			p.addError(token, "Unexpected ')' outside of a variation.")
 
-			if numberMatch && !symbolMatch {
-				parseErrors = append(parseErrors, generateErrorText(symbolToken.Position, "Expected move text after move number."))
-				continue
+		case token.Type == LeftParen:
+			p.advanceToken()
+			variation, result := p.parseLine(true)
+			if len(elements) == 0 {
+				p.addError(token, "Expected a move before the variation.")
+			} else {
+				last := &elements[len(elements)-1]
+				last.RecursiveAnnotation = append(last.RecursiveAnnotation, PgnElementSequence{variation})
+			}
+			if result != "" {
+				return elements, result
 			}
 
-			numericAnnotationGlyph := ""
-			numericAnnotationMatch, numericAnnotationToken := p.expectToken(NumericAnnotationGlyph)
-			if numericAnnotationMatch {
-				numericAnnotationGlyph = numericAnnotationToken.Value
+		case token.Type == NumericAnnotationGlyph || isSuffixAnnotation(token):
+			glyph := p.parseGlyph()
+			if len(elements) == 0 {
+				p.addError(token, "Expected a move before the annotation glyph.")
+			} else {
+				last := &elements[len(elements)-1]
+				last.NumericAnnotationGlyphs = append(last.NumericAnnotationGlyphs, glyph)
 			}
 
-			element := PgnElement{moveNumberText, sanText, numericAnnotationGlyph, nil}
+		case token.Type == Integer || token.Type == Symbol:
+			element, ok := p.parseMove()
+			if !ok {
+				continue
+			}
+			if len(elements) == 0 && len(leadingComments) > 0 {
+				element.LeadingComments = leadingComments
+			}
 			elements = append(elements, element)
+
+		default:
+			p.addError(token, "Expected move number or move text.  Advancing to next PGN token.")
+			p.advanceToken()
 		}
-		match, _ = p.peekToken()
-		if lastIdx == p.idx {
-			parseErrors = append(parseErrors, generateErrorText(p.tokens[p.idx].Position, "Internal parser error."))
+	}
+}
+
+// parseMove parses a move with its optional move number indicator
+func (p *PgnGameParser) parseMove() (PgnElement, bool) {
+	moveNumberText := ""
+	numberMatch, numberToken := p.expectToken(Integer)
+	if numberMatch {
+
+		// if the first is an integer, then it's a move number
+		periods := ""
+		periodMatch, _ := p.expectToken(Period)
+		for periodMatch {
+			periods += "."
+			periodMatch, _ = p.expectToken(Period)
 		}
+
+		moveNumberText = numberToken.Value + periods
 	}
 
-	var err error = nil
-	if len(parseErrors) > 0 {
-		err = fmt.Errorf("one or more errors occurred parsing the PGN: %s", parseErrors)
+	// the game termination marker is left for parseLine
+	symbolMatch, symbolToken := p.peekToken()
+	if !symbolMatch || symbolToken.Type != Symbol || isGameResult(symbolToken.Value) {
+		p.addError(symbolToken, "Expected move text after move number.")
+		return PgnElement{}, false
 	}
+	p.advanceToken()
 
-	return PgnGame{tags, elements, result}, err
+	return PgnElement{MoveNumberIndicator: moveNumberText, SanMove: symbolToken.Value, Position: symbolToken.Position}, true
+}
+
+// suffixAnnotations maps the move suffix annotations to their equivalent NAG
+var suffixAnnotations = map[string]string{
+	"!":  "1",
+	"?":  "2",
+	"!!": "3",
+	"??": "4",
+	"!?": "5",
+	"?!": "6",
+}
+
+func isSuffixAnnotation(token *PgnToken) bool {
+	return token.Type == Unknown && (token.Value == "!" || token.Value == "?")
+}
+
+// parseGlyph parses a NAG, or a suffix annotation made of adjacent ! and ? tokens, into the NAG value
+func (p *PgnGameParser) parseGlyph() string {
+	_, token := p.peekToken()
+	p.advanceToken()
+	if token.Type == NumericAnnotationGlyph {
+		return token.Value
+	}
+
+	suffix := token.Value
+	nextOffset := token.Position.Offset + 1
+	for len(suffix) < 2 && p.idx < len(p.tokens) && isSuffixAnnotation(&p.tokens[p.idx]) && p.tokens[p.idx].Position.Offset == nextOffset {
+		suffix += p.tokens[p.idx].Value
+		p.advanceToken()
+	}
+	return suffixAnnotations[suffix]
+}
+
+// addError records a parse error at a token, or at the end of the tokens when token is nil
+func (p *PgnGameParser) addError(token *PgnToken, errorText string) {
+	p.parseErrors = append(p.parseErrors, generateErrorText(p.positionOf(token), errorText))
 }
 
 func isGameResult(value string) bool {
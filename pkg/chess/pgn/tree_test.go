@@ -2,6 +2,7 @@ package pgn
 
 import (
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"strings"
 	"testing"
 )
@@ -48,7 +49,24 @@ var expectedTag = []PgnTag{
 }
 
 func NewPgnElement(number string, move string) PgnElement {
-	return PgnElement{number, move, "", nil}
+	return PgnElement{MoveNumberIndicator: number, SanMove: move}
+}
+
+// withoutPositions clears the source positions so elements can be compared to literals
+func withoutPositions(elements []PgnElement) []PgnElement {
+	cleared := make([]PgnElement, len(elements))
+	for i, element := range elements {
+		element.Position = PgnTokenPosition{}
+		if element.RecursiveAnnotation != nil {
+			variations := make([]PgnElementSequence, len(element.RecursiveAnnotation))
+			for j, variation := range element.RecursiveAnnotation {
+				variations[j] = PgnElementSequence{withoutPositions(variation.Elements)}
+			}
+			element.RecursiveAnnotation = variations
+		}
+		cleared[i] = element
+	}
+	return cleared
 }
 
 var moves = []PgnElement{
@@ -76,8 +94,73 @@ func TestTagParsing(t *testing.T) {
 	assert.Equal(t, expectedTag, game.TagSection)
 
 	// take only the number I have in the initial pgn
-	actualMoves := game.MoveText[:len(moves)]
+	actualMoves := withoutPositions(game.MoveText[:len(moves)])
 	assert.Equal(t, moves, actualMoves)
 
 	assert.Equal(t, "1/2-1/2", game.Result)
 }
+
+var annotatedSample = `[Event "Lesson"]
+
+{The Ruy Lopez} 1. e4 e5 2. Nf3 Nc6 3. Bb5 $1 $14 {pinning pressure} (3. Bc4 Bc5
+(3... Nf6 {the two knights} 4. Ng5) 4. c3) (3. d4 exd4) 3... a6!? ; the Morphy defence
+4. Ba4 Nf6?! 1-0
+`
+
+func TestParse_AnnotatedGame(t *testing.T) {
+	game, err := createPgnGameFromString(annotatedSample)
+	assert.NoError(t, err)
+
+	mainLine := withoutPositions(game.MoveText)
+	assert.Len(t, mainLine, 8)
+	assert.Equal(t, "1-0", game.Result)
+
+	assert.Equal(t, []string{"The Ruy Lopez"}, mainLine[0].LeadingComments)
+	assert.Nil(t, mainLine[0].Comments)
+
+	bishop := mainLine[4]
+	assert.Equal(t, "Bb5", bishop.SanMove)
+	assert.Equal(t, []string{"1", "14"}, bishop.NumericAnnotationGlyphs)
+	assert.Equal(t, []string{"pinning pressure"}, bishop.Comments)
+	assert.Len(t, bishop.RecursiveAnnotation, 2)
+
+	italian := bishop.RecursiveAnnotation[0].Elements
+	assert.Equal(t, []PgnElement{NewPgnElement("3.", "Bc4"), {SanMove: "Bc5", RecursiveAnnotation: []PgnElementSequence{{[]PgnElement{
+		{MoveNumberIndicator: "3...", SanMove: "Nf6", Comments: []string{"the two knights"}},
+		NewPgnElement("4.", "Ng5"),
+	}}}}, NewPgnElement("4.", "c3")}, italian)
+	assert.Equal(t, []PgnElement{NewPgnElement("3.", "d4"), NewPgnElement("", "exd4")}, bishop.RecursiveAnnotation[1].Elements)
+
+	assert.Equal(t, "a6", mainLine[5].SanMove)
+	assert.Equal(t, []string{"5"}, mainLine[5].NumericAnnotationGlyphs)
+	assert.Equal(t, []string{"the Morphy defence"}, mainLine[5].Comments)
+	assert.Equal(t, []string{"6"}, mainLine[7].NumericAnnotationGlyphs)
+}
+
+func TestParse_AnnotatedGameRoundTrips(t *testing.T) {
+	original, err := createPgnGameFromString(annotatedSample)
+	require.NoError(t, err)
+
+	reread, err := createPgnGameFromString(ToPgnString(original))
+	require.NoError(t, err)
+	assert.Equal(t, withoutPositions(original.MoveText), withoutPositions(reread.MoveText))
+}
+
+func TestParse_VariationErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		movetext string
+	}{
+		{"unclosed variation", "1. e4 (1. d4 *"},
+		{"unexpected close", "1. e4 ) e5 *"},
+		{"variation before a move", "(1. d4) 1. e4 *"},
+		{"glyph before a move", "$1 1. e4 *"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := createPgnGameFromString(testCase.movetext)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,286 @@
+package pgn
+
+// writer.go serializes PGN games in the export format described in section 8 of the specification
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/jerhon/chess/pkg/chess"
+	"github.com/jerhon/chess/pkg/chess/fen"
+	"github.com/jerhon/chess/pkg/chess/game"
+)
+
+// maxLineLength is the column at which movetext is wrapped
+const maxLineLength = 80
+
+// standardStartFen is the FEN of the standard starting position, games from any other position are
+// written with SetUp and FEN tags
+const standardStartFen = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+
+// SevenTagRoster lists the tags every exported game carries, in the order they are written.
+var SevenTagRoster = []string{"Event", "Site", "Date", "Round", "White", "Black", "Result"}
+
+// sevenTagRosterDefaults are written when a game does not supply one of the roster tags
+var sevenTagRosterDefaults = map[string]string{
+	"Event":  "?",
+	"Site":   "?",
+	"Date":   "????.??.??",
+	"Round":  "?",
+	"White":  "?",
+	"Black":  "?",
+	"Result": "*",
+}
+
+// PgnWriter writes PGN games in export format to an io.Writer.
+type PgnWriter struct {
+	writer io.Writer
+}
+
+func NewPgnWriter(writer io.Writer) *PgnWriter {
+	return &PgnWriter{writer: writer}
+}
+
+// WriteGame writes a single game: the seven tag roster in order followed by the remaining tags in
+// the order given, a blank line, the movetext wrapped at 80 columns ending with the result and a
+// blank line separating it from the next game.
+func (w *PgnWriter) WriteGame(pgnGame PgnGame) error {
+	result := gameResult(pgnGame)
+
+	builder := strings.Builder{}
+	writeTagSection(&builder, pgnGame.TagSection, result)
+	builder.WriteString("\n")
+
+	movetext := newMovetextWriter()
+	movetext.writeElements(pgnGame.MoveText, true)
+	movetext.writeToken(result)
+	builder.WriteString(movetext.String())
+	builder.WriteString("\n\n")
+
+	_, err := io.WriteString(w.writer, builder.String())
+	return err
+}
+
+// ToPgnString returns the export format text of a single game.
+func ToPgnString(pgnGame PgnGame) string {
+	builder := strings.Builder{}
+	_ = NewPgnWriter(&builder).WriteGame(pgnGame)
+	return builder.String()
+}
+
+// FromChessGame builds a PgnGame from the moves played in a ChessGame. The given tags are kept, the
+// Result tag is set from the game and SetUp and FEN tags are added when the game did not start from
+// the standard position.
+func FromChessGame(chessGame *chess.ChessGame, tags []PgnTag) PgnGame {
+	result := ResultString(chessGame.GetResult())
+
+	tagSection := []PgnTag{}
+	for _, tag := range tags {
+		if tag.Name != "Result" && tag.Name != "SetUp" && tag.Name != "FEN" {
+			tagSection = append(tagSection, tag)
+		}
+	}
+	tagSection = append(tagSection, PgnTag{"Result", result})
+
+	start := chessGame.StartingPosition()
+	startFen := fen.ToFenString(start)
+	if startFen != standardStartFen {
+		tagSection = append(tagSection, PgnTag{"SetUp", "1"}, PgnTag{"FEN", startFen})
+	}
+
+	moveNumber := start.FullmoveNumber
+	if moveNumber < 1 {
+		moveNumber = 1
+	}
+	whiteToMove := start.PlayerToMove != game.BlackPiece
+
+	moveText := []PgnElement{}
+	for i, record := range chessGame.History() {
+		indicator := ""
+		if whiteToMove {
+			indicator = strconv.Itoa(moveNumber) + "."
+		} else if i == 0 {
+			indicator = strconv.Itoa(moveNumber) + "..."
+		}
+		moveText = append(moveText, PgnElement{MoveNumberIndicator: indicator, SanMove: record.San})
+
+		if !whiteToMove {
+			moveNumber++
+		}
+		whiteToMove = !whiteToMove
+	}
+
+	return PgnGame{TagSection: tagSection, MoveText: moveText, Result: result}
+}
+
+// ResultString returns the PGN game termination marker for a game result.
+func ResultString(result game.GameResult) string {
+	switch {
+	case result == game.WhiteWins:
+		return "1-0"
+	case result == game.BlackWins:
+		return "0-1"
+	case result.IsDraw():
+		return "1/2-1/2"
+	default:
+		return "*"
+	}
+}
+
+// gameResult picks the termination marker for a game, preferring the movetext result over the Result tag.
+func gameResult(pgnGame PgnGame) string {
+	if isGameResult(pgnGame.Result) {
+		return pgnGame.Result
+	}
+	for _, tag := range pgnGame.TagSection {
+		if tag.Name == "Result" && isGameResult(tag.Value) {
+			return tag.Value
+		}
+	}
+	return "*"
+}
+
+func writeTagSection(builder *strings.Builder, tags []PgnTag, result string) {
+	values := map[string]string{}
+	for _, tag := range tags {
+		if _, exists := values[tag.Name]; !exists {
+			values[tag.Name] = tag.Value
+		}
+	}
+	values["Result"] = result
+
+	roster := map[string]bool{}
+	for _, name := range SevenTagRoster {
+		roster[name] = true
+		value, exists := values[name]
+		if !exists || value == "" {
+			value = sevenTagRosterDefaults[name]
+		}
+		writeTag(builder, name, value)
+	}
+
+	for _, tag := range tags {
+		if !roster[tag.Name] {
+			writeTag(builder, tag.Name, tag.Value)
+		}
+	}
+}
+
+func writeTag(builder *strings.Builder, name string, value string) {
+	fmt.Fprintf(builder, "[%s \"%s\"]\n", name, escapeString(value))
+}
+
+// escapeString escapes the backslash and quote characters of a PGN string token.
+func escapeString(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	return strings.ReplaceAll(value, `"`, `\"`)
+}
+
+// movetextWriter joins movetext tokens with single spaces and starts a new line before a token
+// that would pass maxLineLength.
+type movetextWriter struct {
+	builder    strings.Builder
+	lineLength int
+	// attach writes the next token directly after the previous one, as after an opening parenthesis
+	attach bool
+
+	moveNumber  int
+	whiteToMove bool
+}
+
+func newMovetextWriter() *movetextWriter {
+	return &movetextWriter{moveNumber: 1, whiteToMove: true}
+}
+
+func (m *movetextWriter) String() string {
+	return m.builder.String()
+}
+
+func (m *movetextWriter) writeToken(token string) {
+	if m.attach && m.lineLength+len(token) <= maxLineLength {
+		m.attach = false
+	} else if m.lineLength > 0 {
+		m.attach = false
+		if m.lineLength+1+len(token) > maxLineLength {
+			m.builder.WriteString("\n")
+			m.lineLength = 0
+		} else {
+			m.builder.WriteString(" ")
+			m.lineLength++
+		}
+	}
+	m.builder.WriteString(token)
+	m.lineLength += len(token)
+}
+
+// writeComment writes a brace comment, splitting it across lines on spaces when it is too long to fit on one.
+func (m *movetextWriter) writeComment(comment string) {
+	comment = "{" + strings.Join(strings.Fields(comment), " ") + "}"
+	if len(comment) <= maxLineLength {
+		m.writeToken(comment)
+		return
+	}
+	for _, word := range strings.Fields(comment) {
+		m.writeToken(word)
+	}
+}
+
+// writeElements writes a line of moves. Move numbers are written before every white move and before
+// a black move that starts the line or follows a comment or variation.
+func (m *movetextWriter) writeElements(elements []PgnElement, startOfLine bool) {
+	for _, element := range elements {
+		if number, whiteToMove, ok := parseMoveNumberIndicator(element.MoveNumberIndicator); ok {
+			m.moveNumber, m.whiteToMove = number, whiteToMove
+		}
+
+		for _, comment := range element.LeadingComments {
+			m.writeComment(comment)
+			startOfLine = true
+		}
+
+		if m.whiteToMove {
+			m.writeToken(strconv.Itoa(m.moveNumber) + ".")
+		} else if startOfLine {
+			m.writeToken(strconv.Itoa(m.moveNumber) + "...")
+		}
+		m.writeToken(element.SanMove)
+		for _, glyph := range element.NumericAnnotationGlyphs {
+			m.writeToken("$" + glyph)
+		}
+
+		startOfLine = false
+		for _, comment := range element.Comments {
+			m.writeComment(comment)
+			startOfLine = true
+		}
+
+		// a variation replaces this move, so it starts from the same move number and side
+		for _, variation := range element.RecursiveAnnotation {
+			moveNumber, whiteToMove := m.moveNumber, m.whiteToMove
+			m.writeToken("(")
+			m.attach = true
+			m.writeElements(variation.Elements, true)
+			m.attach = true
+			m.writeToken(")")
+			m.moveNumber, m.whiteToMove = moveNumber, whiteToMove
+			startOfLine = true
+		}
+
+		if !m.whiteToMove {
+			m.moveNumber++
+		}
+		m.whiteToMove = !m.whiteToMove
+	}
+}
+
+// parseMoveNumberIndicator reads an indicator such as 12. or 12... into the move number and side to move.
+func parseMoveNumberIndicator(indicator string) (int, bool, bool) {
+	digits := strings.TrimRight(indicator, ".")
+	number, err := strconv.Atoi(digits)
+	if err != nil || number < 1 {
+		return 0, false, false
+	}
+	return number, len(indicator)-len(digits) < 3, true
+}
@@ -0,0 +1,143 @@
+package pgn
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/jerhon/chess/pkg/chess"
+	"github.com/jerhon/chess/pkg/chess/fen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteGame_RoundTripsSpecSample(t *testing.T) {
+	original, err := createPgnGameFromString(pgnSpecSample)
+	require.NoError(t, err)
+
+	written := ToPgnString(original)
+	reread, err := createPgnGameFromString(written)
+	require.NoError(t, err)
+
+	assert.Equal(t, original.TagSection, reread.TagSection)
+	assert.Equal(t, withoutPositions(original.MoveText), withoutPositions(reread.MoveText))
+	assert.Equal(t, original.Result, reread.Result)
+	assert.True(t, strings.HasPrefix(written, "[Event \"F/S Return Match\"]\n"))
+	assert.True(t, strings.HasSuffix(written, "43. Re6 1/2-1/2\n\n"))
+}
+
+func TestWriteGame_WrapsMovetextAt80Columns(t *testing.T) {
+	original, err := createPgnGameFromString(pgnSpecSample)
+	require.NoError(t, err)
+
+	for _, line := range strings.Split(ToPgnString(original), "\n") {
+		assert.LessOrEqual(t, len(line), maxLineLength, line)
+	}
+}
+
+func TestWriteGame_SevenTagRosterOrderAndDefaults(t *testing.T) {
+	pgnGame := PgnGame{
+		TagSection: []PgnTag{{"Annotator", "Someone"}, {"White", "Tal"}, {"Event", `The "Big" Match \ Final`}},
+		MoveText:   []PgnElement{NewPgnElement("1.", "e4")},
+	}
+
+	written := ToPgnString(pgnGame)
+
+	expected := `[Event "The \"Big\" Match \\ Final"]
+[Site "?"]
+[Date "????.??.??"]
+[Round "?"]
+[White "Tal"]
+[Black "?"]
+[Result "*"]
+[Annotator "Someone"]
+
+1. e4 *
+
+`
+	assert.Equal(t, expected, written)
+
+	reread, err := createPgnGameFromString(written)
+	require.NoError(t, err)
+	assert.Equal(t, `The "Big" Match \ Final`, reread.TagSection[0].Value)
+	assert.Equal(t, "*", reread.Result)
+}
+
+func TestWriteGame_Movetext(t *testing.T) {
+	testCases := []struct {
+		name     string
+		elements []PgnElement
+		expected string
+	}{
+		{"black to move first", []PgnElement{NewPgnElement("12...", "Nf6"), NewPgnElement("", "Bg5")}, "12... Nf6 13. Bg5 *"},
+		{"glyphs", []PgnElement{{MoveNumberIndicator: "1.", SanMove: "e4", NumericAnnotationGlyphs: []string{"1", "18"}}}, "1. e4 $1 $18 *"},
+		{"leading comment", []PgnElement{{MoveNumberIndicator: "1.", SanMove: "e4", LeadingComments: []string{"a classic"}}}, "{a classic} 1. e4 *"},
+		{"comment renumbers black", []PgnElement{{MoveNumberIndicator: "1.", SanMove: "e4", Comments: []string{"best by test"}}, NewPgnElement("", "e5")}, "1. e4 {best by test} 1... e5 *"},
+		{
+			"variation",
+			[]PgnElement{
+				NewPgnElement("1.", "e4"),
+				{SanMove: "e5", RecursiveAnnotation: []PgnElementSequence{
+					{[]PgnElement{NewPgnElement("1...", "c5"), NewPgnElement("", "Nf3")}},
+					{[]PgnElement{NewPgnElement("", "e6")}},
+				}},
+				NewPgnElement("", "Nf3"),
+			},
+			"1. e4 e5 (1... c5 2. Nf3) (1... e6) 2. Nf3 *",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			written := ToPgnString(PgnGame{MoveText: testCase.elements})
+			movetext := strings.SplitN(written, "\n\n", 2)[1]
+			assert.Equal(t, testCase.expected+"\n\n", movetext)
+		})
+	}
+}
+
+func TestPgnWriter_WritesMultipleGames(t *testing.T) {
+	buffer := bytes.Buffer{}
+	writer := NewPgnWriter(&buffer)
+	require.NoError(t, writer.WriteGame(PgnGame{MoveText: []PgnElement{NewPgnElement("1.", "e4")}, Result: "1-0"}))
+	require.NoError(t, writer.WriteGame(PgnGame{MoveText: []PgnElement{NewPgnElement("1.", "d4")}, Result: "0-1"}))
+
+	games := strings.Split(strings.TrimSpace(buffer.String()), "\n\n[Event")
+	assert.Len(t, games, 2)
+	assert.Contains(t, games[0], "1. e4 1-0")
+	assert.Contains(t, games[1], "1. d4 0-1")
+}
+
+func TestFromChessGame(t *testing.T) {
+	chessGame := chess.NewGame()
+	for _, move := range []string{"f3", "e5", "g4", "Qh4"} {
+		ok, err := chessGame.TrySanMove(move)
+		require.True(t, ok)
+		require.NoError(t, err)
+	}
+
+	pgnGame := FromChessGame(chessGame, []PgnTag{{"White", "Fool"}, {"Result", "*"}})
+	written := ToPgnString(pgnGame)
+	assert.Contains(t, written, "[Result \"0-1\"]")
+	assert.Contains(t, written, "1. f3 e5 2. g4 Qh4# 0-1")
+	assert.NotContains(t, written, "[FEN")
+
+	reread, err := createPgnGameFromString(written)
+	require.NoError(t, err)
+	assert.Equal(t, pgnGame.MoveText, withoutPositions(reread.MoveText))
+}
+
+func TestFromChessGame_NonStandardStart(t *testing.T) {
+	position, err := fen.ParseFen("4k3/8/8/8/8/8/4P3/4K3 b - - 0 40")
+	require.NoError(t, err)
+	chessGame := chess.NewGameFromPosition(&position)
+	for _, move := range []string{"Kd7", "e4"} {
+		ok, err := chessGame.TrySanMove(move)
+		require.True(t, ok)
+		require.NoError(t, err)
+	}
+
+	written := ToPgnString(FromChessGame(chessGame, nil))
+	assert.Contains(t, written, "[SetUp \"1\"]\n[FEN \"4k3/8/8/8/8/8/4P3/4K3 b - - 0 40\"]")
+	assert.Contains(t, written, "40... Kd7 41. e4 *")
+}
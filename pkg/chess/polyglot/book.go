@@ -0,0 +1,177 @@
+// Package polyglot reads, writes and builds opening books in the Polyglot format, the most widely used
+// book format among chess engines. The format is described at http://hgm.nubati.net/book_format.html.
+package polyglot
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"slices"
+
+	"github.com/jerhon/chess/pkg/chess/game"
+)
+
+// entrySize is the size of an entry in a book file.
+const entrySize = 16
+
+// Entry is one move of a book. Entries are stored big endian in the order of the fields.
+type Entry struct {
+	// Key is the hash of the position the move is played from, see game.ChessPosition.Hash
+	Key uint64
+	// Move is the move in the encoding of EncodeMove
+	Move uint16
+	// Weight is how often the move should be chosen relative to the other moves of the position, a move with
+	// no weight should not be played
+	Weight uint16
+	// Learn is left to the engine using the book, this package keeps it but does not use it
+	Learn uint32
+}
+
+// Book is an opening book held in memory with its entries sorted by key.
+type Book struct {
+	entries []Entry
+}
+
+// BookMove is a book entry decoded for a position.
+type BookMove struct {
+	Move game.ChessMove
+	// Promotion is the piece a pawn promotes to, NoPiece if the move is not a promotion
+	Promotion game.PieceType
+	Weight    uint16
+	Learn     uint32
+}
+
+// UciString returns the move in UCI notation.
+func (m BookMove) UciString() string {
+	return m.Move.UciString(m.Promotion)
+}
+
+// NewBook makes a book from entries in any order.
+func NewBook(entries []Entry) *Book {
+	sorted := slices.Clone(entries)
+	slices.SortStableFunc(sorted, compareEntries)
+	return &Book{entries: sorted}
+}
+
+// compareEntries orders entries by key and then by falling weight, the order Polyglot writes them in.
+func compareEntries(a Entry, b Entry) int {
+	if c := cmp.Compare(a.Key, b.Key); c != 0 {
+		return c
+	}
+	return cmp.Compare(b.Weight, a.Weight)
+}
+
+// ReadBook reads every entry of a book. The entries are sorted if the file was not.
+func ReadBook(reader io.Reader) (*Book, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	if len(data)%entrySize != 0 {
+		return nil, fmt.Errorf("book size %d is not a multiple of the %d byte entry size", len(data), entrySize)
+	}
+
+	entries := make([]Entry, len(data)/entrySize)
+	if err := binary.Read(bytes.NewReader(data), binary.BigEndian, entries); err != nil {
+		return nil, err
+	}
+	if !slices.IsSortedFunc(entries, func(a Entry, b Entry) int { return cmp.Compare(a.Key, b.Key) }) {
+		slices.SortStableFunc(entries, compareEntries)
+	}
+	return &Book{entries: entries}, nil
+}
+
+// LoadBook reads the book file at path.
+func LoadBook(path string) (*Book, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ReadBook(file)
+}
+
+// WriteTo writes the book in the Polyglot file format.
+func (b *Book) WriteTo(writer io.Writer) (int64, error) {
+	buffer := bytes.Buffer{}
+	if err := binary.Write(&buffer, binary.BigEndian, b.entries); err != nil {
+		return 0, err
+	}
+	return buffer.WriteTo(writer)
+}
+
+// Entries returns every entry of the book in sorted order.
+func (b *Book) Entries() []Entry {
+	return slices.Clone(b.entries)
+}
+
+// Lookup returns the entries for a position, highest weight first.
+func (b *Book) Lookup(position *game.ChessPosition) []Entry {
+	key := position.Hash()
+	start, _ := slices.BinarySearchFunc(b.entries, key, func(entry Entry, key uint64) int {
+		return cmp.Compare(entry.Key, key)
+	})
+	end := start
+	for end < len(b.entries) && b.entries[end].Key == key {
+		end++
+	}
+	return slices.Clone(b.entries[start:end])
+}
+
+// Moves returns the book moves for a position, highest weight first. Entries that are not legal in the
+// position are skipped.
+func (b *Book) Moves(position *game.ChessPosition) []BookMove {
+	moves := []BookMove{}
+	for _, entry := range b.Lookup(position) {
+		move, promotion, err := DecodeMove(position, entry.Move)
+		if err != nil {
+			continue
+		}
+		moves = append(moves, BookMove{Move: move, Promotion: promotion, Weight: entry.Weight, Learn: entry.Learn})
+	}
+	return moves
+}
+
+// ErrNoBookMove is returned when a book has no move to play in a position.
+var ErrNoBookMove = errors.New("no book move for the position")
+
+// BestMove returns the book move with the highest weight.
+func (b *Book) BestMove(position *game.ChessPosition) (BookMove, error) {
+	moves := b.Moves(position)
+	if len(moves) == 0 || moves[0].Weight == 0 {
+		return BookMove{}, ErrNoBookMove
+	}
+	return moves[0], nil
+}
+
+// WeightedMove picks a book move at random with a chance in proportion to its weight, so moves with no
+// weight are never chosen. When random is nil the shared source of math/rand is used.
+func (b *Book) WeightedMove(position *game.ChessPosition, random *rand.Rand) (BookMove, error) {
+	moves := b.Moves(position)
+	total := 0
+	for _, move := range moves {
+		total += int(move.Weight)
+	}
+	if total == 0 {
+		return BookMove{}, ErrNoBookMove
+	}
+
+	var pick int
+	if random != nil {
+		pick = random.Intn(total)
+	} else {
+		pick = rand.Intn(total)
+	}
+	for _, move := range moves {
+		pick -= int(move.Weight)
+		if pick < 0 {
+			return move, nil
+		}
+	}
+	return moves[len(moves)-1], nil
+}
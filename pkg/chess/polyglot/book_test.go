@@ -0,0 +1,184 @@
+package polyglot
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/jerhon/chess/pkg/chess/fen"
+	"github.com/jerhon/chess/pkg/chess/game"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parsePosition(t *testing.T, fenString string) *game.ChessPosition {
+	t.Helper()
+	position, err := fen.ParseFen(fenString)
+	require.NoError(t, err)
+	return &position
+}
+
+// legalMove finds a legal move of a position from its UCI notation.
+func legalMove(t *testing.T, position *game.ChessPosition, uci string) game.ChessMove {
+	t.Helper()
+	movement := game.NewChessMovement(position)
+	movement.Calculate()
+	for _, move := range movement.Moves {
+		if move.UciString(game.NoPiece) == uci[:4] {
+			return move
+		}
+	}
+	require.FailNow(t, "move is not legal", uci)
+	return game.ChessMove{}
+}
+
+func TestEncodeMove(t *testing.T) {
+	start := game.NewStandardStartingPosition()
+	castling := parsePosition(t, "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1")
+	promotion := parsePosition(t, "8/P6k/8/8/8/8/8/K7 w - - 0 1")
+
+	tests := []struct {
+		name      string
+		position  *game.ChessPosition
+		uci       string
+		promotion game.PieceType
+		encoded   uint16
+	}{
+		{"pawn push", start, "e2e4", game.NoPiece, 12<<6 | 28},
+		{"knight", start, "g1f3", game.NoPiece, 6<<6 | 21},
+		{"castle kingside as king takes rook", castling, "e1g1", game.NoPiece, 4<<6 | 7},
+		{"castle queenside as king takes rook", castling, "e1c1", game.NoPiece, 4<<6 | 0},
+		{"queen promotion", promotion, "a7a8q", game.Queen, 4<<12 | 48<<6 | 56},
+		{"knight promotion", promotion, "a7a8n", game.Knight, 1<<12 | 48<<6 | 56},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			move := legalMove(t, test.position, test.uci)
+			assert.Equal(t, test.encoded, EncodeMove(move, test.promotion))
+
+			decoded, promotion, err := DecodeMove(test.position, test.encoded)
+			require.NoError(t, err)
+			assert.Equal(t, move, decoded)
+			assert.Equal(t, test.promotion, promotion)
+			assert.Equal(t, test.uci, decoded.UciString(promotion))
+		})
+	}
+}
+
+func TestDecodeMove_IllegalMove(t *testing.T) {
+	_, _, err := DecodeMove(game.NewStandardStartingPosition(), 12<<6|36)
+	assert.ErrorContains(t, err, "e2e5")
+
+	_, _, err = DecodeMove(game.NewStandardStartingPosition(), 7<<12|12<<6|28)
+	assert.ErrorContains(t, err, "unknown promotion")
+}
+
+// startBook has two moves from the starting position and one from the position after 1. e4.
+func startBook(t *testing.T) *Book {
+	afterE4 := parsePosition(t, "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1")
+	return NewBook([]Entry{
+		{Key: afterE4.Hash(), Move: 52<<6 | 36, Weight: 5},
+		{Key: game.NewStandardStartingPosition().Hash(), Move: 11<<6 | 27, Weight: 10},
+		{Key: game.NewStandardStartingPosition().Hash(), Move: 12<<6 | 28, Weight: 30, Learn: 7},
+	})
+}
+
+func TestBook_ReadWriteRoundTrip(t *testing.T) {
+	book := startBook(t)
+
+	buffer := bytes.Buffer{}
+	written, err := book.WriteTo(&buffer)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3*entrySize), written)
+
+	// the starting position key is the smallest, so its best move is written first
+	data := buffer.Bytes()
+	assert.Equal(t, []byte{0x46, 0x3b, 0x96, 0x18, 0x16, 0x91, 0xfc, 0x9c, 0x03, 0x1c, 0x00, 0x1e, 0x00, 0x00, 0x00, 0x07}, data[0:16])
+
+	read, err := ReadBook(&buffer)
+	require.NoError(t, err)
+	assert.Equal(t, book.Entries(), read.Entries())
+}
+
+func TestReadBook_SortsEntries(t *testing.T) {
+	unsorted := bytes.Buffer{}
+	for _, key := range []byte{3, 1, 2} {
+		unsorted.Write([]byte{0, 0, 0, 0, 0, 0, 0, key, 0, 0, 0, 1, 0, 0, 0, 0})
+	}
+
+	book, err := ReadBook(&unsorted)
+	require.NoError(t, err)
+	keys := []uint64{}
+	for _, entry := range book.Entries() {
+		keys = append(keys, entry.Key)
+	}
+	assert.Equal(t, []uint64{1, 2, 3}, keys)
+}
+
+func TestReadBook_TruncatedFile(t *testing.T) {
+	_, err := ReadBook(bytes.NewReader(make([]byte, 20)))
+	assert.ErrorContains(t, err, "not a multiple")
+}
+
+func TestBook_Moves(t *testing.T) {
+	book := startBook(t)
+
+	moves := book.Moves(game.NewStandardStartingPosition())
+	require.Len(t, moves, 2)
+	assert.Equal(t, "e2e4", moves[0].UciString())
+	assert.Equal(t, uint16(30), moves[0].Weight)
+	assert.Equal(t, uint32(7), moves[0].Learn)
+	assert.Equal(t, "d2d4", moves[1].UciString())
+
+	assert.Empty(t, book.Moves(parsePosition(t, "4k3/8/8/8/8/8/8/4K3 w - - 0 1")))
+}
+
+func TestBook_MovesSkipsIllegalEntries(t *testing.T) {
+	start := game.NewStandardStartingPosition()
+	book := NewBook([]Entry{{Key: start.Hash(), Move: 12<<6 | 36, Weight: 1}, {Key: start.Hash(), Move: 12<<6 | 28, Weight: 1}})
+
+	moves := book.Moves(start)
+	require.Len(t, moves, 1)
+	assert.Equal(t, "e2e4", moves[0].UciString())
+}
+
+func TestBook_BestMove(t *testing.T) {
+	book := startBook(t)
+
+	move, err := book.BestMove(game.NewStandardStartingPosition())
+	require.NoError(t, err)
+	assert.Equal(t, "e2e4", move.UciString())
+
+	_, err = book.BestMove(parsePosition(t, "4k3/8/8/8/8/8/8/4K3 w - - 0 1"))
+	assert.ErrorIs(t, err, ErrNoBookMove)
+}
+
+func TestBook_WeightedMove(t *testing.T) {
+	start := game.NewStandardStartingPosition()
+	book := NewBook([]Entry{
+		{Key: start.Hash(), Move: 12<<6 | 28, Weight: 3},
+		{Key: start.Hash(), Move: 11<<6 | 27, Weight: 1},
+		{Key: start.Hash(), Move: 10<<6 | 26, Weight: 0},
+	})
+
+	random := rand.New(rand.NewSource(1))
+	counts := map[string]int{}
+	for i := 0; i < 4000; i++ {
+		move, err := book.WeightedMove(start, random)
+		require.NoError(t, err)
+		counts[move.UciString()]++
+	}
+	assert.InDelta(t, 3000, counts["e2e4"], 150)
+	assert.InDelta(t, 1000, counts["d2d4"], 150)
+	assert.Zero(t, counts["c2c4"])
+}
+
+func TestBook_WeightedMoveWithoutWeights(t *testing.T) {
+	start := game.NewStandardStartingPosition()
+	book := NewBook([]Entry{{Key: start.Hash(), Move: 12<<6 | 28}})
+
+	_, err := book.WeightedMove(start, nil)
+	assert.ErrorIs(t, err, ErrNoBookMove)
+	_, err = book.BestMove(start)
+	assert.ErrorIs(t, err, ErrNoBookMove)
+}
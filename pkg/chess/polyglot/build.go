@@ -0,0 +1,149 @@
+package polyglot
+
+// build.go makes a book from the games of a PGN collection
+
+import (
+	"io"
+
+	"github.com/jerhon/chess/pkg/chess"
+	"github.com/jerhon/chess/pkg/chess/game"
+	"github.com/jerhon/chess/pkg/chess/pgn"
+)
+
+// BuildOptions controls which moves of a PGN collection go into a book.
+type BuildOptions struct {
+	// MaxPly is how many plies of each game are added, zero adds every move
+	MaxPly int
+	// MinGames is how many games a move must be played in to be added
+	MinGames int
+}
+
+// DefaultBuildOptions returns the options Polyglot itself builds books with.
+func DefaultBuildOptions() BuildOptions {
+	return BuildOptions{MaxPly: 1024, MinGames: 3}
+}
+
+// Builder collects the moves of games into a book. A move is weighted by how well it scored for the side
+// that played it, 2 for each win and 1 for each draw, so a move that was only ever lost has no weight.
+type Builder struct {
+	options BuildOptions
+	moves   map[bookKey]*moveStats
+}
+
+type bookKey struct {
+	key  uint64
+	move uint16
+}
+
+type moveStats struct {
+	games int
+	score int
+}
+
+// NewBuilder makes an empty builder.
+func NewBuilder(options BuildOptions) *Builder {
+	return &Builder{options: options, moves: map[bookKey]*moveStats{}}
+}
+
+// Add adds the moves of a game. When a move of the game cannot be played the moves before it are still
+// added and the *pgn.ReplayError is returned.
+func (b *Builder) Add(pgnGame pgn.PgnGame) error {
+	replayed, err := pgn.Replay(pgnGame)
+	if replayed == nil {
+		return err
+	}
+
+	for ply, record := range replayed.Game.History() {
+		if b.options.MaxPly > 0 && ply >= b.options.MaxPly {
+			break
+		}
+		position := replayed.Positions[ply]
+		key := bookKey{position.Hash(), encodeRecord(record)}
+		stats := b.moves[key]
+		if stats == nil {
+			stats = &moveStats{}
+			b.moves[key] = stats
+		}
+		stats.games++
+		stats.score += resultScore(pgnGame.Result, record.Piece.Color == game.WhitePiece)
+	}
+	return err
+}
+
+// Book returns a book of the moves played in at least MinGames games. Weights are scaled down where needed
+// so the largest weight of each position fits the 16 bits of an entry.
+func (b *Builder) Book() *Book {
+	entries := []Entry{}
+	largest := map[uint64]int{}
+	for key, stats := range b.moves {
+		if stats.games < b.options.MinGames {
+			continue
+		}
+		entries = append(entries, Entry{Key: key.key, Move: key.move})
+		largest[key.key] = max(largest[key.key], stats.score)
+	}
+	for i := range entries {
+		score := b.moves[bookKey{entries[i].Key, entries[i].Move}].score
+		if top := largest[entries[i].Key]; top > maxWeight {
+			score = score * maxWeight / top
+		}
+		entries[i].Weight = uint16(score)
+	}
+	return NewBook(entries)
+}
+
+const maxWeight = 0xffff
+
+// encodeRecord encodes a move that was played in a game.
+func encodeRecord(record chess.MoveRecord) uint16 {
+	move := game.ChessMove{
+		From:         game.ChessSquare{Location: record.From, Piece: record.Piece},
+		To:           record.To,
+		IsCastle:     record.IsCastle,
+		IsPromotion:  record.Promotion != game.NoPiece,
+		CastlingRook: record.CastlingRook,
+	}
+	return EncodeMove(move, record.Promotion)
+}
+
+// resultScore scores a game result for one side, 2 for a win, 1 for a draw and 0 for a loss or an
+// unfinished game.
+func resultScore(result string, white bool) int {
+	switch {
+	case result == "1/2-1/2":
+		return 1
+	case result == "1-0" && white, result == "0-1" && !white:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// BuildBook makes a book from every game of a PGN collection. Games that cannot be read or replayed are
+// skipped, adding the moves that could be played, so only an error reading from reader is returned.
+func BuildBook(reader io.Reader, options BuildOptions) (*Book, error) {
+	source := &errorReader{reader: reader}
+	builder := NewBuilder(options)
+	for pgnGame := range pgn.ReadGames(source) {
+		_ = builder.Add(pgnGame)
+	}
+	if source.err != nil {
+		return nil, source.err
+	}
+	return builder.Book(), nil
+}
+
+// errorReader remembers the first error other than io.EOF, as ReadGames reports it like any other game
+// that cannot be read.
+type errorReader struct {
+	reader io.Reader
+	err    error
+}
+
+func (r *errorReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if err != nil && err != io.EOF && r.err == nil {
+		r.err = err
+	}
+	return n, err
+}
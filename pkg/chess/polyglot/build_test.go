@@ -0,0 +1,108 @@
+package polyglot
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jerhon/chess/pkg/chess/game"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const collection = `[Event "One"]
+[Result "1-0"]
+
+1. e4 e5 2. Nf3 1-0
+
+[Event "Two"]
+[Result "0-1"]
+
+1. e4 c5 0-1
+
+[Event "Three"]
+[Result "1/2-1/2"]
+
+1. d4 d5 1/2-1/2
+
+[Event "Four"]
+[Result "1-0"]
+
+1. e4 e5 2. Qh7 1-0
+`
+
+// weights returns the UCI notation and weight of each book move of a position.
+func weights(book *Book, position *game.ChessPosition) map[string]uint16 {
+	result := map[string]uint16{}
+	for _, move := range book.Moves(position) {
+		result[move.UciString()] = move.Weight
+	}
+	return result
+}
+
+func TestBuildBook(t *testing.T) {
+	book, err := BuildBook(strings.NewReader(collection), BuildOptions{MinGames: 1})
+	require.NoError(t, err)
+
+	start := game.NewStandardStartingPosition()
+	assert.Equal(t, map[string]uint16{"e2e4": 4, "d2d4": 1}, weights(book, start))
+
+	afterE4 := parsePosition(t, "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1")
+	assert.Equal(t, map[string]uint16{"e7e5": 0, "c7c5": 2}, weights(book, afterE4))
+
+	// the illegal second move of the last game is left out but its first moves count
+	afterE5 := parsePosition(t, "rnbqkbnr/pppp1ppp/8/4p3/4P3/8/PPPP1PPP/RNBQKBNR w KQkq e6 0 2")
+	assert.Equal(t, map[string]uint16{"g1f3": 2}, weights(book, afterE5))
+}
+
+func TestBuildBook_MinGames(t *testing.T) {
+	book, err := BuildBook(strings.NewReader(collection), BuildOptions{MinGames: 2})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]uint16{"e2e4": 4}, weights(book, game.NewStandardStartingPosition()))
+	assert.Len(t, book.Entries(), 2)
+}
+
+func TestBuildBook_MaxPly(t *testing.T) {
+	book, err := BuildBook(strings.NewReader(collection), BuildOptions{MaxPly: 1, MinGames: 1})
+	require.NoError(t, err)
+
+	assert.Len(t, book.Entries(), 2)
+	assert.Equal(t, map[string]uint16{"e2e4": 4, "d2d4": 1}, weights(book, game.NewStandardStartingPosition()))
+}
+
+func TestBuilder_Castling(t *testing.T) {
+	book, err := BuildBook(strings.NewReader(`[Result "1-0"]
+
+1. e4 e5 2. Nf3 Nc6 3. Bc4 Bc5 4. O-O 1-0`), BuildOptions{MinGames: 1})
+	require.NoError(t, err)
+
+	position := parsePosition(t, "r1bqk1nr/pppp1ppp/2n5/2b1p3/2B1P3/5N2/PPPP1PPP/RNBQK2R w KQkq - 4 4")
+	entries := book.Lookup(position)
+	require.Len(t, entries, 1)
+	assert.Equal(t, uint16(4<<6|7), entries[0].Move)
+	assert.Equal(t, map[string]uint16{"e1g1": 2}, weights(book, position))
+}
+
+func TestBuilder_ScalesLargeWeights(t *testing.T) {
+	builder := NewBuilder(BuildOptions{MinGames: 1})
+	key := game.NewStandardStartingPosition().Hash()
+	builder.moves[bookKey{key, 12<<6 | 28}] = &moveStats{games: 50000, score: 100000}
+	builder.moves[bookKey{key, 11<<6 | 27}] = &moveStats{games: 50000, score: 50000}
+
+	entries := builder.Book().Entries()
+	require.Len(t, entries, 2)
+	assert.Equal(t, uint16(maxWeight), entries[0].Weight)
+	assert.Equal(t, uint16(maxWeight/2), entries[1].Weight)
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("disk on fire")
+}
+
+func TestBuildBook_ReadError(t *testing.T) {
+	_, err := BuildBook(failingReader{}, DefaultBuildOptions())
+	assert.ErrorContains(t, err, "disk on fire")
+}
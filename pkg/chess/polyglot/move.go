@@ -0,0 +1,75 @@
+package polyglot
+
+// move.go converts between the 16 bit move of a book entry and the moves of the game package
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jerhon/chess/pkg/chess/game"
+)
+
+// promotionPieces maps the promotion field of a book move to its piece, 0 being no promotion.
+var promotionPieces = []game.PieceType{game.NoPiece, game.Knight, game.Bishop, game.Rook, game.Queen}
+
+// EncodeMove packs a move into the book format: the to file in bits 0-2, the to rank in bits 3-5, the from
+// file in bits 6-8, the from rank in bits 9-11 and the promotion piece in bits 12-14. Castling is written as
+// the king capturing its own rook.
+func EncodeMove(move game.ChessMove, promotionPiece game.PieceType) uint16 {
+	to := move.To
+	if move.IsCastle {
+		to = move.CastlingRook
+	}
+
+	encoded := uint16(to.File.ToIndex()) | uint16(to.Rank.ToIndex())<<3 |
+		uint16(move.From.Location.File.ToIndex())<<6 | uint16(move.From.Location.Rank.ToIndex())<<9
+	if move.IsPromotion {
+		for i, piece := range promotionPieces {
+			if i > 0 && piece == promotionPiece {
+				encoded |= uint16(i) << 12
+			}
+		}
+	}
+	return encoded
+}
+
+// DecodeMove finds the legal move of a position that a book move stands for, along with the piece a pawn
+// promotes to. An error is returned when the book move is not legal in the position, which happens when two
+// positions share a hash or the book is damaged.
+func DecodeMove(position *game.ChessPosition, move uint16) (game.ChessMove, game.PieceType, error) {
+	from := game.LocationFromIndex(int(move>>6) & 0x3f)
+	to := game.LocationFromIndex(int(move) & 0x3f)
+	promotion := int(move>>12) & 0x7
+	if promotion >= len(promotionPieces) {
+		return game.ChessMove{}, game.NoPiece, fmt.Errorf("book move %s%s has an unknown promotion %d", from, to, promotion)
+	}
+	promotionPiece := promotionPieces[promotion]
+
+	// the king taking its own rook is castling with that rook
+	king, rook := position.Board.GetSquare(from).Piece, position.Board.GetSquare(to).Piece
+	castle := king.Piece == game.King && rook.Piece == game.Rook && king.Color == rook.Color
+
+	movement := game.NewChessMovement(position)
+	movement.Calculate()
+	for _, legal := range movement.Moves {
+		if legal.From.Location != from || legal.IsCastle != castle {
+			continue
+		}
+		if (castle && legal.CastlingRook != to) || (!castle && legal.To != to) {
+			continue
+		}
+		if legal.IsPromotion != (promotionPiece != game.NoPiece) {
+			continue
+		}
+		return legal, promotionPiece, nil
+	}
+	return game.ChessMove{}, game.NoPiece, fmt.Errorf("book move %s is not legal in the position", moveText(from, to, promotionPiece))
+}
+
+func moveText(from game.ChessLocation, to game.ChessLocation, promotionPiece game.PieceType) string {
+	text := from.String() + to.String()
+	if promotionPiece != game.NoPiece {
+		text += strings.ToLower(string(promotionPiece))
+	}
+	return text
+}
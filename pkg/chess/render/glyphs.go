@@ -0,0 +1,42 @@
+package render
+
+import "github.com/jerhon/chess/pkg/chess/game"
+
+// glyphSize is the width and height of the box the piece glyphs are drawn in.
+const glyphSize = 45
+
+// glyphs holds the outline of each piece drawn in a glyphSize box, as SVG elements without fill or stroke so
+// the same outline serves both colors.
+var glyphs = map[game.PieceType]string{
+	game.Pawn: `<circle cx="22.5" cy="14" r="5.5"/>` +
+		`<path d="M15 35L18 24Q22.5 19.5 27 24L30 35Z"/>` +
+		`<path d="M11 39.5h23v-4.5H11Z"/>`,
+	game.Rook: `<path d="M12 35L14 30V18H31V30L33 35Z"/>` +
+		`<path d="M11 18V9h5v3h4V9h5v3h4V9h5v9Z"/>` +
+		`<path d="M9 39.5h27V35H9Z"/>`,
+	game.Knight: `<path d="M14 35L15 28Q12 21 18 15L20 9L23 12.5Q31.5 14 33.5 25L31 28.5L26 24.5L24.5 29.5L30 35Z"/>` +
+		`<circle cx="23" cy="17" r="1.5"/>` +
+		`<path d="M9 39.5h27V35H9Z"/>`,
+	game.Bishop: `<circle cx="22.5" cy="9" r="3"/>` +
+		`<path d="M15 34Q13.5 25 22.5 12Q31.5 25 30 34Z"/>` +
+		`<path d="M22.5 22L26.5 17"/>` +
+		`<path d="M9 39.5h27V34H9Z"/>`,
+	game.Queen: `<path d="M12 35L9.5 15L16 26L18 11L22.5 25L27 11L29 26L35.5 15L33 35Z"/>` +
+		`<circle cx="9.5" cy="13" r="2.5"/><circle cx="18" cy="9" r="2.5"/><circle cx="27" cy="9" r="2.5"/>` +
+		`<circle cx="35.5" cy="13" r="2.5"/>` +
+		`<path d="M9 39.5h27V35H9Z"/>`,
+	game.King: `<path d="M21 5h3v4h4v3h-4v5h-3v-5h-4V9h4Z"/>` +
+		`<path d="M13 35L11 22Q22.5 15 34 22L32 35Z"/>` +
+		`<path d="M9 39.5h27V35H9Z"/>`,
+}
+
+// glyphColors are the fill and stroke of the pieces of each color.
+var glyphColors = map[game.ColorType][2]string{
+	game.WhitePiece: {"#ffffff", "#000000"},
+	game.BlackPiece: {"#000000", "#ffffff"},
+}
+
+// glyphID names the definition of a piece in the SVG, e.g. wK or bP.
+func glyphID(piece game.ChessPiece) string {
+	return string(rune(piece.Color)) + string(rune(piece.Piece))
+}
@@ -0,0 +1,303 @@
+// Package render draws chess positions as standalone SVG diagrams, for puzzles and game reports.
+package render
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/jerhon/chess/pkg/chess/game"
+)
+
+const (
+	// DefaultSize is the width and height of a diagram in pixels when Options.Size is zero
+	DefaultSize = 400
+
+	DefaultLightSquare   = "#f0d9b5"
+	DefaultDarkSquare    = "#b58863"
+	DefaultLastMoveColor = "#cdd26a"
+	DefaultCheckColor    = "#e8483c"
+	DefaultArrowColor    = "#15781b"
+	DefaultHighlight     = "#6fa8dc"
+)
+
+// Highlight colors a square, drawn over the square and under the piece on it.
+type Highlight struct {
+	Square game.ChessLocation
+	// Color is an SVG color, DefaultHighlight when empty
+	Color string
+}
+
+// Arrow is drawn from the center of one square to the center of another, over the pieces.
+type Arrow struct {
+	From game.ChessLocation
+	To   game.ChessLocation
+	// Color is an SVG color, DefaultArrowColor when empty
+	Color string
+}
+
+// Options configures a diagram. The zero value draws a DefaultSize board from white's side without
+// coordinates or highlights, see DefaultOptions for the usual diagram.
+type Options struct {
+	// Size is the width and height of the diagram in pixels
+	Size int
+	// LightSquare and DarkSquare are the SVG colors of the squares
+	LightSquare string
+	DarkSquare  string
+	// Orientation is the side drawn at the bottom, white when NoColor
+	Orientation game.ColorType
+	// Coordinates labels the files along the bottom edge and the ranks along the left edge
+	Coordinates bool
+
+	// LastMove lists the squares of the last move, usually its from and to squares, colored LastMoveColor
+	LastMove      []game.ChessLocation
+	LastMoveColor string
+	// Check colors the square of the king of the side to move with CheckColor when it is in check
+	Check      bool
+	CheckColor string
+
+	Highlights []Highlight
+	Arrows     []Arrow
+}
+
+// DefaultOptions returns the options of the usual diagram: the default size and colors, with coordinates and
+// the king in check marked.
+func DefaultOptions() Options {
+	return Options{
+		Size:        DefaultSize,
+		LightSquare: DefaultLightSquare,
+		DarkSquare:  DefaultDarkSquare,
+		Orientation: game.WhitePiece,
+		Coordinates: true,
+		Check:       true,
+	}
+}
+
+// withDefaults fills in the options left empty.
+func (options Options) withDefaults() Options {
+	if options.Size <= 0 {
+		options.Size = DefaultSize
+	}
+	defaults := []struct {
+		value    *string
+		fallback string
+	}{
+		{&options.LightSquare, DefaultLightSquare},
+		{&options.DarkSquare, DefaultDarkSquare},
+		{&options.LastMoveColor, DefaultLastMoveColor},
+		{&options.CheckColor, DefaultCheckColor},
+	}
+	for _, d := range defaults {
+		if *d.value == "" {
+			*d.value = d.fallback
+		}
+	}
+	if options.Orientation != game.BlackPiece {
+		options.Orientation = game.WhitePiece
+	}
+	return options
+}
+
+// SVG returns the diagram of a position as a standalone SVG document.
+func SVG(position *game.ChessPosition, options Options) string {
+	builder := strings.Builder{}
+	// a strings.Builder never fails to write
+	_ = WriteSVG(&builder, position, options)
+	return builder.String()
+}
+
+// WriteSVG writes the diagram of a position as a standalone SVG document.
+func WriteSVG(writer io.Writer, position *game.ChessPosition, options Options) error {
+	options = options.withDefaults()
+	d := diagram{options: options, square: float64(options.Size) / 8}
+
+	d.printf(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	d.printf(`<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		options.Size, options.Size, options.Size, options.Size)
+	d.writeDefinitions(position)
+	d.writeSquares(position)
+	if options.Coordinates {
+		d.writeCoordinates()
+	}
+	d.writePieces(position)
+	d.writeArrows()
+	d.printf("</svg>\n")
+
+	_, err := io.WriteString(writer, d.builder.String())
+	return err
+}
+
+// diagram builds the SVG of a position.
+type diagram struct {
+	options Options
+	// square is the width of a square in pixels
+	square  float64
+	builder strings.Builder
+}
+
+func (d *diagram) printf(format string, args ...any) {
+	fmt.Fprintf(&d.builder, format, args...)
+}
+
+// origin returns the top left corner of a square in pixels.
+func (d *diagram) origin(location game.ChessLocation) (float64, float64) {
+	column, row := location.File.ToIndex(), 7-location.Rank.ToIndex()
+	if d.options.Orientation == game.BlackPiece {
+		column, row = 7-column, 7-row
+	}
+	return float64(column) * d.square, float64(row) * d.square
+}
+
+// center returns the center of a square in pixels.
+func (d *diagram) center(location game.ChessLocation) (float64, float64) {
+	x, y := d.origin(location)
+	return x + d.square/2, y + d.square/2
+}
+
+// writeDefinitions defines a glyph for each piece on the board, drawn with <use>.
+func (d *diagram) writeDefinitions(position *game.ChessPosition) {
+	defined := map[game.ChessPiece]bool{}
+	d.printf("<defs>\n")
+	for square := range position.Board.IterateSquares() {
+		piece := square.Piece
+		if piece.Piece == game.NoPiece || defined[piece] {
+			continue
+		}
+		defined[piece] = true
+		colors := glyphColors[piece.Color]
+		d.printf(`<g id="%s" fill="%s" stroke="%s" stroke-width="1.5" stroke-linejoin="round">%s</g>`+"\n",
+			glyphID(piece), colors[0], colors[1], glyphs[piece.Piece])
+	}
+	d.printf("</defs>\n")
+}
+
+// writeSquares draws the squares with their highlights: the last move, then the king in check, then the
+// highlights of the options, so the later ones are drawn over the earlier ones.
+func (d *diagram) writeSquares(position *game.ChessPosition) {
+	for square := range position.Board.IterateSquares() {
+		color := d.options.DarkSquare
+		if (square.Location.File.ToIndex()+square.Location.Rank.ToIndex())%2 == 1 {
+			color = d.options.LightSquare
+		}
+		d.writeSquare(square.Location, color, "")
+	}
+
+	for _, location := range d.options.LastMove {
+		d.writeSquare(location, d.options.LastMoveColor, ` fill-opacity="0.8"`)
+	}
+	if d.options.Check {
+		king := position.Board.PieceBitboard(game.ChessPiece{Piece: game.King, Color: position.PlayerToMove})
+		if king.Count() == 1 {
+			location := game.LocationFromIndex(king.First())
+			if game.IsSquareAttacked(position.Board, location, position.PlayerToMove.OppositeColor()) {
+				d.writeSquare(location, d.options.CheckColor, ` fill-opacity="0.8"`)
+			}
+		}
+	}
+	for _, highlight := range d.options.Highlights {
+		color := highlight.Color
+		if color == "" {
+			color = DefaultHighlight
+		}
+		d.writeSquare(highlight.Square, color, ` fill-opacity="0.8"`)
+	}
+}
+
+func (d *diagram) writeSquare(location game.ChessLocation, color string, attributes string) {
+	x, y := d.origin(location)
+	d.printf(`<rect x="%s" y="%s" width="%s" height="%s" fill="%s"%s/>`+"\n",
+		number(x), number(y), number(d.square), number(d.square), attribute(color), attributes)
+}
+
+// writeCoordinates labels the files inside the bottom row and the ranks inside the left column, in the color
+// of the opposite square so they stay readable.
+func (d *diagram) writeCoordinates() {
+	fontSize := d.square / 5
+	margin := d.square / 16
+
+	for file := game.FileA; file <= game.FileH; file++ {
+		location := game.ChessLocation{File: file, Rank: game.Rank1}
+		if d.options.Orientation == game.BlackPiece {
+			location.Rank = game.Rank8
+		}
+		x, y := d.origin(location)
+		d.printf(`<text x="%s" y="%s" font-family="sans-serif" font-size="%s" font-weight="bold" text-anchor="end" fill="%s">%s</text>`+"\n",
+			number(x+d.square-margin), number(y+d.square-margin), number(fontSize), attribute(d.labelColor(location)), file)
+	}
+	for rank := game.Rank1; rank <= game.Rank8; rank++ {
+		location := game.ChessLocation{File: game.FileA, Rank: rank}
+		if d.options.Orientation == game.BlackPiece {
+			location.File = game.FileH
+		}
+		x, y := d.origin(location)
+		d.printf(`<text x="%s" y="%s" font-family="sans-serif" font-size="%s" font-weight="bold" fill="%s">%s</text>`+"\n",
+			number(x+margin), number(y+margin+fontSize), number(fontSize), attribute(d.labelColor(location)), rank)
+	}
+}
+
+func (d *diagram) labelColor(location game.ChessLocation) string {
+	if (location.File.ToIndex()+location.Rank.ToIndex())%2 == 1 {
+		return d.options.DarkSquare
+	}
+	return d.options.LightSquare
+}
+
+func (d *diagram) writePieces(position *game.ChessPosition) {
+	scale := d.square / glyphSize
+	for square := range position.Board.IterateSquares() {
+		if square.IsEmpty() {
+			continue
+		}
+		x, y := d.origin(square.Location)
+		d.printf(`<use xlink:href="#%s" transform="translate(%s %s) scale(%s)"/>`+"\n",
+			glyphID(square.Piece), number(x), number(y), number(scale))
+	}
+}
+
+// writeArrows draws each arrow as a line from the center of its first square to the base of a head that
+// points at the center of its second square.
+func (d *diagram) writeArrows() {
+	width := d.square / 6
+	headLength, headWidth := d.square/2.5, d.square/2.5
+
+	for _, arrow := range d.options.Arrows {
+		if arrow.From == arrow.To {
+			continue
+		}
+		color := arrow.Color
+		if color == "" {
+			color = DefaultArrowColor
+		}
+
+		fromX, fromY := d.center(arrow.From)
+		toX, toY := d.center(arrow.To)
+		length := math.Hypot(toX-fromX, toY-fromY)
+		// unit vectors along the arrow and across it
+		alongX, alongY := (toX-fromX)/length, (toY-fromY)/length
+		acrossX, acrossY := -alongY, alongX
+
+		baseX, baseY := toX-alongX*headLength, toY-alongY*headLength
+		d.printf(`<g fill="%s" stroke="%s" opacity="0.8">`+"\n", attribute(color), attribute(color))
+		d.printf(`<line x1="%s" y1="%s" x2="%s" y2="%s" stroke-width="%s" stroke-linecap="round"/>`+"\n",
+			number(fromX), number(fromY), number(baseX), number(baseY), number(width))
+		d.printf(`<polygon points="%s,%s %s,%s %s,%s" stroke="none"/>`+"\n",
+			number(toX), number(toY),
+			number(baseX+acrossX*headWidth/2), number(baseY+acrossY*headWidth/2),
+			number(baseX-acrossX*headWidth/2), number(baseY-acrossY*headWidth/2))
+		d.printf("</g>\n")
+	}
+}
+
+// number formats a coordinate with at most two decimals.
+func number(value float64) string {
+	return strconv.FormatFloat(math.Round(value*100)/100, 'f', -1, 64)
+}
+
+// attribute escapes a value given in the options for use in an attribute.
+func attribute(value string) string {
+	return attributeEscaper.Replace(value)
+}
+
+var attributeEscaper = strings.NewReplacer(`&`, "&amp;", `<`, "&lt;", `>`, "&gt;", `"`, "&quot;")
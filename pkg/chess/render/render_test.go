@@ -0,0 +1,109 @@
+package render
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/jerhon/chess/pkg/chess/fen"
+	"github.com/jerhon/chess/pkg/chess/game"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parsePosition(t *testing.T, fenString string) *game.ChessPosition {
+	t.Helper()
+	position, err := fen.ParseFen(fenString)
+	require.NoError(t, err)
+	return &position
+}
+
+// elements decodes an SVG document and counts its elements by name, failing when it is not well formed.
+func elements(t *testing.T, svg string) map[string]int {
+	t.Helper()
+	counts := map[string]int{}
+	decoder := xml.NewDecoder(strings.NewReader(svg))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return counts
+		}
+		require.NoError(t, err)
+		if start, ok := token.(xml.StartElement); ok {
+			counts[start.Name.Local]++
+		}
+	}
+}
+
+func TestSVG_StartPosition(t *testing.T) {
+	svg := SVG(game.NewStandardStartingPosition(), DefaultOptions())
+
+	counts := elements(t, svg)
+	assert.Equal(t, 1, counts["svg"])
+	assert.Equal(t, 64, counts["rect"])
+	assert.Equal(t, 32, counts["use"])
+	assert.Equal(t, 16, counts["text"])
+	assert.Equal(t, 0, counts["line"])
+
+	assert.Contains(t, svg, `width="400" height="400"`)
+	// a1 is dark and in the bottom left corner, h1 is light
+	assert.Contains(t, svg, `<rect x="0" y="350" width="50" height="50" fill="#b58863"/>`)
+	assert.Contains(t, svg, `<rect x="350" y="350" width="50" height="50" fill="#f0d9b5"/>`)
+	// the white king stands on e1
+	assert.Contains(t, svg, `<use xlink:href="#wK" transform="translate(200 350) scale(1.11)"/>`)
+	for _, id := range []string{"wP", "wN", "wB", "wR", "wQ", "wK", "bP", "bN", "bB", "bR", "bQ", "bK"} {
+		assert.Contains(t, svg, `<g id="`+id+`"`)
+	}
+}
+
+func TestSVG_BlackOrientationAndSize(t *testing.T) {
+	options := DefaultOptions()
+	options.Orientation = game.BlackPiece
+	options.Size = 240
+	options.LightSquare = "white"
+	options.DarkSquare = "gray"
+	svg := SVG(game.NewStandardStartingPosition(), options)
+
+	assert.Contains(t, svg, `width="240" height="240"`)
+	// from black's side h1 is in the top left corner and the black king on e8 on the bottom row
+	assert.Contains(t, svg, `<rect x="0" y="0" width="30" height="30" fill="white"/>`)
+	assert.Contains(t, svg, `<use xlink:href="#bK" transform="translate(90 210) scale(0.67)"/>`)
+	assert.Regexp(t, `<text [^>]*>h</text>`, svg)
+}
+
+func TestSVG_ZeroOptions(t *testing.T) {
+	svg := SVG(parsePosition(t, "4k3/8/8/8/8/8/8/4K3 w - - 0 1"), Options{})
+
+	counts := elements(t, svg)
+	assert.Equal(t, 64, counts["rect"])
+	assert.Equal(t, 2, counts["use"])
+	assert.Zero(t, counts["text"])
+	assert.Contains(t, svg, `width="400" height="400"`)
+}
+
+func TestSVG_HighlightsAndArrows(t *testing.T) {
+	position := parsePosition(t, "rnb1kbnr/pppp1ppp/8/4p3/6Pq/5P2/PPPPP2P/RNBQKBNR w KQkq - 1 3")
+	options := DefaultOptions()
+	options.LastMove = []game.ChessLocation{{File: game.FileD, Rank: game.Rank8}, {File: game.FileH, Rank: game.Rank4}}
+	options.Highlights = []Highlight{{Square: game.ChessLocation{File: game.FileA, Rank: game.Rank1}, Color: `x"y`}}
+	options.Arrows = []Arrow{
+		{From: game.ChessLocation{File: game.FileH, Rank: game.Rank4}, To: game.ChessLocation{File: game.FileE, Rank: game.Rank1}},
+		{From: game.ChessLocation{File: game.FileA, Rank: game.Rank1}, To: game.ChessLocation{File: game.FileA, Rank: game.Rank1}},
+	}
+	svg := SVG(position, options)
+
+	counts := elements(t, svg)
+	// the squares, two of the last move, the king in check and one highlight
+	assert.Equal(t, 64+4, counts["rect"])
+	assert.Equal(t, 1, counts["line"])
+	assert.Equal(t, 1, counts["polygon"])
+
+	assert.Contains(t, svg, `<rect x="200" y="350" width="50" height="50" fill="#e8483c" fill-opacity="0.8"/>`)
+	assert.Contains(t, svg, `<rect x="150" y="0" width="50" height="50" fill="#cdd26a" fill-opacity="0.8"/>`)
+	assert.Contains(t, svg, `fill="x&quot;y"`)
+	assert.Contains(t, svg, `<line x1="375" y1="225" `)
+
+	options.Check = false
+	assert.NotContains(t, SVG(position, options), "#e8483c")
+}
@@ -0,0 +1,77 @@
+package chess
+
+import "github.com/jerhon/chess/pkg/chess/game"
+
+// DrawRules decides when repetition and the fifty-move rule end a game by themselves and when they only
+// let the player to move claim a draw. A threshold of 0 never applies.
+type DrawRules struct {
+	// Name describes the rules, e.g. "FIDE"
+	Name string
+
+	// ClaimRepetitions and AutomaticRepetitions are how often the current position must have occurred
+	ClaimRepetitions     int
+	AutomaticRepetitions int
+	// ClaimHalfmoves and AutomaticHalfmoves are the halfmove clock, plies without a capture or pawn move
+	ClaimHalfmoves     int
+	AutomaticHalfmoves int
+}
+
+var (
+	// FIDERules are the FIDE laws over the board: threefold repetition and fifty moves may be claimed, the game
+	// is drawn at the fifth occurrence of a position and after seventy-five moves.
+	FIDERules = DrawRules{Name: "FIDE", ClaimRepetitions: 3, AutomaticRepetitions: 5, ClaimHalfmoves: 100, AutomaticHalfmoves: 150}
+
+	// OnlineRules draw the game as soon as a position occurs three times or fifty moves are played, as most
+	// online servers do. A new game uses these rules.
+	OnlineRules = DrawRules{Name: "Online", ClaimRepetitions: 3, AutomaticRepetitions: 3, ClaimHalfmoves: 100, AutomaticHalfmoves: 100}
+
+	// USCFRules are the US Chess rules, under which repetition and fifty moves only ever allow a claim.
+	USCFRules = DrawRules{Name: "USCF", ClaimRepetitions: 3, ClaimHalfmoves: 100}
+)
+
+// reached returns true when a count has reached a threshold that applies.
+func reached(count int, threshold int) bool {
+	return threshold > 0 && count >= threshold
+}
+
+// SetDrawRules sets the rules for draws by repetition and the fifty-move rule.
+func (g *ChessGame) SetDrawRules(rules DrawRules) {
+	g.drawRules = rules
+}
+
+// DrawRules returns the rules for draws by repetition and the fifty-move rule.
+func (g *ChessGame) DrawRules() DrawRules {
+	return g.drawRules
+}
+
+// CanClaimDraw returns the reason the player to move may claim a draw, repetition or the fifty-move rule,
+// and false when no draw can be claimed or the game is over.
+func (g *ChessGame) CanClaimDraw() (game.ResultReason, bool) {
+	if g.GetResult() != game.InProgress {
+		return game.ReasonNone, false
+	}
+	switch {
+	case reached(g.positionHistory[g.position.Hash()], g.drawRules.ClaimRepetitions):
+		return game.ReasonRepetition, true
+	case reached(g.position.HalfmoveClock, g.drawRules.ClaimHalfmoves):
+		return game.ReasonFiftyMove, true
+	default:
+		return game.ReasonNone, false
+	}
+}
+
+// positionResult returns the result the position and the draw rules decide: checkmate, stalemate and
+// insufficient material from the position, then the automatic draws of the rules.
+func (g *ChessGame) positionResult() game.GameResult {
+	g.calculate()
+	switch {
+	case g.moves.Result != game.InProgress:
+		return g.moves.Result
+	case reached(g.position.HalfmoveClock, g.drawRules.AutomaticHalfmoves):
+		return game.DrawFiftyMove
+	case reached(g.positionHistory[g.position.Hash()], g.drawRules.AutomaticRepetitions):
+		return game.DrawRepetition
+	default:
+		return game.InProgress
+	}
+}
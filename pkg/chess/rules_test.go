@@ -0,0 +1,103 @@
+package chess
+
+import (
+	"testing"
+
+	"github.com/jerhon/chess/pkg/chess/game"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// knightShuffle returns the starting position once more after it is played.
+var knightShuffle = []string{"Nf3", "Nf6", "Ng1", "Ng8"}
+
+func TestDrawRules_OnlineByDefault(t *testing.T) {
+	g := NewGame()
+	assert.Equal(t, OnlineRules, g.DrawRules())
+}
+
+func TestDrawRules_FIDERepetitionIsClaimedThenAutomatic(t *testing.T) {
+	g := NewGame()
+	g.SetDrawRules(FIDERules)
+
+	playMoves(t, g, knightShuffle...)
+	playMoves(t, g, knightShuffle...)
+	assert.Equal(t, game.InProgress, g.GetResult())
+	reason, ok := g.CanClaimDraw()
+	assert.True(t, ok)
+	assert.Equal(t, game.ReasonRepetition, reason)
+
+	// the fourth occurrence is still only claimable, the fifth ends the game
+	playMoves(t, g, knightShuffle...)
+	assert.Equal(t, game.InProgress, g.GetResult())
+	playMoves(t, g, knightShuffle...)
+	assert.Equal(t, game.DrawRepetition, g.GetResult())
+	assert.Equal(t, game.ReasonRepetition, g.GetResultReason())
+
+	_, ok = g.CanClaimDraw()
+	assert.False(t, ok)
+}
+
+func TestDrawRules_FIDEFiftyMovesAreClaimedThenAutomatic(t *testing.T) {
+	g := newGameFromFen(t, "4k3/8/8/8/8/8/4P3/R3K3 w - - 99 80")
+	g.SetDrawRules(FIDERules)
+
+	playMoves(t, g, "Ra2")
+	assert.Equal(t, game.InProgress, g.GetResult())
+	reason, ok := g.CanClaimDraw()
+	assert.True(t, ok)
+	assert.Equal(t, game.ReasonFiftyMove, reason)
+
+	g = newGameFromFen(t, "4k3/8/8/8/8/8/4P3/R3K3 w - - 149 105")
+	g.SetDrawRules(FIDERules)
+	playMoves(t, g, "Ra2")
+	assert.Equal(t, game.DrawFiftyMove, g.GetResult())
+	assert.Equal(t, game.ReasonFiftyMove, g.GetResultReason())
+}
+
+func TestDrawRules_OnlineFiftyMovesAreAutomatic(t *testing.T) {
+	g := newGameFromFen(t, "4k3/8/8/8/8/8/4P3/R3K3 w - - 99 80")
+	playMoves(t, g, "Ra2")
+	assert.Equal(t, game.DrawFiftyMove, g.GetResult())
+}
+
+func TestDrawRules_USCFNeverEndsTheGame(t *testing.T) {
+	g := newGameFromFen(t, "4k3/8/8/8/8/8/4P3/R3K3 w - - 199 130")
+	g.SetDrawRules(USCFRules)
+	for range 5 {
+		playMoves(t, g, "Ra2", "Kd8", "Ra1", "Ke8")
+	}
+	assert.Equal(t, game.InProgress, g.GetResult())
+
+	reason, ok := g.CanClaimDraw()
+	assert.True(t, ok)
+	assert.Equal(t, game.ReasonRepetition, reason)
+}
+
+func TestClaimDraw_Repetition(t *testing.T) {
+	g := NewGame()
+	g.SetDrawRules(FIDERules)
+	playMoves(t, g, knightShuffle...)
+	assert.Error(t, g.ClaimDraw())
+
+	playMoves(t, g, knightShuffle...)
+	require.NoError(t, g.ClaimDraw())
+	assert.Equal(t, game.DrawRepetition, g.GetResult())
+	assert.Equal(t, game.ReasonRepetition, g.GetResultReason())
+
+	// taking the move back withdraws the claim
+	require.True(t, g.Undo())
+	assert.Equal(t, game.InProgress, g.GetResult())
+	_, ok := g.CanClaimDraw()
+	assert.False(t, ok)
+}
+
+func TestClaimDraw_FiftyMoves(t *testing.T) {
+	g := newGameFromFen(t, "4k3/8/8/8/8/8/4P3/R3K3 w - - 99 80")
+	g.SetDrawRules(USCFRules)
+	playMoves(t, g, "Ra2")
+
+	require.NoError(t, g.ClaimDraw())
+	assert.Equal(t, game.DrawFiftyMove, g.GetResult())
+	assert.Equal(t, game.ReasonFiftyMove, g.GetResultReason())
+}
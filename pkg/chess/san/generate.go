@@ -0,0 +1,74 @@
+package san
+
+import (
+	"github.com/jerhon/chess/pkg/chess/game"
+)
+
+// FromMove describes a legal move in the given position in standard algebraic notation, e.g. Nbd7,
+// exd6, e8=Q+, O-O or Qh7#. The file and/or rank of the moving piece is only added when another piece of
+// the same type could also move to the destination. promotionPiece is only used when the move is a promotion.
+func FromMove(position *game.ChessPosition, move game.ChessMove, promotionPiece game.PieceType) string {
+	movement := game.NewChessMovement(position)
+	movement.Calculate()
+
+	next := game.NewChessMovement(position.ApplyMove(move, promotionPiece))
+	next.Calculate()
+	checkmate := next.IsCheckmate
+	check := !checkmate && next.Check[next.Position.PlayerToMove]
+
+	if move.IsCastle {
+		castle := SanCastle{
+			CastleKingSide:  move.CastlesKingSide(),
+			CastleQueenSide: !move.CastlesKingSide(),
+		}
+		suffix := ""
+		if checkmate {
+			suffix = "#"
+		} else if check {
+			suffix = "+"
+		}
+		return castle.String() + suffix
+	}
+
+	piece := move.From.Piece.Piece
+	sanMove := SanMove{
+		Piece:     piece,
+		ToFile:    move.To.File,
+		ToRank:    move.To.Rank,
+		Capture:   position.Board.HasPiece(move.To) || (piece == game.Pawn && move.To.File != move.From.Location.File),
+		Check:     check,
+		Checkmate: checkmate,
+	}
+
+	if move.IsPromotion {
+		sanMove.PromotionPiece = promotionPiece
+	}
+
+	if piece == game.Pawn {
+		// pawn captures are always written with the file the pawn left
+		if sanMove.Capture {
+			sanMove.FromFile = move.From.Location.File
+		}
+		return sanMove.String()
+	}
+
+	// disambiguate against other pieces of the same type that can reach the same square,
+	// preferring the file, then the rank, then both
+	sameFile, sameRank, ambiguous := false, false, false
+	for _, other := range movement.Moves {
+		if other.IsCastle || other.To != move.To || other.From.Piece != move.From.Piece || other.From.Location == move.From.Location {
+			continue
+		}
+		ambiguous = true
+		sameFile = sameFile || other.From.Location.File == move.From.Location.File
+		sameRank = sameRank || other.From.Location.Rank == move.From.Location.Rank
+	}
+	if ambiguous && (!sameFile || sameRank) {
+		sanMove.FromFile = move.From.Location.File
+	}
+	if ambiguous && sameFile {
+		sanMove.FromRank = move.From.Location.Rank
+	}
+
+	return sanMove.String()
+}
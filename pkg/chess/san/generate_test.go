@@ -0,0 +1,110 @@
+package san
+
+import (
+	"testing"
+
+	"github.com/jerhon/chess/pkg/chess/fen"
+	"github.com/jerhon/chess/pkg/chess/game"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func findMove(t *testing.T, position *game.ChessPosition, uci string) game.ChessMove {
+	t.Helper()
+	movement := game.NewChessMovement(position)
+	movement.Calculate()
+	for _, move := range movement.Moves {
+		if move.From.Location.String()+move.To.String() == uci {
+			return move
+		}
+	}
+	require.Failf(t, "move not found", "no legal move %s", uci)
+	return game.ChessMove{}
+}
+
+func TestFromMove(t *testing.T) {
+	tests := []struct {
+		name      string
+		fen       string
+		uci       string
+		promotion game.PieceType
+		expected  string
+	}{
+		{"pawn push", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", "e2e4", game.NoPiece, "e4"},
+		{"knight move", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", "b1c3", game.NoPiece, "Nc3"},
+		{"pawn capture", "4k3/8/8/3p4/4P3/8/8/4K3 w - - 0 1", "e4d5", game.NoPiece, "exd5"},
+		{"en passant", "4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 1", "e5d6", game.NoPiece, "exd6"},
+		{"piece capture", "4k3/8/8/3p4/8/8/8/3RK3 w - - 0 1", "d1d5", game.NoPiece, "Rxd5"},
+		{"file disambiguation", "4k3/8/8/8/8/8/8/R4RK1 w - - 0 1", "f1d1", game.NoPiece, "Rfd1"},
+		{"rank disambiguation", "4k3/8/8/R7/8/8/8/R3K3 w - - 0 1", "a1a3", game.NoPiece, "R1a3"},
+		{"file and rank disambiguation", "4k3/8/8/8/8/Q1Q5/8/Q3K3 w - - 0 1", "a3b2", game.NoPiece, "Qa3b2"},
+		{"pinned piece does not need disambiguation", "4k3/8/8/8/1b6/8/3N4/4K1N1 w - - 0 1", "g1f3", game.NoPiece, "Nf3"},
+		{"promotion", "8/1P6/8/8/8/8/8/k3K3 w - - 0 1", "b7b8", game.Queen, "b8=Q"},
+		{"capture promotion with check", "r3k3/1P6/8/8/8/8/8/4K3 w - - 0 1", "b7a8", game.Rook, "bxa8=R+"},
+		{"check", "4k3/8/8/8/8/8/8/R3K3 w - - 0 1", "a1a8", game.NoPiece, "Ra8+"},
+		{"checkmate", "6k1/5ppp/8/8/8/8/8/R3K3 w - - 0 1", "a1a8", game.NoPiece, "Ra8#"},
+		{"castle king side", "4k3/8/8/8/8/8/8/4K2R w K - 0 1", "e1g1", game.NoPiece, "O-O"},
+		{"castle queen side with check", "3k4/8/8/8/8/8/8/R3K3 w Q - 0 1", "e1c1", game.NoPiece, "O-O-O+"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			position, err := fen.ParseFen(test.fen)
+			require.NoError(t, err)
+			move := findMove(t, &position, test.uci)
+			assert.Equal(t, test.expected, FromMove(&position, move, test.promotion))
+		})
+	}
+}
+
+func TestFromMove_RoundTripsThroughParseSan(t *testing.T) {
+	positions := []string{
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+		"r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 b kq - 0 1",
+		"rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8",
+		"4k3/8/8/8/8/QQQ5/QQQ5/QQQ1K3 w - - 0 1",
+	}
+
+	for _, fenString := range positions {
+		position, err := fen.ParseFen(fenString)
+		require.NoError(t, err)
+		movement := game.NewChessMovement(&position)
+		movement.Calculate()
+
+		for _, move := range movement.Moves {
+			promotions := []game.PieceType{game.NoPiece}
+			if move.IsPromotion {
+				promotions = game.PromotionPieces
+			}
+			for _, promotion := range promotions {
+				sanText := FromMove(&position, move, promotion)
+				sanMove, sanCastle, err := ParseSan(sanText)
+				require.NoError(t, err, sanText)
+
+				if move.IsCastle {
+					require.NotNil(t, sanCastle, sanText)
+					assert.Equal(t, move.To.File == game.FileG, sanCastle.CastleKingSide, sanText)
+					continue
+				}
+
+				// the parsed SAN must identify exactly this move among the legal moves
+				require.NotNil(t, sanMove, sanText)
+				matches := 0
+				for _, other := range movement.Moves {
+					if other.IsCastle || other.From.Piece.Piece != sanMove.Piece || other.To.File != sanMove.ToFile || other.To.Rank != sanMove.ToRank {
+						continue
+					}
+					if (sanMove.FromFile != game.NoFile && other.From.Location.File != sanMove.FromFile) ||
+						(sanMove.FromRank != game.NoRank && other.From.Location.Rank != sanMove.FromRank) {
+						continue
+					}
+					matches++
+					assert.Equal(t, move.From.Location, other.From.Location, sanText)
+				}
+				assert.Equal(t, 1, matches, sanText)
+				assert.Equal(t, promotion, sanMove.PromotionPiece, sanText)
+			}
+		}
+	}
+}
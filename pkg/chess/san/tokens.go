@@ -30,6 +30,16 @@ type SanTokenPosition struct {
 	Offset     int
 }
 
+// SanError is an error reading SAN, found at a position of the text.
+type SanError struct {
+	Position SanTokenPosition
+	Message  string
+}
+
+func (e *SanError) Error() string {
+	return fmt.Sprintf("%s at offset %d", e.Message, e.Position.Offset)
+}
+
 type SanTokenReader struct {
 	reader     *strings.Reader
 	offset     int
@@ -104,8 +114,6 @@ func (tr *SanTokenReader) expectAndAdvanceRune(expected rune) bool {
 }
 
 func (tr *SanTokenReader) ReadTokens() ([]SanToken, error) {
-
-	parseErrors := []string{}
 	tokens := []SanToken{}
 	var r rune
 	_, err := tr.peekRune()
@@ -115,9 +123,6 @@ func (tr *SanTokenReader) ReadTokens() ([]SanToken, error) {
 			r, err = tr.readRune()
 		}
 		if err != nil {
-			if len(parseErrors) > 0 {
-				return tokens, fmt.Errorf("one or more parse errors occurred: %s", parseErrors)
-			}
 			return tokens, nil
 		}
 		tokenType := None
@@ -128,12 +133,10 @@ func (tr *SanTokenReader) ReadTokens() ([]SanToken, error) {
 				_, err = tr.readRune()
 				if tr.expectAndAdvanceRune('.') {
 					if !tr.expectAndAdvanceRune('p') {
-						parseErrors = append(parseErrors, "Expected 'p' after 'e.'")
-						continue
+						return tokens, &SanError{Position: tr.getPosition(), Message: "expected 'p' after 'e.'"}
 					}
 					if !tr.expectAndAdvanceRune('.') {
-						parseErrors = append(parseErrors, "Expected '.' after 'e.p'")
-						continue
+						return tokens, &SanError{Position: tr.getPosition(), Message: "expected '.' after 'e.p'"}
 					}
 
 					tokenType = EnPassantToken
@@ -178,7 +181,7 @@ func (tr *SanTokenReader) ReadTokens() ([]SanToken, error) {
 			continue
 		}
 
-		return tokens, fmt.Errorf("unexpected character: %s", string(r))
+		return tokens, &SanError{Position: startPosition, Message: fmt.Sprintf("unexpected character %q", r)}
 	}
 
 	return tokens, nil
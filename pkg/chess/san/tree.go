@@ -1,13 +1,18 @@
 package san
 
 import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
 	"github.com/jerhon/chess/pkg/chess/game"
 )
 
 // ParseSan parses a san string returning either a san move or a castling move
 func ParseSan(san string) (*SanMove, *SanCastle, error) {
-	// Special case for castling - check before tokenization since "0-0" notation uses '-' which is not a valid token
-	switch san {
+	// Special case for castling - check before tokenization since "0-0" notation uses '-' which is not a valid token.
+	// A castle may give check, so the check and checkmate suffixes are ignored.
+	switch strings.TrimRight(san, "+#") {
 	case "O-O", "0-0":
 		return nil, &SanCastle{
 			CastleKingSide: true,
@@ -54,6 +59,7 @@ func ParseSan(san string) (*SanMove, *SanCastle, error) {
 		capture = true
 		idx++
 	}
+	afterCapture := idx
 
 	if idx < len(tokens) && tokens[idx].Type == FileToken {
 		fromFile = toFile
@@ -67,7 +73,16 @@ func ParseSan(san string) (*SanMove, *SanCastle, error) {
 		idx++
 	}
 
+	if toFile == game.NoFile || toRank == game.NoRank || (capture && idx == afterCapture) {
+		return nil, nil, &SanError{Position: tokenPosition(san, tokens, idx), Message: "expected a destination square"}
+	}
+
 	for ; idx < len(tokens); idx++ {
+		switch tokens[idx].Type {
+		case FileToken, RankToken, CaptureToken:
+			return nil, nil, &SanError{Position: tokens[idx].Position, Message: fmt.Sprintf("unexpected %q after the destination square", tokens[idx].Value)}
+		}
+
 		if tokens[idx].Type == EnPassantToken {
 			enPassant = true
 		}
@@ -98,3 +113,11 @@ func ParseSan(san string) (*SanMove, *SanCastle, error) {
 		PromotionPiece: promotionPiece,
 	}, nil, nil
 }
+
+// tokenPosition returns the position of a token, or the end of the text when idx is past the last token.
+func tokenPosition(san string, tokens []SanToken, idx int) SanTokenPosition {
+	if idx < len(tokens) {
+		return tokens[idx].Position
+	}
+	return SanTokenPosition{RuneOffset: utf8.RuneCountInString(san), Offset: len(san)}
+}
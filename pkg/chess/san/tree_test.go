@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestParseSan(t *testing.T) {
@@ -112,6 +113,18 @@ func TestParseSan(t *testing.T) {
 				CastleQueenSide: true,
 			},
 		},
+		{
+			input: "O-O+",
+			sanCastlingMove: &SanCastle{
+				CastleKingSide: true,
+			},
+		},
+		{
+			input: "O-O-O#",
+			sanCastlingMove: &SanCastle{
+				CastleQueenSide: true,
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -123,3 +136,27 @@ func TestParseSan(t *testing.T) {
 		})
 	}
 }
+
+func TestParseSan_Malformed(t *testing.T) {
+	tests := []struct {
+		input  string
+		offset int
+	}{
+		{"", 0},
+		{"N", 1},
+		{"Nx", 2},
+		{"e", 1},
+		{"e4?", 2},
+		{"e4e5e6", 4},
+		{"exd6 e.x.", 7},
+		{"Nf3x", 4},
+	}
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			_, _, err := ParseSan(test.input)
+			var sanError *SanError
+			require.ErrorAs(t, err, &sanError)
+			assert.Equal(t, test.offset, sanError.Position.Offset)
+		})
+	}
+}
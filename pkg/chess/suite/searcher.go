@@ -0,0 +1,72 @@
+package suite
+
+import (
+	"context"
+	"time"
+
+	"github.com/jerhon/chess/pkg/chess/fen"
+	"github.com/jerhon/chess/pkg/chess/game"
+	"github.com/jerhon/chess/pkg/chess_uci"
+)
+
+// Searcher finds the move an engine plays in a position.
+type Searcher interface {
+	// BestMove searches the position for moveTime, or until ctx is cancelled, and returns the best move in
+	// UCI notation. Each position is searched as the start of a new game.
+	BestMove(ctx context.Context, position *game.ChessPosition, moveTime time.Duration) (string, error)
+}
+
+// engineSearcher searches with an engine in this process, such as the built in engine.
+type engineSearcher struct {
+	engine chess_uci.Engine
+}
+
+// NewEngineSearcher returns a Searcher that runs the searches of an engine implementing chess_uci.Engine.
+func NewEngineSearcher(engine chess_uci.Engine) Searcher {
+	return engineSearcher{engine: engine}
+}
+
+func (s engineSearcher) BestMove(ctx context.Context, position *game.ChessPosition, moveTime time.Duration) (string, error) {
+	s.engine.NewGame()
+	milliseconds := int(moveTime.Milliseconds())
+	best := s.engine.Search(ctx, newGame(position), chess_uci.CmdGo{MoveTime: &milliseconds}, nil)
+	return best.Move, nil
+}
+
+// clientSearcher searches with an external engine over UCI.
+type clientSearcher struct {
+	client *chess_uci.Client
+}
+
+// NewClientSearcher returns a Searcher that sends each position to an external engine through a UCI client.
+func NewClientSearcher(client *chess_uci.Client) Searcher {
+	return clientSearcher{client: client}
+}
+
+func (s clientSearcher) BestMove(ctx context.Context, position *game.ChessPosition, moveTime time.Duration) (string, error) {
+	if err := s.client.NewGame(); err != nil {
+		return "", err
+	}
+	if err := s.client.SetPosition(chess_uci.CmdPosition{FEN: fen.ToFenString(position)}); err != nil {
+		return "", err
+	}
+
+	milliseconds := int(moveTime.Milliseconds())
+	search, err := s.client.Go(chess_uci.CmdGo{MoveTime: &milliseconds})
+	if err != nil {
+		return "", err
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.client.Stop()
+		case <-done:
+		}
+	}()
+
+	best, err := search.Wait()
+	return best.Move, err
+}
@@ -0,0 +1,184 @@
+// Package suite runs EPD test suites such as WAC or STS against an engine, giving each position a fixed
+// time and checking the move played against the bm, am and STS c0 operations of the record.
+package suite
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jerhon/chess/pkg/chess"
+	"github.com/jerhon/chess/pkg/chess/fen"
+	"github.com/jerhon/chess/pkg/chess/game"
+)
+
+// DefaultMoveTime is the time given to each position when Options.MoveTime is zero.
+const DefaultMoveTime = time.Second
+
+// Options configures a suite run.
+type Options struct {
+	// MoveTime is the time the engine searches each position
+	MoveTime time.Duration
+	// Progress, when set, is called with the result of each position as soon as it is known
+	Progress func(Result)
+}
+
+// Result is the outcome of one position of a suite.
+type Result struct {
+	// Record is the EPD record of the position
+	Record fen.Epd
+	// Move is the move played in UCI and San in standard algebraic notation, empty if the search failed
+	Move string
+	San  string
+	// Solved is true when the move is one of the best moves and none of the moves to avoid
+	Solved bool
+	// Points and MaxPoints score the move, from the c0 move scores of STS records or one point if solved
+	Points    int
+	MaxPoints int
+	// Err is the error of the search, the position counts as failed
+	Err error
+}
+
+// Report totals the results of a suite.
+type Report struct {
+	Results []Result
+	Solved  int
+	Failed  int
+	// Score is the sum of the points of each position out of MaxScore
+	Score    int
+	MaxScore int
+}
+
+// Run searches each position of the suite in turn and scores the moves played. It stops early, returning
+// the report so far with the error of the context, when ctx is cancelled.
+func Run(ctx context.Context, searcher Searcher, records []fen.Epd, options Options) (Report, error) {
+	moveTime := options.MoveTime
+	if moveTime <= 0 {
+		moveTime = DefaultMoveTime
+	}
+
+	report := Report{Results: []Result{}}
+	for _, record := range records {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		position := record.Position
+		move, err := searcher.BestMove(ctx, &position, moveTime)
+		result := Score(record, move)
+		if err != nil {
+			result.Err = err
+		}
+
+		report.Results = append(report.Results, result)
+		if result.Solved {
+			report.Solved++
+		} else {
+			report.Failed++
+		}
+		report.Score += result.Points
+		report.MaxScore += result.MaxPoints
+		if options.Progress != nil {
+			options.Progress(result)
+		}
+	}
+	return report, nil
+}
+
+// Score checks a move given in UCI notation against an EPD record. A record without bm or am operations
+// can't be solved, and a record whose c0 comment holds STS move scores such as "Nf3=10, e4=5" scores the
+// move from them instead of one point for a solution.
+func Score(record fen.Epd, uciMove string) Result {
+	result := Result{Record: record, Move: uciMove, MaxPoints: 1}
+
+	played, err := playUci(&record.Position, uciMove)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.San = played.San
+
+	matches := func(sanMoves []string) bool {
+		for _, sanMove := range sanMoves {
+			if expected, err := playSan(&record.Position, sanMove); err == nil && sameMove(expected, played) {
+				return true
+			}
+		}
+		return false
+	}
+	result.Solved = (len(record.BestMoves) > 0 || len(record.AvoidMoves) > 0) &&
+		(len(record.BestMoves) == 0 || matches(record.BestMoves)) && !matches(record.AvoidMoves)
+	if result.Solved {
+		result.Points = 1
+	}
+
+	if scores := moveScores(record.Comments[0]); len(scores) > 0 {
+		result.Points, result.MaxPoints = 0, 0
+		for sanMove, points := range scores {
+			result.MaxPoints = max(result.MaxPoints, points)
+			if expected, err := playSan(&record.Position, sanMove); err == nil && sameMove(expected, played) {
+				result.Points = points
+			}
+		}
+	}
+	return result
+}
+
+// moveScores reads the move scores of an STS c0 comment, e.g. "f5=10, Be5+=2, Bf2=3". It returns nil when
+// the comment is not a list of move scores.
+func moveScores(comment string) map[string]int {
+	if !strings.Contains(comment, "=") {
+		return nil
+	}
+	scores := map[string]int{}
+	for _, part := range strings.Split(comment, ",") {
+		// the score follows the last = as promotions such as e8=Q hold one too
+		index := strings.LastIndex(part, "=")
+		if index < 0 {
+			return nil
+		}
+		points, err := strconv.Atoi(strings.TrimSpace(part[index+1:]))
+		if err != nil {
+			return nil
+		}
+		scores[strings.TrimSpace(part[:index])] = points
+	}
+	return scores
+}
+
+// playUci plays a UCI move in a position and returns its record.
+func playUci(position *game.ChessPosition, uciMove string) (chess.MoveRecord, error) {
+	if uciMove == "" {
+		return chess.MoveRecord{}, fmt.Errorf("the engine returned no move")
+	}
+	chessGame := newGame(position)
+	if _, err := chessGame.TryUciMove(uciMove); err != nil {
+		return chess.MoveRecord{}, err
+	}
+	return chessGame.History()[0], nil
+}
+
+// playSan plays a move in standard algebraic notation in a position and returns its record. Check and
+// annotation suffixes are ignored as EPD suites don't always write them.
+func playSan(position *game.ChessPosition, sanMove string) (chess.MoveRecord, error) {
+	sanMove = strings.TrimRight(sanMove, "+#!?")
+	chessGame := newGame(position)
+	if _, err := chessGame.TrySanMove(sanMove); err != nil {
+		return chess.MoveRecord{}, err
+	}
+	return chessGame.History()[0], nil
+}
+
+// newGame returns a game from a position of a suite. A record may have a halfmove clock past the fifty-move
+// rule or come from a repeated position, so those draws never end the game before its move is played.
+func newGame(position *game.ChessPosition) *chess.ChessGame {
+	chessGame := chess.NewGameFromPosition(position)
+	chessGame.SetDrawRules(chess.USCFRules)
+	return chessGame
+}
+
+func sameMove(a, b chess.MoveRecord) bool {
+	return a.From == b.From && a.To == b.To && a.Promotion == b.Promotion
+}
@@ -0,0 +1,128 @@
+package suite
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/jerhon/chess/pkg/chess/engine"
+	"github.com/jerhon/chess/pkg/chess/fen"
+	"github.com/jerhon/chess/pkg/chess_uci"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parseEpd(t *testing.T, epdString string) fen.Epd {
+	t.Helper()
+	epd, err := fen.ParseEpd(epdString)
+	require.NoError(t, err)
+	return epd
+}
+
+// mateSuite holds mates in one the engine finds at once, and one position whose best move it can't play.
+func mateSuite(t *testing.T) []fen.Epd {
+	return []fen.Epd{
+		parseEpd(t, `6k1/5ppp/8/8/8/8/8/R5K1 w - - bm Ra8#; id "back rank";`),
+		parseEpd(t, `k7/2K5/8/8/8/8/8/7R w - - bm Ra1#; am Rh8+; id "corner";`),
+		parseEpd(t, `6k1/5ppp/8/8/8/8/8/R5K1 w - - bm Kf1; id "wrong";`),
+	}
+}
+
+func TestScore(t *testing.T) {
+	backRank := parseEpd(t, `6k1/5ppp/8/8/8/8/8/R5K1 w - - bm Ra8+; am Kf1;`)
+	sts := parseEpd(t, `6k1/5ppp/8/8/8/8/8/R5K1 w - - bm Ra8; c0 "Ra8=10, Kf1=4, Ra7=1";`)
+	avoidOnly := parseEpd(t, `6k1/5ppp/8/8/8/8/8/R5K1 w - - am Kf1;`)
+	pastFiftyMoves := parseEpd(t, `6k1/5ppp/8/8/8/8/8/R5K1 w - - bm Ra8#; hmvc 120;`)
+
+	tests := []struct {
+		name      string
+		record    fen.Epd
+		move      string
+		san       string
+		solved    bool
+		points    int
+		maxPoints int
+	}{
+		{"best move, suffix ignored", backRank, "a1a8", "Ra8#", true, 1, 1},
+		{"avoided move", backRank, "g1f1", "Kf1", false, 0, 1},
+		{"other move", backRank, "a1a2", "Ra2", false, 0, 1},
+		{"sts best move", sts, "a1a8", "Ra8#", true, 10, 10},
+		{"sts partial score", sts, "g1f1", "Kf1", false, 4, 10},
+		{"sts unscored move", sts, "a1a2", "Ra2", false, 0, 10},
+		{"illegal move", sts, "h2h3", "", false, 0, 1},
+		{"avoid only", avoidOnly, "a1a8", "Ra8#", true, 1, 1},
+		{"past the fifty-move rule", pastFiftyMoves, "a1a8", "Ra8#", true, 1, 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := Score(test.record, test.move)
+			if test.san == "" {
+				assert.Error(t, result.Err)
+				return
+			}
+			require.NoError(t, result.Err)
+			assert.Equal(t, test.san, result.San)
+			assert.Equal(t, test.solved, result.Solved)
+			assert.Equal(t, test.points, result.Points)
+			assert.Equal(t, test.maxPoints, result.MaxPoints)
+		})
+	}
+}
+
+func TestRun_Engine(t *testing.T) {
+	progress := []string{}
+	report, err := Run(context.Background(), NewEngineSearcher(engine.NewEngine()), mateSuite(t), Options{
+		MoveTime: 100 * time.Millisecond,
+		Progress: func(result Result) { progress = append(progress, result.Record.ID) },
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"back rank", "corner", "wrong"}, progress)
+	require.Len(t, report.Results, 3)
+	assert.Equal(t, "a1a8", report.Results[0].Move)
+	assert.Equal(t, "Ra1#", report.Results[1].San)
+	assert.False(t, report.Results[2].Solved)
+	assert.Equal(t, 2, report.Solved)
+	assert.Equal(t, 1, report.Failed)
+	assert.Equal(t, 2, report.Score)
+	assert.Equal(t, 3, report.MaxScore)
+}
+
+func TestRun_EnginePastTheFiftyMoveRule(t *testing.T) {
+	records := []fen.Epd{parseEpd(t, `6k1/5ppp/8/8/8/8/8/R5K1 w - - bm Ra8#; hmvc 100; id "late";`)}
+	report, err := Run(context.Background(), NewEngineSearcher(engine.NewEngine()), records, Options{MoveTime: 100 * time.Millisecond})
+	require.NoError(t, err)
+
+	require.Len(t, report.Results, 1)
+	require.NoError(t, report.Results[0].Err)
+	assert.Equal(t, "Ra8#", report.Results[0].San)
+	assert.Equal(t, 1, report.Solved)
+}
+
+func TestRun_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	report, err := Run(ctx, NewEngineSearcher(engine.NewEngine()), mateSuite(t), Options{})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, report.Results)
+}
+
+func TestRun_Client(t *testing.T) {
+	commandReader, commandWriter := io.Pipe()
+	responseReader, responseWriter := io.Pipe()
+	go func() {
+		chess_uci.NewServer(engine.NewEngine(), commandReader, responseWriter).Run()
+		responseWriter.Close()
+	}()
+	client, err := chess_uci.NewClient(responseReader, commandWriter)
+	require.NoError(t, err)
+	defer commandWriter.Close()
+	defer client.Close()
+
+	report, err := Run(context.Background(), NewClientSearcher(client), mateSuite(t), Options{MoveTime: 100 * time.Millisecond})
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Solved)
+	assert.Equal(t, 1, report.Failed)
+}
@@ -78,6 +78,18 @@ type CmdPosition struct {
 
 func (CmdPosition) Name() string { return "position" }
 
+// String formats the command as it is sent to an engine.
+func (c CmdPosition) String() string {
+	text := "position startpos"
+	if !c.StartPos {
+		text = "position fen " + c.FEN
+	}
+	if len(c.Moves) > 0 {
+		text += " moves " + strings.Join(c.Moves, " ")
+	}
+	return text
+}
+
 // go ... parameters
 
 type CmdGo struct {
@@ -97,6 +109,33 @@ type CmdGo struct {
 
 func (CmdGo) Name() string { return "go" }
 
+// String formats the command as it is sent to an engine.
+func (c CmdGo) String() string {
+	parts := []string{"go"}
+	if len(c.SearchMoves) > 0 {
+		parts = append(parts, "searchmoves")
+		parts = append(parts, c.SearchMoves...)
+	}
+	if c.Ponder {
+		parts = append(parts, "ponder")
+	}
+	for _, field := range []struct {
+		name  string
+		value *int
+	}{
+		{"wtime", c.WTime}, {"btime", c.BTime}, {"winc", c.WInc}, {"binc", c.BInc}, {"movestogo", c.MovesToGo},
+		{"depth", c.Depth}, {"nodes", c.Nodes}, {"mate", c.Mate}, {"movetime", c.MoveTime},
+	} {
+		if field.value != nil {
+			parts = append(parts, field.name, strconv.Itoa(*field.value))
+		}
+	}
+	if c.Infinite {
+		parts = append(parts, "infinite")
+	}
+	return strings.Join(parts, " ")
+}
+
 // stop
 
 type CmdStop struct{}
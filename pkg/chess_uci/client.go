@@ -0,0 +1,310 @@
+package chess_uci
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// responseTimeout bounds how long the client waits for uciok and readyok
+const responseTimeout = 10 * time.Second
+
+// quitTimeout is how long a started engine is given to exit after quit before it is killed
+const quitTimeout = 5 * time.Second
+
+// infoBufferSize is how many info lines a search holds for a slow reader before dropping them
+const infoBufferSize = 64
+
+// ErrEngineExited is returned when the engine stops responding because its output has ended.
+var ErrEngineExited = errors.New("engine exited")
+
+// Client drives an external UCI engine, either a process started by StartClient or any pair of streams
+// given to NewClient.
+type Client struct {
+	// EngineName and EngineAuthor are the id the engine sent in the handshake
+	EngineName   string
+	EngineAuthor string
+	// Options lists the options the engine announced in the handshake
+	Options []UciOption
+
+	writer io.Writer
+	// process is the engine process when the client started it
+	process *exec.Cmd
+	stdin   io.Closer
+
+	uciOk   chan struct{}
+	readyOk chan struct{}
+	// exited is closed once the engine's output has ended
+	exited chan struct{}
+
+	// mutex guards the handshake fields and the running search, which the read loop updates
+	mutex  sync.Mutex
+	search *Search
+}
+
+// Search is a search running in the engine.
+type Search struct {
+	// Info receives the info lines of the search and is closed when the search ends. Lines are dropped
+	// rather than blocking the engine when the reader falls behind.
+	Info <-chan SearchInfo
+
+	info chan SearchInfo
+	done chan struct{}
+	best BestMove
+	err  error
+}
+
+// Wait waits for the search to end and returns the engine's best move.
+func (s *Search) Wait() (BestMove, error) {
+	<-s.done
+	return s.best, s.err
+}
+
+func (s *Search) finish(best BestMove, err error) {
+	s.best, s.err = best, err
+	close(s.info)
+	close(s.done)
+}
+
+// StartClient starts an engine executable and performs the uci/isready handshake.
+func StartClient(path string, args ...string) (*Client, error) {
+	process := exec.Command(path, args...)
+	stdin, err := process.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := process.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := process.Start(); err != nil {
+		return nil, fmt.Errorf("starting engine %s: %w", path, err)
+	}
+
+	client := newClient(stdout, stdin)
+	client.process = process
+	client.stdin = stdin
+	if err := client.handshake(); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return client, nil
+}
+
+// NewClient performs the uci/isready handshake with an engine reading commands from writer and writing
+// responses to reader.
+func NewClient(reader io.Reader, writer io.Writer) (*Client, error) {
+	client := newClient(reader, writer)
+	if err := client.handshake(); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+func newClient(reader io.Reader, writer io.Writer) *Client {
+	client := &Client{
+		writer:  writer,
+		uciOk:   make(chan struct{}),
+		readyOk: make(chan struct{}, 1),
+		exited:  make(chan struct{}),
+	}
+	go client.readLoop(reader)
+	return client
+}
+
+func (c *Client) handshake() error {
+	if err := c.send("uci"); err != nil {
+		return err
+	}
+	if err := c.await(c.uciOk, "uciok"); err != nil {
+		return err
+	}
+	return c.IsReady()
+}
+
+// readLoop dispatches the engine's responses until its output ends.
+func (c *Client) readLoop(reader io.Reader) {
+	scanner := bufio.NewScanner(reader)
+	uciOk := false
+	for scanner.Scan() {
+		response, err := ParseUciResponse(scanner.Text())
+		if err != nil {
+			// engines are free to print anything else, such as a banner
+			continue
+		}
+
+		c.mutex.Lock()
+		switch r := response.(type) {
+		case ResponseId:
+			if r.Field == "name" {
+				c.EngineName = r.Value
+			} else {
+				c.EngineAuthor = r.Value
+			}
+		case UciOption:
+			c.Options = append(c.Options, r)
+		case ResponseUciOk:
+			if !uciOk {
+				uciOk = true
+				close(c.uciOk)
+			}
+		case ResponseReadyOk:
+			select {
+			case c.readyOk <- struct{}{}:
+			default:
+			}
+		case SearchInfo:
+			if c.search != nil {
+				select {
+				case c.search.info <- r:
+				default:
+				}
+			}
+		case BestMove:
+			if c.search != nil {
+				c.search.finish(r, nil)
+				c.search = nil
+			}
+		}
+		c.mutex.Unlock()
+	}
+
+	// exited is closed first so a caller woken by the search ending sees the engine as exited
+	close(c.exited)
+	c.mutex.Lock()
+	if c.search != nil {
+		c.search.finish(BestMove{}, ErrEngineExited)
+		c.search = nil
+	}
+	c.mutex.Unlock()
+}
+
+func (c *Client) send(line string) error {
+	select {
+	case <-c.exited:
+		return ErrEngineExited
+	default:
+	}
+	_, err := io.WriteString(c.writer, line+"\n")
+	return err
+}
+
+// await waits for a response signal, the engine exiting or the response timeout.
+func (c *Client) await(signal <-chan struct{}, response string) error {
+	select {
+	case <-signal:
+		return nil
+	case <-c.exited:
+		// the response may have been the engine's last line
+		select {
+		case <-signal:
+			return nil
+		default:
+			return fmt.Errorf("waiting for %s: %w", response, ErrEngineExited)
+		}
+	case <-time.After(responseTimeout):
+		return fmt.Errorf("timed out waiting for %s", response)
+	}
+}
+
+// IsReady sends isready and waits for readyok.
+func (c *Client) IsReady() error {
+	if err := c.send("isready"); err != nil {
+		return err
+	}
+	return c.await(c.readyOk, "readyok")
+}
+
+// SetOption sets an engine option, a nil value is used for button options.
+func (c *Client) SetOption(name string, value *string) error {
+	line := "setoption name " + name
+	if value != nil {
+		line += " value " + *value
+	}
+	return c.send(line)
+}
+
+// NewGame tells the engine the next position is from a different game and waits for it to be ready.
+func (c *Client) NewGame() error {
+	if err := c.send("ucinewgame"); err != nil {
+		return err
+	}
+	return c.IsReady()
+}
+
+// SetPosition sets the position the next search starts from.
+func (c *Client) SetPosition(position CmdPosition) error {
+	return c.send(position.String())
+}
+
+// Go starts a search of the current position. Only one search runs at a time.
+func (c *Client) Go(limits CmdGo) (*Search, error) {
+	info := make(chan SearchInfo, infoBufferSize)
+	search := &Search{Info: info, info: info, done: make(chan struct{})}
+
+	c.mutex.Lock()
+	if c.search != nil {
+		c.mutex.Unlock()
+		return nil, fmt.Errorf("a search is already running")
+	}
+	select {
+	case <-c.exited:
+		c.mutex.Unlock()
+		return nil, ErrEngineExited
+	default:
+	}
+	c.search = search
+	c.mutex.Unlock()
+
+	if err := c.send(limits.String()); err != nil {
+		c.mutex.Lock()
+		c.search = nil
+		c.mutex.Unlock()
+		return nil, err
+	}
+	return search, nil
+}
+
+// Stop stops the running search and returns its best move.
+func (c *Client) Stop() (BestMove, error) {
+	c.mutex.Lock()
+	search := c.search
+	c.mutex.Unlock()
+	if search == nil {
+		return BestMove{}, fmt.Errorf("no search is running")
+	}
+
+	if err := c.send("stop"); err != nil {
+		return BestMove{}, err
+	}
+	return search.Wait()
+}
+
+// PonderHit tells the engine the move it was pondering on was played.
+func (c *Client) PonderHit() error {
+	return c.send("ponderhit")
+}
+
+// Close sends quit and, for a started engine, waits for it to exit, killing it if it does not exit promptly.
+func (c *Client) Close() error {
+	quitErr := c.send("quit")
+	if c.process == nil {
+		return quitErr
+	}
+
+	// the output is read to its end before waiting, as Wait closes the pipe
+	c.stdin.Close()
+	select {
+	case <-c.exited:
+		return c.process.Wait()
+	case <-time.After(quitTimeout):
+		c.process.Process.Kill()
+		<-c.exited
+		c.process.Wait()
+		return fmt.Errorf("engine did not exit after quit and was killed")
+	}
+}
@@ -0,0 +1,310 @@
+package chess_uci
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseUciResponse(t *testing.T) {
+	intPtr := func(n int) *int { return &n }
+	tests := []struct {
+		in   string
+		want UciResponse
+	}{
+		{"id name Stockfish 16", ResponseId{Field: "name", Value: "Stockfish 16"}},
+		{"id author the Stockfish developers", ResponseId{Field: "author", Value: "the Stockfish developers"}},
+		{"uciok", ResponseUciOk{}},
+		{"  READYOK ", ResponseReadyOk{}},
+		{"bestmove e2e4", BestMove{Move: "e2e4"}},
+		{"bestmove e7e8q ponder h2h1", BestMove{Move: "e7e8q", Ponder: "h2h1"}},
+		{"bestmove (none)", BestMove{}},
+		{
+			"info depth 20 seldepth 31 multipv 1 score cp 35 nodes 1234567 nps 987654 hashfull 412 tbhits 0 time 1250 pv e2e4 e7e5 g1f3",
+			SearchInfo{Depth: intPtr(20), SelDepth: intPtr(31), MultiPV: intPtr(1), Score: &UciScore{Value: 35}, Nodes: intPtr(1234567),
+				Nps: intPtr(987654), HashFull: intPtr(412), Time: intPtr(1250), PV: []string{"e2e4", "e7e5", "g1f3"}},
+		},
+		{"info depth 5 score mate -2 upperbound pv h7h8", SearchInfo{Depth: intPtr(5), Score: &UciScore{Mate: true, Value: -2, UpperBound: true}, PV: []string{"h7h8"}}},
+		{"info score cp 12 lowerbound currmove d2d4 currmovenumber 2", SearchInfo{Score: &UciScore{Value: 12, LowerBound: true}, CurrMove: "d2d4", CurrMoveNumber: intPtr(2)}},
+		{"info refutation d1h5 g6h5 depth 3", SearchInfo{Depth: intPtr(3)}},
+		{"info string NNUE evaluation using nn.nnue enabled", SearchInfo{String: "NNUE evaluation using nn.nnue enabled"}},
+		{"option name Hash type spin default 16 min 1 max 33554432", UciOption{OptionName: "Hash", Type: "spin", Default: strPtr("16"), Min: intPtr(1), Max: intPtr(33554432)}},
+		{"option name Clear Hash type button", UciOption{OptionName: "Clear Hash", Type: "button"}},
+		{"option name Use default book type check default true", UciOption{OptionName: "Use default book", Type: "check", Default: strPtr("true")}},
+		{"option name SyzygyPath type string default <empty>", UciOption{OptionName: "SyzygyPath", Type: "string", Default: strPtr("")}},
+		{"option name Style type combo default Normal var Solid var Normal var Risky", UciOption{OptionName: "Style", Type: "combo", Default: strPtr("Normal"), Vars: []string{"Solid", "Normal", "Risky"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseUciResponse(tt.in)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ParseUciResponse(%q) = %+v, want %+v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseUciResponse_Errors(t *testing.T) {
+	for _, in := range []string{"", "Stockfish 16 by the Stockfish developers", "id", "id version 3", "bestmove", "option type spin", "option name Hash type spin bogus 3"} {
+		if _, err := ParseUciResponse(in); err == nil {
+			t.Fatalf("expected error for %q", in)
+		}
+	}
+}
+
+func TestCommandStrings_RoundTrip(t *testing.T) {
+	for _, in := range []string{
+		"position startpos",
+		"position startpos moves e2e4 e7e5",
+		"position fen 7k/P7/8/8/8/8/8/K7 w - - 0 1 moves a7a8q",
+		"go",
+		"go searchmoves e2e4 d2d4 ponder wtime 1000 btime 2000 winc 10 binc 20 movestogo 30 depth 4 nodes 5000 mate 2 movetime 100 infinite",
+	} {
+		cmd, err := ParseUciCommand(in)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := cmd.(fmt.Stringer).String(); got != in {
+			t.Fatalf("String() = %q, want %q", got, in)
+		}
+	}
+}
+
+// helperEngineEnv makes the test binary act as a scripted engine, see TestHelperEngine.
+const helperEngineEnv = "CHESS_UCI_HELPER_ENGINE"
+
+// TestHelperEngine is not a real test: when started by startHelperEngine the test binary runs this scripted
+// engine on its stdin and stdout.
+func TestHelperEngine(t *testing.T) {
+	if os.Getenv(helperEngineEnv) != "1" {
+		return
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	send := func(lines ...string) {
+		for _, line := range lines {
+			fmt.Fprintln(out, line)
+		}
+		out.Flush()
+	}
+
+	send("Scripted engine banner")
+	position := ""
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		cmd, err := ParseUciCommand(scanner.Text())
+		if err != nil {
+			continue
+		}
+		switch c := cmd.(type) {
+		case CmdUci:
+			send("id name Scripted Engine", "id author The Testers",
+				"option name Hash type spin default 16 min 1 max 1024",
+				"option name Ponder type check default false",
+				"option name Clear Hash type button",
+				"uciok")
+		case CmdIsReady:
+			send("readyok")
+		case CmdPosition:
+			position = c.String()
+		case CmdGo:
+			if c.Mate != nil {
+				// a crash in the middle of a search
+				os.Exit(3)
+			}
+			send("info string "+position,
+				"info depth 1 seldepth 2 multipv 1 score cp 20 nodes 100 nps 1000 hashfull 3 time 5 pv e2e4 e7e5",
+				"info depth 2 score mate 3 lowerbound pv e2e4")
+			if !c.Infinite {
+				send("bestmove e2e4 ponder e7e5")
+			}
+		case CmdStop:
+			send("bestmove d2d4")
+		case CmdQuit:
+			os.Exit(0)
+		}
+	}
+	os.Exit(0)
+}
+
+func startHelperEngine(t *testing.T) *Client {
+	t.Helper()
+	t.Setenv(helperEngineEnv, "1")
+	client, err := StartClient(os.Args[0], "-test.run=^TestHelperEngine$")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return client
+}
+
+func collectInfo(search *Search) []SearchInfo {
+	infos := []SearchInfo{}
+	for info := range search.Info {
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+func TestClient_Handshake(t *testing.T) {
+	client := startHelperEngine(t)
+	defer client.Close()
+
+	if client.EngineName != "Scripted Engine" || client.EngineAuthor != "The Testers" {
+		t.Fatalf("unexpected id: %q %q", client.EngineName, client.EngineAuthor)
+	}
+	if len(client.Options) != 3 || client.Options[0].OptionName != "Hash" || client.Options[2].Type != "button" {
+		t.Fatalf("unexpected options: %+v", client.Options)
+	}
+	if err := client.NewGame(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestClient_Search(t *testing.T) {
+	client := startHelperEngine(t)
+
+	if err := client.SetPosition(CmdPosition{StartPos: true, Moves: []string{"e2e4"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	depth := 2
+	search, err := client.Go(CmdGo{Depth: &depth})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	infos := collectInfo(search)
+	best, err := search.Wait()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if best != (BestMove{Move: "e2e4", Ponder: "e7e5"}) {
+		t.Fatalf("unexpected best move: %+v", best)
+	}
+	if len(infos) != 3 || infos[0].String != "position startpos moves e2e4" {
+		t.Fatalf("unexpected info: %+v", infos)
+	}
+	if *infos[1].Depth != 1 || infos[1].Score.Value != 20 || !reflect.DeepEqual(infos[1].PV, []string{"e2e4", "e7e5"}) {
+		t.Fatalf("unexpected info: %+v", infos[1])
+	}
+	if !infos[2].Score.Mate || !infos[2].Score.LowerBound {
+		t.Fatalf("unexpected score: %+v", infos[2].Score)
+	}
+
+	if err := client.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestClient_StopInfiniteSearch(t *testing.T) {
+	client := startHelperEngine(t)
+	defer client.Close()
+
+	search, err := client.Go(CmdGo{Infinite: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := client.Go(CmdGo{}); err == nil {
+		t.Fatalf("expected an error starting a second search")
+	}
+
+	best, err := client.Stop()
+	if err != nil || best.Move != "d2d4" {
+		t.Fatalf("unexpected stop result: %+v %v", best, err)
+	}
+	if len(collectInfo(search)) != 3 {
+		t.Fatalf("expected the info lines sent before stop")
+	}
+}
+
+func TestClient_EngineExitsDuringSearch(t *testing.T) {
+	client := startHelperEngine(t)
+	defer client.Close()
+
+	mate := 1
+	search, err := client.Go(CmdGo{Mate: &mate})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := search.Wait(); !errors.Is(err, ErrEngineExited) {
+		t.Fatalf("expected ErrEngineExited, got %v", err)
+	}
+	if err := client.IsReady(); !errors.Is(err, ErrEngineExited) {
+		t.Fatalf("expected ErrEngineExited, got %v", err)
+	}
+}
+
+func TestClient_StartMissingEngine(t *testing.T) {
+	if _, err := StartClient("/nonexistent/engine"); err == nil {
+		t.Fatalf("expected an error")
+	}
+}
+
+func TestClient_DrivesServer(t *testing.T) {
+	commandReader, commandWriter := io.Pipe()
+	responseReader, responseWriter := io.Pipe()
+	serverDone := make(chan error, 1)
+	go func() {
+		serverDone <- NewServer(newFakeEngine(), commandReader, responseWriter).Run()
+		responseWriter.Close()
+	}()
+
+	client, err := NewClient(responseReader, commandWriter)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if client.EngineName != "Fake 1.0" || len(client.Options) != 2 {
+		t.Fatalf("unexpected handshake: %+v", client)
+	}
+
+	if err := client.SetPosition(CmdPosition{FEN: "7k/8/8/8/8/8/8/K6R w - - 0 1"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	search, err := client.Go(CmdGo{Infinite: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := client.IsReady(); err != nil {
+		t.Fatalf("isready during a search: %v", err)
+	}
+	best, err := client.Stop()
+	if err != nil || best.Move == "" {
+		t.Fatalf("unexpected stop result: %+v %v", best, err)
+	}
+	if infos := collectInfo(search); len(infos) != 1 || infos[0].PV[0] != best.Move {
+		t.Fatalf("unexpected info: %+v", infos)
+	}
+
+	client.Close()
+	commandWriter.Close()
+	select {
+	case err := <-serverDone:
+		if err != nil {
+			t.Fatalf("unexpected server error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("server did not exit after quit")
+	}
+}
+
+func TestClient_IgnoresUnparsedOutput(t *testing.T) {
+	// the pipe stays open so the engine does not appear to exit
+	silence, silenceWriter := io.Pipe()
+	defer silenceWriter.Close()
+	engineOutput := io.MultiReader(strings.NewReader("Some engine v1 by nobody\nuciok\nreadyok\n"), silence)
+	client, err := NewClient(engineOutput, io.Discard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if client.EngineName != "" {
+		t.Fatalf("unexpected name: %q", client.EngineName)
+	}
+}
@@ -0,0 +1,364 @@
+package chess_uci
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// responses.go holds the messages an engine sends to the GUI.
+//
+// Supported responses:
+//   - id name <name> / id author <author>
+//   - uciok
+//   - readyok
+//   - bestmove <move> [ponder <move>]
+//   - info [depth <N>] [seldepth <N>] [multipv <N>] [score cp <N> | mate <N> [lowerbound | upperbound]]
+//     [nodes <N>] [nps <N>] [hashfull <N>] [time <ms>] [currmove <move>] [currmovenumber <N>] [pv <move> ...]
+//     [string <text>]
+//   - option name <name> type <type> [default <value>] [min <N>] [max <N>] [var <value> ...]
+
+// UciResponse is the common interface for the messages parsed by ParseUciResponse, mirroring UciCommand.
+type UciResponse interface {
+	Name() string
+}
+
+// id name <name> / id author <author>
+type ResponseId struct {
+	Field string // name or author
+	Value string
+}
+
+func (ResponseId) Name() string { return "id" }
+
+// uciok
+type ResponseUciOk struct{}
+
+func (ResponseUciOk) Name() string { return "uciok" }
+
+// readyok
+type ResponseReadyOk struct{}
+
+func (ResponseReadyOk) Name() string { return "readyok" }
+
+// BestMove is the result of a search. An empty Move means the position has no legal moves.
+type BestMove struct {
+	Move   string
+	Ponder string // empty when there is no move to ponder on
+}
+
+func (BestMove) Name() string { return "bestmove" }
+
+func (b BestMove) String() string {
+	move := b.Move
+	if move == "" {
+		move = "(none)"
+	}
+	if b.Ponder == "" {
+		return "bestmove " + move
+	}
+	return "bestmove " + move + " ponder " + b.Ponder
+}
+
+// UciScore is the evaluation reported in an info line, from the point of view of the side to move.
+type UciScore struct {
+	// Mate is true when Value is a number of moves to mate rather than centipawns, negative when the side to move is mated
+	Mate       bool
+	Value      int
+	LowerBound bool
+	UpperBound bool
+}
+
+func (s UciScore) String() string {
+	text := "score cp " + strconv.Itoa(s.Value)
+	if s.Mate {
+		text = "score mate " + strconv.Itoa(s.Value)
+	}
+	if s.LowerBound {
+		text += " lowerbound"
+	} else if s.UpperBound {
+		text += " upperbound"
+	}
+	return text
+}
+
+// SearchInfo is an info line reporting the progress of a search, nil and empty fields are left out.
+type SearchInfo struct {
+	Depth          *int
+	SelDepth       *int
+	MultiPV        *int
+	Score          *UciScore
+	Nodes          *int
+	Nps            *int
+	HashFull       *int // permille
+	Time           *int // milliseconds
+	CurrMove       string
+	CurrMoveNumber *int
+	PV             []string
+	String         string // free text, always written last as it runs to the end of the line
+}
+
+func (SearchInfo) Name() string { return "info" }
+
+// Line formats the info line.
+func (i SearchInfo) Line() string {
+	parts := []string{"info"}
+	appendInt := func(name string, value *int) {
+		if value != nil {
+			parts = append(parts, name, strconv.Itoa(*value))
+		}
+	}
+
+	appendInt("depth", i.Depth)
+	appendInt("seldepth", i.SelDepth)
+	appendInt("multipv", i.MultiPV)
+	if i.Score != nil {
+		parts = append(parts, i.Score.String())
+	}
+	appendInt("nodes", i.Nodes)
+	appendInt("nps", i.Nps)
+	appendInt("hashfull", i.HashFull)
+	appendInt("time", i.Time)
+	if i.CurrMove != "" {
+		parts = append(parts, "currmove", i.CurrMove)
+	}
+	appendInt("currmovenumber", i.CurrMoveNumber)
+	if len(i.PV) > 0 {
+		parts = append(parts, "pv")
+		parts = append(parts, i.PV...)
+	}
+	if i.String != "" {
+		parts = append(parts, "string", i.String)
+	}
+	return strings.Join(parts, " ")
+}
+
+// UciOption describes an option announced in response to uci.
+type UciOption struct {
+	OptionName string
+	Type       string // check, spin, combo, button or string
+	Default    *string
+	Min        *int     // spin only
+	Max        *int     // spin only
+	Vars       []string // combo only
+}
+
+func (UciOption) Name() string { return "option" }
+
+func (o UciOption) String() string {
+	parts := []string{"option", "name", o.OptionName, "type", o.Type}
+	if o.Default != nil {
+		defaultValue := *o.Default
+		// an empty string option is announced with the <empty> placeholder
+		if defaultValue == "" && o.Type == "string" {
+			defaultValue = "<empty>"
+		}
+		parts = append(parts, "default", defaultValue)
+	}
+	if o.Min != nil {
+		parts = append(parts, "min", strconv.Itoa(*o.Min))
+	}
+	if o.Max != nil {
+		parts = append(parts, "max", strconv.Itoa(*o.Max))
+	}
+	for _, v := range o.Vars {
+		parts = append(parts, "var", v)
+	}
+	return strings.Join(parts, " ")
+}
+
+// ParseUciResponse parses a single line sent by an engine. Like ParseUciCommand the keyword is
+// case-insensitive, unknown info fields are ignored and unrecognized lines return an error.
+func ParseUciResponse(input string) (UciResponse, error) {
+	tokens := fieldsKeepEmpty(input)
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty input")
+	}
+	switch strings.ToLower(tokens[0]) {
+	case "id":
+		return parseId(tokens)
+	case "uciok":
+		return ResponseUciOk{}, nil
+	case "readyok":
+		return ResponseReadyOk{}, nil
+	case "bestmove":
+		return parseBestMove(tokens)
+	case "info":
+		return parseInfo(tokens), nil
+	case "option":
+		return parseOption(tokens)
+	default:
+		return nil, fmt.Errorf("unknown response: %s", tokens[0])
+	}
+}
+
+func parseId(tokens []string) (UciResponse, error) {
+	// id name <name> | id author <author>
+	if len(tokens) < 3 {
+		return nil, fmt.Errorf("id requires a field and a value")
+	}
+	field := strings.ToLower(tokens[1])
+	if field != "name" && field != "author" {
+		return nil, fmt.Errorf("id: expected 'name' or 'author', got %q", tokens[1])
+	}
+	return ResponseId{Field: field, Value: strings.Join(tokens[2:], " ")}, nil
+}
+
+func parseBestMove(tokens []string) (UciResponse, error) {
+	// bestmove <move> [ponder <move>]
+	if len(tokens) < 2 {
+		return nil, fmt.Errorf("bestmove requires a move")
+	}
+	best := BestMove{Move: tokens[1]}
+	if best.Move == "(none)" || best.Move == "0000" {
+		best.Move = ""
+	}
+	if len(tokens) >= 4 && strings.ToLower(tokens[2]) == "ponder" {
+		best.Ponder = tokens[3]
+	}
+	return best, nil
+}
+
+func isInfoKeyword(tok string) bool {
+	switch tok {
+	case "depth", "seldepth", "time", "nodes", "pv", "multipv", "score", "currmove", "currmovenumber",
+		"hashfull", "nps", "tbhits", "sbhits", "cpuload", "string", "refutation", "currline":
+		return true
+	default:
+		return false
+	}
+}
+
+func parseInfo(tokens []string) SearchInfo {
+	info := SearchInfo{}
+	i := 1
+	for i < len(tokens) {
+		switch strings.ToLower(tokens[i]) {
+		case "depth":
+			info.Depth = parseNextIntPtr(tokens, &i)
+		case "seldepth":
+			info.SelDepth = parseNextIntPtr(tokens, &i)
+		case "multipv":
+			info.MultiPV = parseNextIntPtr(tokens, &i)
+		case "nodes":
+			info.Nodes = parseNextIntPtr(tokens, &i)
+		case "nps":
+			info.Nps = parseNextIntPtr(tokens, &i)
+		case "hashfull":
+			info.HashFull = parseNextIntPtr(tokens, &i)
+		case "time":
+			info.Time = parseNextIntPtr(tokens, &i)
+		case "currmovenumber":
+			info.CurrMoveNumber = parseNextIntPtr(tokens, &i)
+		case "currmove":
+			if i+1 < len(tokens) {
+				info.CurrMove = tokens[i+1]
+			}
+			i += 2
+		case "score":
+			info.Score = parseScore(tokens, &i)
+		case "pv":
+			i++
+			for i < len(tokens) && !isInfoKeyword(strings.ToLower(tokens[i])) {
+				info.PV = append(info.PV, tokens[i])
+				i++
+			}
+		case "string":
+			// the text runs to the end of the line
+			info.String = strings.Join(tokens[i+1:], " ")
+			i = len(tokens)
+		default:
+			// fields without a SearchInfo counterpart, such as tbhits or refutation, are skipped with their values
+			i++
+			for i < len(tokens) && !isInfoKeyword(strings.ToLower(tokens[i])) {
+				i++
+			}
+		}
+	}
+	return info
+}
+
+func parseScore(tokens []string, i *int) *UciScore {
+	// score cp <N> | mate <N> [lowerbound | upperbound]
+	*i = *i + 1
+	if *i >= len(tokens) {
+		return nil
+	}
+	score := UciScore{Mate: strings.ToLower(tokens[*i]) == "mate"}
+	value := parseNextIntPtr(tokens, i)
+	if value == nil {
+		return nil
+	}
+	score.Value = *value
+	if *i < len(tokens) {
+		switch strings.ToLower(tokens[*i]) {
+		case "lowerbound":
+			score.LowerBound = true
+			*i = *i + 1
+		case "upperbound":
+			score.UpperBound = true
+			*i = *i + 1
+		}
+	}
+	return &score
+}
+
+func isOptionKeyword(tok string) bool {
+	switch tok {
+	case "name", "type", "default", "min", "max", "var":
+		return true
+	default:
+		return false
+	}
+}
+
+func parseOption(tokens []string) (UciResponse, error) {
+	// option name <name> type <type> [default <value>] [min <N>] [max <N>] [var <value>]*
+	// name, default and var values may contain spaces and run until the next keyword
+	option := UciOption{}
+	i := 1
+	readUntil := func(isEnd func(string) bool) string {
+		start := i
+		for i < len(tokens) && !isEnd(strings.ToLower(tokens[i])) {
+			i++
+		}
+		return strings.Join(tokens[start:i], " ")
+	}
+	readValue := func() string { return readUntil(isOptionKeyword) }
+
+	for i < len(tokens) {
+		key := strings.ToLower(tokens[i])
+		i++
+		switch key {
+		case "name":
+			// only type can end a name, so names such as "Use default book" are kept whole
+			option.OptionName = readUntil(func(tok string) bool { return tok == "type" })
+		case "type":
+			if i < len(tokens) {
+				option.Type = strings.ToLower(tokens[i])
+				i++
+			}
+		case "default":
+			value := readValue()
+			if value == "<empty>" {
+				value = ""
+			}
+			option.Default = &value
+		case "min":
+			i--
+			option.Min = parseNextIntPtr(tokens, &i)
+		case "max":
+			i--
+			option.Max = parseNextIntPtr(tokens, &i)
+		case "var":
+			option.Vars = append(option.Vars, readValue())
+		default:
+			return nil, fmt.Errorf("option: unexpected token %q", tokens[i-1])
+		}
+	}
+
+	if option.OptionName == "" || option.Type == "" {
+		return nil, fmt.Errorf("option requires a name and a type")
+	}
+	return option, nil
+}
@@ -0,0 +1,64 @@
+package chess_uci
+
+import "testing"
+
+func TestSearchInfo_Line(t *testing.T) {
+	depth, selDepth, multiPV, nodes, nps, hashFull, time, currMoveNumber := 12, 18, 2, 100000, 50000, 250, 2000, 3
+	tests := []struct {
+		name string
+		info SearchInfo
+		want string
+	}{
+		{"empty", SearchInfo{}, "info"},
+		{"string only", SearchInfo{String: "hello world"}, "info string hello world"},
+		{
+			"every field",
+			SearchInfo{
+				Depth: &depth, SelDepth: &selDepth, MultiPV: &multiPV, Score: &UciScore{Value: -35, UpperBound: true},
+				Nodes: &nodes, Nps: &nps, HashFull: &hashFull, Time: &time, CurrMove: "e2e4", CurrMoveNumber: &currMoveNumber,
+				PV: []string{"e2e4", "e7e5"}, String: "last",
+			},
+			"info depth 12 seldepth 18 multipv 2 score cp -35 upperbound nodes 100000 nps 50000 hashfull 250 time 2000 currmove e2e4 currmovenumber 3 pv e2e4 e7e5 string last",
+		},
+		{"mate", SearchInfo{Score: &UciScore{Mate: true, Value: -3, LowerBound: true}}, "info score mate -3 lowerbound"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.info.Line(); got != tt.want {
+				t.Fatalf("Line() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBestMove_String(t *testing.T) {
+	tests := []struct {
+		move BestMove
+		want string
+	}{
+		{BestMove{Move: "e2e4"}, "bestmove e2e4"},
+		{BestMove{Move: "e7e8q", Ponder: "h7h8"}, "bestmove e7e8q ponder h7h8"},
+		{BestMove{}, "bestmove (none)"},
+	}
+	for _, tt := range tests {
+		if got := tt.move.String(); got != tt.want {
+			t.Fatalf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestUciOption_String(t *testing.T) {
+	style, empty := "Normal", ""
+	tests := []struct {
+		option UciOption
+		want   string
+	}{
+		{UciOption{OptionName: "Style", Type: "combo", Default: &style, Vars: []string{"Solid", "Normal", "Risky"}}, "option name Style type combo default Normal var Solid var Normal var Risky"},
+		{UciOption{OptionName: "NalimovPath", Type: "string", Default: &empty}, "option name NalimovPath type string default <empty>"},
+	}
+	for _, tt := range tests {
+		if got := tt.option.String(); got != tt.want {
+			t.Fatalf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
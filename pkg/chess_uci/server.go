@@ -0,0 +1,253 @@
+package chess_uci
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/jerhon/chess/pkg/chess"
+	"github.com/jerhon/chess/pkg/chess/fen"
+)
+
+// Engine is the search behind a Server. The server calls it from a single goroutine at a time, except for
+// PonderHit which is called while Search is running.
+type Engine interface {
+	// Name and Author are sent in the id response to uci
+	Name() string
+	Author() string
+	// Options lists the options announced in response to uci
+	Options() []UciOption
+	SetOption(name string, value *string) error
+	// NewGame clears any state kept between searches of the same game
+	NewGame()
+	// Search searches the current position of the game within the limits until they are reached or ctx is
+	// cancelled, reporting progress through info. It returns the best move found, or an empty move when the
+	// position has no legal moves. The game must not be changed.
+	Search(ctx context.Context, chessGame *chess.ChessGame, limits CmdGo, info func(SearchInfo)) BestMove
+	// PonderHit tells a search started with go ponder that the expected move was played and the search now runs
+	// under its time limits.
+	PonderHit()
+}
+
+// Server runs a UCI session: it reads commands from a GUI, keeps the position it describes and runs the
+// engine's searches on their own goroutine so stop and isready are answered while searching.
+type Server struct {
+	engine Engine
+	reader io.Reader
+
+	// writeMutex serializes responses from the command loop and the search goroutine
+	writeMutex sync.Mutex
+	writer     io.Writer
+	writeErr   error
+
+	// chessGame is the position to search, nil after a position command that could not be set up
+	chessGame *chess.ChessGame
+	// positionErr is why the last position command could not be set up
+	positionErr error
+	debug       bool
+	search      *activeSearch
+}
+
+// activeSearch tracks a search goroutine.
+type activeSearch struct {
+	cancel context.CancelFunc
+	// done is closed once the best move has been written
+	done chan struct{}
+	// release is closed when a ponder or infinite search may report its best move
+	release  chan struct{}
+	released bool
+	infinite bool
+}
+
+func NewServer(engine Engine, reader io.Reader, writer io.Writer) *Server {
+	return &Server{
+		engine:    engine,
+		reader:    reader,
+		writer:    writer,
+		chessGame: newGame(),
+	}
+}
+
+// Run processes commands until quit or the end of the input. Any running search is stopped and its best
+// move written before Run returns. It returns the first error reading commands or writing responses.
+func (s *Server) Run() error {
+	scanner := bufio.NewScanner(s.reader)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		cmd, err := ParseUciCommand(line)
+		if err != nil {
+			// unknown commands are ignored as the protocol requires
+			if s.debug {
+				s.writeLine("info string " + err.Error())
+			}
+			continue
+		}
+
+		if _, quit := cmd.(CmdQuit); quit {
+			break
+		}
+		s.handle(cmd)
+
+		if err := s.writeError(); err != nil {
+			s.stopSearch()
+			return err
+		}
+	}
+
+	s.stopSearch()
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return s.writeError()
+}
+
+func (s *Server) handle(cmd UciCommand) {
+	switch c := cmd.(type) {
+	case CmdUci:
+		s.writeLine("id name " + s.engine.Name())
+		s.writeLine("id author " + s.engine.Author())
+		for _, option := range s.engine.Options() {
+			s.writeLine(option.String())
+		}
+		s.writeLine("uciok")
+	case CmdDebug:
+		s.debug = c.On
+	case CmdIsReady:
+		s.writeLine("readyok")
+	case CmdSetOption:
+		if err := s.engine.SetOption(c.OptionName, c.Value); err != nil {
+			s.writeLine("info string " + err.Error())
+		}
+	case CmdUciNewGame:
+		s.stopSearch()
+		s.engine.NewGame()
+		s.chessGame, s.positionErr = newGame(), nil
+	case CmdPosition:
+		s.stopSearch()
+		chessGame, err := newGameFromPosition(c)
+		if err != nil {
+			// searching the moves before the error would answer for a position the GUI isn't in
+			s.writeLine("info string " + err.Error())
+			s.chessGame, s.positionErr = nil, err
+			return
+		}
+		s.chessGame, s.positionErr = chessGame, nil
+	case CmdGo:
+		s.startSearch(c)
+	case CmdStop:
+		s.stopSearch()
+	case CmdPonderHit:
+		if s.search != nil && !s.search.released {
+			s.engine.PonderHit()
+			if !s.search.infinite {
+				s.releaseSearch()
+			}
+		}
+	}
+}
+
+// newGame returns a game from the starting position. A GUI keeps sending moves after a draw could have been
+// claimed, so repetitions and the fifty-move rule never end the game.
+func newGame() *chess.ChessGame {
+	chessGame := chess.NewGame()
+	chessGame.SetDrawRules(chess.USCFRules)
+	return chessGame
+}
+
+// newGameFromPosition builds the game for a position command, returning an error when the FEN is invalid or a
+// move cannot be played.
+func newGameFromPosition(cmd CmdPosition) (*chess.ChessGame, error) {
+	chessGame := newGame()
+	if !cmd.StartPos {
+		position, err := fen.ParseFen(cmd.FEN)
+		if err != nil {
+			return nil, fmt.Errorf("invalid position fen %s: %v", cmd.FEN, err)
+		}
+		chessGame = chess.NewGameFromPosition(&position)
+		chessGame.SetDrawRules(chess.USCFRules)
+	}
+
+	for _, move := range cmd.Moves {
+		if _, err := chessGame.TryUciMove(move); err != nil {
+			return nil, fmt.Errorf("invalid position move %s: %v", move, err)
+		}
+	}
+	return chessGame, nil
+}
+
+func (s *Server) startSearch(limits CmdGo) {
+	// a go while searching replaces the running search
+	s.stopSearch()
+
+	if s.chessGame == nil {
+		s.writeLine("info string no position to search: " + s.positionErr.Error())
+		s.writeLine(BestMove{}.String())
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	search := &activeSearch{
+		cancel:   cancel,
+		done:     make(chan struct{}),
+		release:  make(chan struct{}),
+		infinite: limits.Infinite,
+	}
+	if !limits.Ponder && !limits.Infinite {
+		search.released = true
+		close(search.release)
+	}
+	s.search = search
+
+	chessGame := s.chessGame
+	go func() {
+		defer close(search.done)
+		best := s.engine.Search(ctx, chessGame, limits, func(info SearchInfo) {
+			s.writeLine(info.Line())
+		})
+
+		// the best move of a ponder or infinite search is held until stop or ponderhit
+		<-search.release
+		s.writeLine(best.String())
+	}()
+}
+
+// releaseSearch allows a ponder or infinite search to report its best move.
+func (s *Server) releaseSearch() {
+	if !s.search.released {
+		s.search.released = true
+		close(s.search.release)
+	}
+}
+
+// stopSearch stops the running search, if there is one, and waits for its best move to be written.
+func (s *Server) stopSearch() {
+	if s.search == nil {
+		return
+	}
+	s.search.cancel()
+	s.releaseSearch()
+	<-s.search.done
+	s.search = nil
+}
+
+func (s *Server) writeLine(line string) {
+	s.writeMutex.Lock()
+	defer s.writeMutex.Unlock()
+	if s.writeErr != nil {
+		return
+	}
+	_, s.writeErr = io.WriteString(s.writer, line+"\n")
+}
+
+func (s *Server) writeError() error {
+	s.writeMutex.Lock()
+	defer s.writeMutex.Unlock()
+	return s.writeErr
+}
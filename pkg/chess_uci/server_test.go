@@ -0,0 +1,336 @@
+package chess_uci
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jerhon/chess/pkg/chess"
+	"github.com/jerhon/chess/pkg/chess/fen"
+	"github.com/jerhon/chess/pkg/chess/game"
+)
+
+// fakeEngine plays the first legal move. Ponder and infinite searches run until they are stopped.
+type fakeEngine struct {
+	mutex      sync.Mutex
+	searchFens []string
+	options    map[string]string
+	newGames   int
+	ponderHits int
+}
+
+func newFakeEngine() *fakeEngine {
+	return &fakeEngine{options: map[string]string{}}
+}
+
+func (e *fakeEngine) Name() string   { return "Fake 1.0" }
+func (e *fakeEngine) Author() string { return "The Testers" }
+
+func (e *fakeEngine) Options() []UciOption {
+	hashDefault := "16"
+	minHash, maxHash := 1, 1024
+	return []UciOption{
+		{OptionName: "Hash", Type: "spin", Default: &hashDefault, Min: &minHash, Max: &maxHash},
+		{OptionName: "Clear Hash", Type: "button"},
+	}
+}
+
+func (e *fakeEngine) SetOption(name string, value *string) error {
+	if name != "Hash" || value == nil {
+		return fmt.Errorf("unknown option %s", name)
+	}
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.options[name] = *value
+	return nil
+}
+
+func (e *fakeEngine) NewGame() {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.newGames++
+}
+
+func (e *fakeEngine) Search(ctx context.Context, chessGame *chess.ChessGame, limits CmdGo, info func(SearchInfo)) BestMove {
+	e.mutex.Lock()
+	e.searchFens = append(e.searchFens, fen.ToFenString(chessGame.GetPosition()))
+	e.mutex.Unlock()
+
+	if limits.Infinite || limits.Ponder {
+		<-ctx.Done()
+	}
+
+	moves := chessGame.GetLegalMoves()
+	if len(moves) == 0 {
+		return BestMove{}
+	}
+	depth := 1
+	move := moves[0].UciString(game.Queen)
+	info(SearchInfo{Depth: &depth, Score: &UciScore{Value: 12}, PV: []string{move}})
+	return BestMove{Move: move}
+}
+
+func (e *fakeEngine) PonderHit() {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.ponderHits++
+}
+
+func (e *fakeEngine) lastSearchFen() string {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if len(e.searchFens) == 0 {
+		return ""
+	}
+	return e.searchFens[len(e.searchFens)-1]
+}
+
+// runServer runs a session over the commands and returns the responses.
+func runServer(t *testing.T, engine Engine, commands ...string) []string {
+	t.Helper()
+	output := &strings.Builder{}
+	server := NewServer(engine, strings.NewReader(strings.Join(commands, "\n")+"\n"), output)
+	if err := server.Run(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return strings.Split(strings.TrimSuffix(output.String(), "\n"), "\n")
+}
+
+func TestServer_Handshake(t *testing.T) {
+	lines := runServer(t, newFakeEngine(), "uci", "isready", "quit")
+
+	want := []string{
+		"id name Fake 1.0",
+		"id author The Testers",
+		"option name Hash type spin default 16 min 1 max 1024",
+		"option name Clear Hash type button",
+		"uciok",
+		"readyok",
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected handshake:\n%s\nwant:\n%s", strings.Join(lines, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestServer_PositionAndGo(t *testing.T) {
+	engine := newFakeEngine()
+	lines := runServer(t, engine, "position startpos moves e2e4 e7e5", "go depth 1")
+
+	if got := engine.lastSearchFen(); got != "rnbqkbnr/pppp1ppp/8/4p3/4P3/8/PPPP1PPP/RNBQKBNR w KQkq e6 0 2" {
+		t.Fatalf("unexpected search position: %s", got)
+	}
+	if len(lines) != 2 || lines[0] != "info depth 1 score cp 12 pv "+strings.TrimPrefix(lines[1], "bestmove ") {
+		t.Fatalf("unexpected output: %v", lines)
+	}
+	if !strings.HasPrefix(lines[1], "bestmove ") {
+		t.Fatalf("expected bestmove, got %v", lines)
+	}
+}
+
+func TestServer_PositionFromFen(t *testing.T) {
+	engine := newFakeEngine()
+	lines := runServer(t, engine, "position fen 7k/P7/8/8/8/8/8/K7 w - - 0 1", "go movetime 10")
+
+	if got := engine.lastSearchFen(); got != "7k/P7/8/8/8/8/8/K7 w - - 0 1" {
+		t.Fatalf("unexpected search position: %s", got)
+	}
+	if lines[len(lines)-1] == "bestmove (none)" {
+		t.Fatalf("expected a move, got %v", lines)
+	}
+}
+
+func TestServer_PositionWithIllegalMove(t *testing.T) {
+	engine := newFakeEngine()
+	lines := runServer(t, engine, "position startpos moves e2e4 e2e4", "go depth 1")
+
+	if !strings.HasPrefix(lines[0], "info string invalid position move e2e4") {
+		t.Fatalf("expected an info string, got %v", lines)
+	}
+	// the position the GUI is in is unknown, so nothing is searched
+	if got := engine.lastSearchFen(); got != "" {
+		t.Fatalf("expected no search, got %s", got)
+	}
+	if lines[len(lines)-1] != "bestmove (none)" {
+		t.Fatalf("expected no move, got %v", lines)
+	}
+
+	// a valid position replaces the invalid one
+	lines = runServer(t, engine, "position startpos moves e2e4 e2e4", "position startpos moves e2e4", "go depth 1")
+	if got := engine.lastSearchFen(); got != "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1" {
+		t.Fatalf("unexpected search position: %s", got)
+	}
+	if lines[len(lines)-1] == "bestmove (none)" {
+		t.Fatalf("expected a move, got %v", lines)
+	}
+}
+
+func TestServer_PositionWithInvalidFen(t *testing.T) {
+	engine := newFakeEngine()
+	lines := runServer(t, engine, "position fen rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR x KQkq - 0 1", "go depth 1")
+
+	if !strings.HasPrefix(lines[0], "info string invalid position fen") || lines[len(lines)-1] != "bestmove (none)" {
+		t.Fatalf("unexpected output: %v", lines)
+	}
+	if got := engine.lastSearchFen(); got != "" {
+		t.Fatalf("expected no search, got %s", got)
+	}
+}
+
+func TestServer_PositionPastARepetition(t *testing.T) {
+	engine := newFakeEngine()
+	lines := runServer(t, engine,
+		"position startpos moves g1f3 g8f6 f3g1 f6g8 g1f3 g8f6 f3g1 f6g8 e2e4", "go depth 1")
+
+	if got := engine.lastSearchFen(); got != "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 5" {
+		t.Fatalf("unexpected search position: %s, output %v", got, lines)
+	}
+	if strings.HasPrefix(lines[0], "info string") {
+		t.Fatalf("unexpected error: %v", lines)
+	}
+}
+
+func TestServer_PositionPastTheFiftyMoveRule(t *testing.T) {
+	engine := newFakeEngine()
+	lines := runServer(t, engine, "position fen 4k3/8/8/8/8/8/8/R3K3 w - - 100 80 moves a1a2", "go depth 1")
+
+	if got := engine.lastSearchFen(); got != "4k3/8/8/8/8/8/R7/4K3 b - - 101 80" {
+		t.Fatalf("unexpected search position: %s, output %v", got, lines)
+	}
+	if strings.HasPrefix(lines[0], "info string") {
+		t.Fatalf("unexpected error: %v", lines)
+	}
+}
+
+func TestServer_NoLegalMoves(t *testing.T) {
+	lines := runServer(t, newFakeEngine(), "position fen 7k/5QQ1/8/8/8/8/8/K7 b - - 0 1", "go depth 1")
+
+	if lines[len(lines)-1] != "bestmove (none)" {
+		t.Fatalf("unexpected output: %v", lines)
+	}
+}
+
+func TestServer_SetOptionAndNewGame(t *testing.T) {
+	engine := newFakeEngine()
+	lines := runServer(t, engine, "setoption name Hash value 64", "setoption name Bogus", "ucinewgame")
+
+	if engine.options["Hash"] != "64" {
+		t.Fatalf("expected Hash to be set, got %v", engine.options)
+	}
+	if engine.newGames != 1 {
+		t.Fatalf("expected one new game, got %d", engine.newGames)
+	}
+	if len(lines) != 1 || lines[0] != "info string unknown option Bogus" {
+		t.Fatalf("unexpected output: %v", lines)
+	}
+}
+
+func TestServer_UnknownCommandsReportedInDebug(t *testing.T) {
+	lines := runServer(t, newFakeEngine(), "hello", "debug on", "hello", "isready")
+
+	if len(lines) != 2 || lines[0] != "info string unknown command: hello" || lines[1] != "readyok" {
+		t.Fatalf("unexpected output: %v", lines)
+	}
+}
+
+// session drives a server over pipes so responses can be read while it runs.
+type session struct {
+	commands *io.PipeWriter
+	lines    *bufio.Scanner
+	result   chan error
+}
+
+func startSession(t *testing.T, engine Engine) *session {
+	t.Helper()
+	commandReader, commandWriter := io.Pipe()
+	responseReader, responseWriter := io.Pipe()
+
+	s := &session{commands: commandWriter, lines: bufio.NewScanner(responseReader), result: make(chan error, 1)}
+	go func() {
+		s.result <- NewServer(engine, commandReader, responseWriter).Run()
+		responseWriter.Close()
+	}()
+	return s
+}
+
+func (s *session) send(t *testing.T, command string) {
+	t.Helper()
+	if _, err := io.WriteString(s.commands, command+"\n"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// expect reads responses until one has the given prefix, failing if another bestmove arrives first.
+func (s *session) expect(t *testing.T, prefix string) string {
+	t.Helper()
+	lines := make(chan string)
+	go func() {
+		for s.lines.Scan() {
+			line := s.lines.Text()
+			if strings.HasPrefix(line, prefix) || strings.HasPrefix(line, "bestmove") {
+				lines <- line
+				return
+			}
+		}
+		close(lines)
+	}()
+
+	select {
+	case line, ok := <-lines:
+		if !ok || !strings.HasPrefix(line, prefix) {
+			t.Fatalf("expected %q, got %q", prefix, line)
+		}
+		return line
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for %q", prefix)
+	}
+	return ""
+}
+
+func TestServer_InfiniteSearchAnswersIsReadyAndStops(t *testing.T) {
+	s := startSession(t, newFakeEngine())
+
+	s.send(t, "position startpos")
+	s.send(t, "go infinite")
+	s.send(t, "isready")
+	s.expect(t, "readyok")
+	s.send(t, "stop")
+	s.expect(t, "bestmove ")
+	s.send(t, "quit")
+
+	if err := <-s.result; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestServer_PonderHit(t *testing.T) {
+	engine := newFakeEngine()
+	s := startSession(t, engine)
+
+	s.send(t, "go ponder wtime 1000 btime 1000")
+	s.send(t, "isready")
+	s.expect(t, "readyok")
+	s.send(t, "ponderhit")
+	s.send(t, "stop")
+	s.expect(t, "bestmove ")
+	s.commands.Close()
+
+	if err := <-s.result; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if engine.ponderHits != 1 {
+		t.Fatalf("expected one ponderhit, got %d", engine.ponderHits)
+	}
+}
+
+func TestServer_EndOfInputStopsSearch(t *testing.T) {
+	lines := runServer(t, newFakeEngine(), "go infinite")
+
+	if !strings.HasPrefix(lines[len(lines)-1], "bestmove ") {
+		t.Fatalf("expected the search to be stopped, got %v", lines)
+	}
+}